@@ -70,7 +70,13 @@ func TrustBonusHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar usuário"})
 		return
 	}
-	if userMemberID != tokenInfo.MemberID {
+	permitido, err := utils.PodeGerenciarRevenda(tokenInfo.MemberID, userMemberID, tokenInfo.Role)
+	if err != nil {
+		log.Printf("❌ Erro ao verificar hierarquia de revendas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar permissões"})
+		return
+	}
+	if !permitido {
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Ação não permitida: você não é o responsável por esta conta"})
 		return
 	}
@@ -123,6 +129,8 @@ func TrustBonusHandler(c *gin.Context) {
 		DataLiberacao:   time.Now(),
 		AdminID:         tokenInfo.Username,
 		Motivo:          req.Motivo,
+		ExpDateAnterior: expDate.Int64,
+		NovoExpDate:     newExpDate,
 	}
 	// Passa o contexto da requisição para SaveToRedisJSON
 	if err := utils.SaveToRedisJSON(c, redisKey, bonus, utils.GetConfiancaFrequenciaDias()*86400); err != nil {
@@ -133,6 +141,16 @@ func TrustBonusHandler(c *gin.Context) {
 	// Log MongoDB
 	_ = utils.SaveActionLog(req.UserID, "trust_bonus", bonus, tokenInfo.Username)
 
+	operacaoID := recordUndo(c, models.UndoRecord{
+		Tipo:          models.UndoBonusConfianca,
+		UserID:        req.UserID,
+		ActorID:       tokenInfo.MemberID,
+		ActorUsername: tokenInfo.Username,
+		ExpDateAntes:  expDate.Int64,
+		ExpDateDepois: newExpDate,
+		Descricao:     "Bônus de confiança de " + strconv.Itoa(req.DiasAdicionados) + " dia(s)",
+	})
+
 	// Resposta padronizada de sucesso
 	log.Printf("✅ Trust bonus aplicado com sucesso para usuário %d", req.UserID)
 	c.JSON(http.StatusOK, gin.H{
@@ -141,6 +159,7 @@ func TrustBonusHandler(c *gin.Context) {
 		"novo_exp_date":    newExpDate,
 		"dias_adicionados": req.DiasAdicionados,
 		"usuario_id":       req.UserID,
+		"operacao_id":      operacaoID,
 	})
 }
 
@@ -182,7 +201,13 @@ func RenewRollbackHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar usuário"})
 		return
 	}
-	if userMemberID != tokenInfo.MemberID {
+	permitido, err := utils.PodeGerenciarRevenda(tokenInfo.MemberID, userMemberID, tokenInfo.Role)
+	if err != nil {
+		log.Printf("❌ Erro ao verificar hierarquia de revendas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar permissões"})
+		return
+	}
+	if !permitido {
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Ação não permitida: você não é o responsável por esta conta"})
 		return
 	}
@@ -242,7 +267,19 @@ func RenewRollbackHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao restaurar exp_date"})
 		return
 	}
-	_, err = tx.Exec("UPDATE streamcreed_db.reg_users SET credits = credits + ? WHERE id = ?", backup.CreditosGastos, tokenInfo.MemberID)
+	// Os créditos voltam para quem pagou a renovação (a master pode desfazer renovação feita pela sub-revenda)
+	revendaReembolso := backup.MemberIDRenovou
+	if revendaReembolso == 0 {
+		revendaReembolso = tokenInfo.MemberID
+	}
+	_, err = models.ApplyCreditMovement(tx, models.CreditMovement{
+		MemberID:  revendaReembolso,
+		Operacao:  models.LedgerReversaoRenovacao,
+		UserID:    req.UserID,
+		Valor:     backup.CreditosGastos,
+		ActorID:   tokenInfo.MemberID,
+		Descricao: "Reversão de renovação do " + username,
+	})
 	if err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao devolver créditos, tente novamente mais tarde."})
@@ -252,7 +289,7 @@ func RenewRollbackHandler(c *gin.Context) {
 	// Inserir log de créditos
 	logReason := "Reversão de renovação do " + username
 	_, err = tx.Exec("INSERT INTO streamcreed_db.credits_log (target_id, admin_id, amount, `date`, reason) VALUES (?, -1, ?, ?, ?)",
-		tokenInfo.MemberID, backup.CreditosGastos, time.Now().Unix(), logReason)
+		revendaReembolso, backup.CreditosGastos, time.Now().Unix(), logReason)
 	if err != nil {
 		tx.Rollback()
 		log.Printf("❌ Erro ao inserir log de créditos: %v", err)
@@ -279,6 +316,11 @@ func RenewRollbackHandler(c *gin.Context) {
 		log.Printf("✅ Backup de renovação removido do Redis para chave %s", backupKey)
 	}
 
+	// A mesma renovação não pode mais ser desfeita por /api/undo
+	if backup.UndoID != "" {
+		utils.DeleteUndoRecord(c, req.UserID, backup.UndoID)
+	}
+
 	// Log MongoDB
 	_ = utils.SaveActionLog(req.UserID, "renew_rollback", backup, tokenInfo.Username)
 
@@ -330,7 +372,13 @@ func ChangeDueDateHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar usuário"})
 		return
 	}
-	if userMemberID != tokenInfo.MemberID {
+	permitido, err := utils.PodeGerenciarRevenda(tokenInfo.MemberID, userMemberID, tokenInfo.Role)
+	if err != nil {
+		log.Printf("❌ Erro ao verificar hierarquia de revendas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar permissões"})
+		return
+	}
+	if !permitido {
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Ação não permitida: você não é o responsável por esta conta"})
 		return
 	}
@@ -393,6 +441,8 @@ func ChangeDueDateHandler(c *gin.Context) {
 		DataAlteracao:      time.Now(),
 		AdminID:            tokenInfo.Username,
 		Motivo:             req.Motivo,
+		ExpDateAnterior:    exp.Unix(),
+		NovoExpDate:        novoVenc.Unix(),
 	}
 	ttl := utils.GetAlteracaoVencimentoFrequenciaDias() * 86400
 	// Passa o contexto da requisição para SaveToRedisJSON
@@ -402,6 +452,16 @@ func ChangeDueDateHandler(c *gin.Context) {
 	}
 	_ = utils.SaveActionLog(req.UserID, "change_due_date", change, tokenInfo.Username)
 
+	operacaoID := recordUndo(c, models.UndoRecord{
+		Tipo:          models.UndoAlteracaoVencimento,
+		UserID:        req.UserID,
+		ActorID:       tokenInfo.MemberID,
+		ActorUsername: tokenInfo.Username,
+		ExpDateAntes:  exp.Unix(),
+		ExpDateDepois: novoVenc.Unix(),
+		Descricao:     "Alteração do vencimento para o dia " + strconv.Itoa(req.NovaDataVencimento),
+	})
+
 	// Resposta padronizada de sucesso
 	log.Printf("✅ Data de vencimento alterada com sucesso para usuário %d", req.UserID)
 	c.JSON(http.StatusOK, gin.H{
@@ -410,5 +470,6 @@ func ChangeDueDateHandler(c *gin.Context) {
 		"novo_exp_date":        novoVenc.Unix(),
 		"nova_data_vencimento": req.NovaDataVencimento,
 		"usuario_id":           req.UserID,
+		"operacao_id":          operacaoID,
 	})
 }
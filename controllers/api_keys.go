@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"apiBackEnd/models"
+	"apiBackEnd/utils"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getMaxAPIKeys retorna o limite de chaves ativas por revenda (API_KEYS_MAX_POR_REVENDA, padrão 10)
+func getMaxAPIKeys() int {
+	val, err := strconv.Atoi(os.Getenv("API_KEYS_MAX_POR_REVENDA"))
+	if err != nil || val <= 0 {
+		return 10
+	}
+	return val
+}
+
+// ListAPIKeysHandler godoc
+// @Summary Listar Chaves de API
+// @Description Lista as chaves de API da revenda (inclusive revogadas). O valor da chave nunca é retornado, apenas o prefixo.
+// @Tags Chaves de API
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"total\": 1, \"chaves\": [{\"id\": 3, \"nome\": \"Bot WhatsApp\", \"prefixo\": \"sok_Ab12Cd34\", \"scopes\": [\"create-test\"], \"criado_em\": 1716210000}], \"scopes_disponiveis\": [\"create-test\", \"read-clients\", \"read-credits\", \"renew\"]}"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/api-keys [get]
+func ListAPIKeysHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	keys, err := models.ListAPIKeys(tokenInfo.MemberID)
+	if err != nil {
+		log.Printf("❌ Erro ao listar chaves de API da revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao listar chaves de API"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":              len(keys),
+		"chaves":             keys,
+		"scopes_disponiveis": utils.APIKeyScopeNames(),
+	})
+}
+
+// CreateAPIKeyHandler godoc
+// @Summary Criar Chave de API
+// @Description Cria uma chave de API para bots e integrações, enviada no header X-API-Key. A chave só pode usar as permissões dos scopes escolhidos. O valor completo é exibido apenas nesta resposta.
+// @Tags Chaves de API
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.CreateAPIKeyPayload true "Nome, scopes e validade opcional"
+// @Success 201 {object} map[string]interface{} "Exemplo: {\"message\": \"Chave criada com sucesso\", \"chave\": \"sok_...\", \"dados\": {\"id\": 3, \"nome\": \"Bot WhatsApp\"}}"
+// @Failure 400 {object} map[string]string "Dados ou scopes inválidos, ou limite de chaves atingido"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/api-keys [post]
+func CreateAPIKeyHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateAPIKeyPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "details": err.Error()})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Informe um nome para a chave"})
+		return
+	}
+	if req.ExpiraDias < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "expira_dias não pode ser negativo"})
+		return
+	}
+
+	// Scopes sem repetição; cada um precisa existir e ser permitido ao papel da revenda
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool)
+	for _, scope := range req.Scopes {
+		scope = strings.TrimSpace(scope)
+		if seen[scope] {
+			continue
+		}
+		if !utils.ValidAPIKeyScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "Scope inválido: " + scope, "scopes_disponiveis": utils.APIKeyScopeNames()})
+			return
+		}
+		for _, perm := range utils.APIKeyPermissions([]string{scope}) {
+			if !utils.HasPermission(tokenInfo.Role, perm) {
+				c.JSON(http.StatusBadRequest, gin.H{"erro": "Seu perfil não permite o scope " + scope})
+				return
+			}
+		}
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+
+	active, err := models.CountActiveAPIKeys(tokenInfo.MemberID)
+	if err != nil {
+		log.Printf("❌ Erro ao contar chaves de API da revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao criar chave de API"})
+		return
+	}
+	if active >= getMaxAPIKeys() {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Limite de chaves ativas atingido. Revogue uma chave antes de criar outra."})
+		return
+	}
+
+	key, prefix, keyHash, err := utils.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar chave de API"})
+		return
+	}
+	var expiresAt *int64
+	if req.ExpiraDias > 0 {
+		exp := time.Now().AddDate(0, 0, req.ExpiraDias).Unix()
+		expiresAt = &exp
+	}
+
+	id, err := models.InsertAPIKey(tokenInfo.MemberID, req.Name, prefix, keyHash, scopes, expiresAt)
+	if err != nil {
+		log.Printf("❌ Erro ao salvar chave de API da revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao criar chave de API"})
+		return
+	}
+
+	saveAuthEvent("api_key_created", tokenInfo.MemberID, tokenInfo.MemberID, gin.H{"api_key_id": id, "nome": req.Name, "scopes": scopes})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Chave criada com sucesso. Guarde-a em local seguro: ela não será exibida novamente.",
+		"chave":   key,
+		"dados": models.APIKey{
+			ID:        id,
+			MemberID:  tokenInfo.MemberID,
+			Name:      req.Name,
+			Prefix:    prefix,
+			Scopes:    scopes,
+			CreatedAt: time.Now().Unix(),
+			ExpiresAt: expiresAt,
+		},
+	})
+}
+
+// RevokeAPIKeyHandler godoc
+// @Summary Revogar Chave de API
+// @Description Revoga uma chave de API da revenda. A chave deixa de funcionar imediatamente.
+// @Tags Chaves de API
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID da chave"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"message\": \"Chave revogada com sucesso\"}"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 404 {object} map[string]string "Chave não encontrada ou já revogada"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/api-keys/{id} [delete]
+func RevokeAPIKeyHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	keyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || keyID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "ID da chave inválido"})
+		return
+	}
+
+	revoked, err := models.RevokeAPIKey(tokenInfo.MemberID, keyID)
+	if err != nil {
+		log.Printf("❌ Erro ao revogar chave de API %d da revenda %d: %v", keyID, tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao revogar chave de API"})
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Chave não encontrada ou já revogada"})
+		return
+	}
+
+	saveAuthEvent("api_key_revoked", tokenInfo.MemberID, tokenInfo.MemberID, gin.H{"api_key_id": keyID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Chave revogada com sucesso"})
+}
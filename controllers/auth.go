@@ -3,25 +3,49 @@ package controllers
 import (
 	"apiBackEnd/models"
 	"apiBackEnd/utils"
+	"database/sql"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
-// AuthMiddleware valida o token JWT nas rotas protegidas.
+// AuthMiddleware valida o token JWT (ou a chave de API) nas rotas protegidas.
 // Esta função agora reside em controllers/auth.go
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
+
+		// 🔑 Bots e integrações: chave de API no header X-API-Key
+		if apiKeyValue := c.GetHeader(utils.APIKeyHeader); tokenString == "" && apiKeyValue != "" {
+			apiKey, user, err := utils.AuthenticateAPIKey(apiKeyValue)
+			if err != nil {
+				if err != utils.ErrAPIKeyInvalid {
+					log.Printf("ERRO: Falha ao validar chave de API: %v", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao validar chave de API"})
+				} else {
+					c.JSON(http.StatusUnauthorized, gin.H{"erro": "Chave de API inválida, revogada ou expirada"})
+				}
+				c.Abort()
+				return
+			}
+			c.Set("api_key_permissions", utils.APIKeyPermissions(apiKey.Scopes))
+			setAuthContext(c, utils.APIKeyClaims(apiKey, user), 0)
+			c.Next()
+			return
+		}
+
 		if tokenString == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token não fornecido"})
 			c.Abort()
 			return
 		}
 
-		claims, _, err := utils.ValidateToken(tokenString)
+		claims, timeRemaining, err := utils.ValidateToken(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token inválido ou expirado"})
 			c.Abort()
@@ -29,19 +53,29 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		if claims != nil {
-			c.Set("claims", claims)
-			if memberID, ok := claims["member_id"].(float64); ok {
-				c.Set("member_id", int(memberID))
-			}
-			if username, ok := claims["username"].(string); ok {
-				c.Set("username", username)
-			}
+			setAuthContext(c, claims, timeRemaining)
 		}
 
 		c.Next()
 	}
 }
 
+// setAuthContext grava a identidade autenticada no contexto do Gin
+func setAuthContext(c *gin.Context, claims jwt.MapClaims, timeRemaining int64) {
+	c.Set("claims", claims)
+	c.Set("time_remaining", timeRemaining)
+	if memberID, ok := claims["member_id"].(float64); ok {
+		c.Set("member_id", int(memberID))
+	}
+	if username, ok := claims["username"].(string); ok {
+		c.Set("username", username)
+	}
+	c.Set("role", utils.RoleFromClaims(claims))
+	if adminID, _ := utils.ImpersonatorFromClaims(claims); adminID != 0 {
+		c.Set("impersonated_by", adminID)
+	}
+}
+
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
@@ -50,16 +84,26 @@ type LoginRequest struct {
 // Estrutura de resposta do login para o Swagger
 type LoginResponse struct {
 	Token         string  `json:"token"`
+	RefreshToken  string  `json:"refresh_token"`
+	ExpiresIn     int64   `json:"expires_in"` // Segundos até o access token expirar
+	SessionID     string  `json:"session_id"`
 	MemberGroupID int     `json:"member_group_id"`
 	Credits       float64 `json:"credits"`
 	Status        int     `json:"status"`
 	MemberID      int     `json:"member_id"`
 }
 
+// TwoFactorChallengeResponse é retornado pelo login quando a revenda tem 2FA ativo
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeID       string `json:"challenge_id"`
+	ExpiresIn         int64  `json:"expires_in"` // Segundos até o desafio expirar
+}
+
 // Login realiza a autenticação do usuário.
 //
 // @Summary Autenticação de Usuário
-// @Description Autentica um usuário e retorna um token JWT se as credenciais forem válidas.
+// @Description Autentica um usuário e abre uma nova sessão (um dispositivo), retornando um access token JWT de curta duração e um refresh token. Se a revenda tiver 2FA ativo, a resposta é um desafio (TwoFactorChallengeResponse) a ser concluído em /login/2fa.
 // @Tags Autenticação
 // @Accept  json
 // @Produce  json
@@ -67,6 +111,7 @@ type LoginResponse struct {
 // @Success 200 {object} LoginResponse "Login realizado com sucesso"
 // @Failure 400 {object} map[string]string "Erro na requisição"
 // @Failure 401 {object} map[string]string "Credenciais inválidas"
+// @Failure 429 {object} map[string]string "Muitas tentativas; aguarde o tempo indicado no header Retry-After"
 // @Router /login [post]
 func Login(c *gin.Context) {
 	var req LoginRequest
@@ -79,9 +124,18 @@ func Login(c *gin.Context) {
 	}
 	log.Printf("INFO: Tentativa de login para o usuário: %s", req.Username) // Log adicionado
 
+	// 🚫 Username ou IP bloqueado por excesso de falhas
+	if rejectLockedLogin(c, req.Username) {
+		return
+	}
+
 	user, err := models.GetUserByUsername(req.Username)
 	if err != nil {
 		log.Printf("ERRO: Usuário '%s' não encontrado ou erro ao buscar: %v", req.Username, err) // Log adicionado
+		if err == sql.ErrNoRows {
+			respondLoginFailure(c, req.Username, "Usuário ou senha incorretos")
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Usuário ou senha incorretos"})
 		return
 	}
@@ -103,23 +157,168 @@ func Login(c *gin.Context) {
 
 	if hashedInputPassword != user.PasswordHash {
 		log.Printf("AVISO: Senha incorreta para o usuário '%s'", req.Username) // Log adicionado
-		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Usuário ou senha incorretos"})
+		respondLoginFailure(c, req.Username, "Usuário ou senha incorretos")
 		return
 	}
 
-	log.Printf("INFO: Credenciais válidas para o usuário '%s'. Gerando token...", user.Username) // Log adicionado
-	// 📌 Gerar token com tempo de expiração configurável
-	token, err := utils.GenerateToken(user.Username, user.MemberID, user.Credits, strconv.Itoa(user.Status))
+	log.Printf("INFO: Credenciais válidas para o usuário '%s'", user.Username) // Log adicionado
+
+	// 🔐 Revenda com 2FA ativo: em vez do JWT, devolve um desafio para /login/2fa
+	twoFactorEnabled, err := models.IsTwoFactorEnabled(user.MemberID)
+	if err != nil {
+		log.Printf("ERRO: Falha ao consultar 2FA do usuário '%s': %v", user.Username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar autenticação em duas etapas"})
+		return
+	}
+	if twoFactorEnabled {
+		challengeID, err := utils.CreateLoginChallenge(c.Request.Context(), user.MemberID)
+		if err != nil {
+			log.Printf("ERRO: Falha ao criar desafio 2FA para o usuário '%s': %v", user.Username, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao iniciar autenticação em duas etapas"})
+			return
+		}
+		log.Printf("INFO: Usuário '%s' com 2FA ativo. Desafio emitido.", user.Username)
+		c.JSON(http.StatusOK, TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeID:       challengeID,
+			ExpiresIn:         int64(utils.GetLoginChallengeExpiration().Seconds()),
+		})
+		return
+	}
+
+	issueLoginTokens(c, user)
+}
+
+// rejectLockedLogin responde 429 com Retry-After se o username ou o IP estiverem bloqueados
+func rejectLockedLogin(c *gin.Context, username string) bool {
+	retryAfter, err := utils.CheckLoginLock(c.Request.Context(), username, c.ClientIP())
+	if err != nil {
+		// Redis indisponível não deve derrubar o login
+		log.Printf("ERRO: Falha ao consultar bloqueio de login de '%s': %v", username, err)
+		return false
+	}
+	if retryAfter <= 0 {
+		return false
+	}
+	respondLoginLocked(c, retryAfter)
+	return true
+}
+
+// respondLoginFailure registra a falha de login e responde 401, ou 429 se a falha gerou bloqueio
+func respondLoginFailure(c *gin.Context, username string, message string) {
+	lockouts, err := utils.RegisterLoginFailure(c.Request.Context(), username, c.ClientIP())
+	if err != nil {
+		log.Printf("ERRO: Falha ao registrar tentativa de login de '%s': %v", username, err)
+	}
+	var retryAfter time.Duration
+	for _, lockout := range lockouts {
+		if lockout.Duration > retryAfter {
+			retryAfter = lockout.Duration
+		}
+	}
+	if retryAfter > 0 {
+		respondLoginLocked(c, retryAfter)
+		return
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"erro": message})
+}
+
+func respondLoginLocked(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"erro":        "Muitas tentativas de login. Tente novamente mais tarde.",
+		"retry_after": seconds,
+	})
+}
+
+// issueLoginTokens abre uma nova sessão para a revenda autenticada e responde com os tokens.
+// Só é chamada com o login completo (senha e, se ativo, 2FA), por isso zera as falhas do username.
+func issueLoginTokens(c *gin.Context, user *models.User) {
+	utils.ResetLoginFailures(c.Request.Context(), user.Username)
+	log.Printf("INFO: Gerando token para o usuário '%s'...", user.Username)
+	// 📌 Abrir nova sessão: access token (TOKEN_EXPIRATION_MINUTES) + refresh token (REFRESH_TOKEN_EXPIRATION_DIAS)
+	tokens, err := utils.GenerateToken(user.Username, user.MemberID, user.MemberGroupID, user.Credits, strconv.Itoa(user.Status), c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
 		log.Printf("ERRO: Falha ao gerar token para o usuário '%s': %v", user.Username, err) // Log adicionado
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar token"})
 		return
 	}
-	log.Printf("INFO: Token gerado com sucesso para o usuário '%s': %s", user.Username, token) // Log adicionado
+	log.Printf("INFO: Sessão %s criada com sucesso para o usuário '%s'", tokens.SessionID, user.Username) // Log adicionado
 
 	// 📌 Retornar os dados do usuário no login
 	c.JSON(http.StatusOK, LoginResponse{
-		Token:         token,
+		Token:         tokens.AccessToken,
+		RefreshToken:  tokens.RefreshToken,
+		ExpiresIn:     tokens.ExpiresIn,
+		SessionID:     tokens.SessionID,
+		MemberGroupID: user.MemberGroupID,
+		Credits:       user.Credits,
+		Status:        user.Status,
+		MemberID:      user.MemberID,
+	})
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshToken emite um novo access token a partir do refresh token da sessão.
+//
+// @Summary Renovar Access Token
+// @Description Troca um refresh token válido por um novo par access token + refresh token (rotação). O refresh token usado deixa de valer; se um refresh token já rotacionado for reapresentado, a sessão inteira é revogada.
+// @Tags Autenticação
+// @Accept  json
+// @Produce  json
+// @Param refresh body RefreshRequest true "Refresh token da sessão"
+// @Success 200 {object} LoginResponse "Tokens renovados com sucesso"
+// @Failure 400 {object} map[string]string "Erro na requisição"
+// @Failure 401 {object} map[string]string "Refresh token inválido, expirado ou reutilizado"
+// @Router /auth/refresh [post]
+func RefreshToken(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos"})
+		return
+	}
+
+	memberID, err := utils.RefreshTokenMemberID(req.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Refresh token inválido"})
+		return
+	}
+
+	// 📌 Recarrega a revenda do banco: créditos e status atualizados entram no novo access token
+	user, err := models.GetUserByID(memberID)
+	if err != nil {
+		log.Printf("ERRO: Revenda %d não encontrada ao renovar token: %v", memberID, err)
+		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Refresh token inválido"})
+		return
+	}
+	if user.Status != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Conta bloqueada. Entre em contato com o suporte."})
+		return
+	}
+
+	tokens, err := utils.RefreshSession(c.Request.Context(), req.RefreshToken, user.Username, user.MemberGroupID, user.Credits, strconv.Itoa(user.Status), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		switch err {
+		case utils.ErrRefreshTokenReused:
+			c.JSON(http.StatusUnauthorized, gin.H{"erro": "Refresh token reutilizado. Por segurança a sessão foi encerrada; faça login novamente."})
+		case utils.ErrRefreshTokenInvalid, utils.ErrSessionNotFound:
+			c.JSON(http.StatusUnauthorized, gin.H{"erro": "Refresh token inválido ou expirado"})
+		default:
+			log.Printf("ERRO: Falha ao renovar sessão da revenda %d: %v", memberID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao renovar token"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, LoginResponse{
+		Token:         tokens.AccessToken,
+		RefreshToken:  tokens.RefreshToken,
+		ExpiresIn:     tokens.ExpiresIn,
+		SessionID:     tokens.SessionID,
 		MemberGroupID: user.MemberGroupID,
 		Credits:       user.Credits,
 		Status:        user.Status,
@@ -0,0 +1,307 @@
+package controllers
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"apiBackEnd/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Renovação automática
+//
+// A revenda marca clientes em PUT /api/users/:user_id/auto-renew. A cada
+// AUTO_RENOVACAO_INTERVALO_MINUTOS (padrão 10), um job renova os clientes marcados que vencem nas
+// próximas AUTO_RENOVACAO_ANTECEDENCIA_HORAS (padrão 24), com o mesmo custo e as mesmas regras de
+// /api/renew, cobrando os créditos da revenda dona do cliente. Clientes que venceram há menos desse
+// mesmo intervalo ainda são tentados (ex.: a revenda recarregou os créditos depois do vencimento).
+//
+//   - só uma instância da API roda o job por vez (trava `job_lock:auto_renovacao` no Redis);
+//   - quando os créditos de uma revenda acabam, os demais clientes dela são pulados naquela rodada;
+//   - um cliente que falhou só é tentado de novo após AUTO_RENOVACAO_RETENTATIVA_MINUTOS (padrão 60);
+//   - toda tentativa, com sucesso ou não, vai para a coleção `renew` do MongoDB (origem "auto_renovacao");
+//   - AUTO_RENOVACAO_ATIVA=false desliga o job nesta instância.
+
+const (
+	autoRenewJobName    = "auto_renovacao"
+	autoRenewOrigem     = "auto_renovacao"
+	autoRenewLoteMaximo = 500
+	// TTL da trava do job; renovado enquanto a rodada executa (utils.KeepJobLock)
+	autoRenewLockTTL = 2 * time.Minute
+)
+
+// GetAutoRenewHandler godoc
+// @Summary Consultar Renovação Automática
+// @Description Retorna se o cliente está marcado para renovação automática, quantos meses são renovados a cada vencimento e o resultado da última tentativa.
+// @Tags Renovação
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param user_id path int true "ID do cliente"
+// @Success 200 {object} models.AutoRenewConfig
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 403 {object} map[string]string "Cliente não pertence à revenda"
+// @Failure 404 {object} map[string]string "Cliente não encontrado"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/users/{user_id}/auto-renew [get]
+func GetAutoRenewHandler(c *gin.Context) {
+	userID, responsavel, ok := autoRenewClient(c)
+	if !ok {
+		return
+	}
+
+	cfg, err := models.GetAutoRenew(userID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusOK, models.AutoRenewConfig{UserID: userID, MemberID: responsavel, Meses: 1})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Erro ao buscar renovação automática do cliente %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar renovação automática"})
+		return
+	}
+	c.JSON(http.StatusOK, cfg)
+}
+
+// SetAutoRenewHandler godoc
+// @Summary Configurar Renovação Automática
+// @Description Liga ou desliga a renovação automática do cliente. Com ela ligada, o cliente é renovado pouco antes do vencimento, com o mesmo custo de /api/renew, usando os créditos da revenda dona do cliente.
+// @Tags Renovação
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param user_id path int true "ID do cliente"
+// @Param body body models.AutoRenewPayload true "Ativo e meses por renovação (padrão 1)"
+// @Success 200 {object} models.AutoRenewConfig
+// @Failure 400 {object} map[string]string "Dados inválidos"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 403 {object} map[string]string "Cliente não pertence à revenda"
+// @Failure 404 {object} map[string]string "Cliente não encontrado"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/users/{user_id}/auto-renew [put]
+func SetAutoRenewHandler(c *gin.Context) {
+	userID, responsavel, ok := autoRenewClient(c)
+	if !ok {
+		return
+	}
+
+	var req models.AutoRenewPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "details": err.Error()})
+		return
+	}
+	if req.Meses == 0 {
+		req.Meses = 1
+	}
+
+	if err := models.SaveAutoRenew(userID, responsavel, req.Meses, req.Ativo); err != nil {
+		log.Printf("❌ Erro ao gravar renovação automática do cliente %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gravar renovação automática"})
+		return
+	}
+
+	tokenInfo, _ := utils.ValidateAndExtractToken(c)
+	utils.SaveActionLog(userID, "auto_renew", gin.H{"ativo": req.Ativo, "meses": req.Meses}, strconv.Itoa(tokenInfo.MemberID))
+
+	c.JSON(http.StatusOK, models.AutoRenewConfig{UserID: userID, MemberID: responsavel, Meses: req.Meses, Ativo: req.Ativo})
+}
+
+// autoRenewClient lê o :user_id e verifica se a revenda pode gerenciar o cliente.
+// Retorna o cliente e a revenda dona dele; em caso de falha já responde e retorna ok=false.
+func autoRenewClient(c *gin.Context) (int, int, bool) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "ID de usuário inválido"})
+		return 0, 0, false
+	}
+
+	permitido, responsavel, err := utils.VerificaPermissaoUsuario(userID, tokenInfo.MemberID, tokenInfo.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"erro": "Usuário não encontrado"})
+			return 0, 0, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar permissões"})
+		return 0, 0, false
+	}
+	if !permitido {
+		c.JSON(http.StatusForbidden, gin.H{"erro": "Usuário não pertence à sua revenda"})
+		return 0, 0, false
+	}
+	return userID, responsavel, true
+}
+
+func autoRenewEnvDuration(name string, def int, unit time.Duration) time.Duration {
+	val, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || val <= 0 {
+		val = def
+	}
+	return time.Duration(val) * unit
+}
+
+// StartAutoRenewScheduler inicia o job de renovação automática em segundo plano até ctx ser cancelado
+func StartAutoRenewScheduler(ctx context.Context) {
+	if strings.EqualFold(os.Getenv("AUTO_RENOVACAO_ATIVA"), "false") {
+		log.Println("⚠️ Renovação automática desativada (AUTO_RENOVACAO_ATIVA=false)")
+		return
+	}
+	intervalo := autoRenewEnvDuration("AUTO_RENOVACAO_INTERVALO_MINUTOS", 10, time.Minute)
+	log.Printf("🔁 Renovação automática ativa (a cada %s)", intervalo)
+
+	go func() {
+		ticker := time.NewTicker(intervalo)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				runAutoRenewJob(ctx)
+			}
+		}
+	}()
+}
+
+// runAutoRenewJob executa uma rodada do job se esta instância obtiver a trava
+func runAutoRenewJob(ctx context.Context) {
+	if config.RedisClient == nil || config.DB == nil {
+		return
+	}
+	token, ok, err := utils.AcquireJobLock(ctx, autoRenewJobName, autoRenewLockTTL)
+	if err != nil {
+		log.Printf("❌ Erro ao obter trava da renovação automática: %v", err)
+		return
+	}
+	if !ok {
+		return // Outra instância está rodando
+	}
+	// Renovada enquanto a rodada executa: uma rodada mais longa que o intervalo não libera a trava
+	stopKeep := utils.KeepJobLock(autoRenewJobName, token, autoRenewLockTTL)
+	defer func() {
+		stopKeep()
+		if err := utils.ReleaseJobLock(context.Background(), autoRenewJobName, token); err != nil {
+			log.Printf("⚠️ Erro ao liberar trava da renovação automática: %v", err)
+		}
+	}()
+
+	antecedencia := autoRenewEnvDuration("AUTO_RENOVACAO_ANTECEDENCIA_HORAS", 24, time.Hour)
+	retentativa := autoRenewEnvDuration("AUTO_RENOVACAO_RETENTATIVA_MINUTOS", 60, time.Minute)
+	now := time.Now()
+
+	due, err := models.ListDueAutoRenewals(now.Add(-antecedencia).Unix(), now.Add(antecedencia).Unix(), now.Add(-retentativa).Unix(), autoRenewLoteMaximo)
+	if err != nil {
+		log.Printf("❌ Erro ao buscar clientes para renovação automática: %v", err)
+		return
+	}
+	if len(due) == 0 {
+		return
+	}
+	log.Printf("🔁 Renovação automática: %d cliente(s) a renovar", len(due))
+
+	renovados := 0
+	semCreditos := make(map[int]bool)
+	revendas := make(map[int]*models.User)
+	for _, d := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		if semCreditos[d.MemberID] {
+			recordAutoRenewFailure(d, "Créditos insuficientes (revenda sem saldo nesta rodada)")
+			continue
+		}
+
+		revenda, ok := revendas[d.MemberID]
+		if !ok {
+			revenda, err = models.GetUserByID(d.MemberID)
+			if err != nil {
+				log.Printf("❌ Erro ao buscar revenda %d para renovação automática: %v", d.MemberID, err)
+				revenda = nil
+			}
+			revendas[d.MemberID] = revenda
+		}
+		if revenda == nil || revenda.Status != 1 {
+			recordAutoRenewFailure(d, "Revenda não encontrada ou bloqueada")
+			continue
+		}
+
+		if rerr := autoRenewClientOnce(ctx, revenda, d); rerr != nil {
+			if rerr.Status == http.StatusPaymentRequired {
+				semCreditos[d.MemberID] = true
+			}
+			msg, _ := rerr.Body["erro"].(string)
+			recordAutoRenewFailure(d, msg)
+			continue
+		}
+		renovados++
+	}
+	log.Printf("✅ Renovação automática concluída: %d de %d cliente(s) renovado(s)", renovados, len(due))
+}
+
+// autoRenewClientOnce renova um cliente com os créditos da revenda, como em RenewAccount
+func autoRenewClientOnce(ctx context.Context, revenda *models.User, d models.AutoRenewDue) *renewalError {
+	claims := jwt.MapClaims{"member_group_id": float64(revenda.MemberGroupID)}
+	plan, rerr := planRenewal(claims, revenda.MemberID, d.UserID, renewalPeriod{Meses: d.Meses})
+	if rerr != nil {
+		return rerr
+	}
+	plan.ActorID = 0 // Sistema
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return &renewalError{http.StatusInternalServerError, gin.H{"erro": "Erro ao iniciar transação"}}
+	}
+	if _, rerr := applyRenewal(tx, revenda.MemberID, plan); rerr != nil {
+		tx.Rollback()
+		return rerr
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("❌ Erro ao finalizar renovação automática do cliente %d: %v", d.UserID, err)
+		return &renewalError{http.StatusInternalServerError, gin.H{"erro": "Erro ao finalizar transação"}}
+	}
+
+	saveRenewBackup(ctx, revenda.MemberID, autoRenewOrigem, plan)
+	saveRenewLog(revenda.MemberID, plan.UserID, plan.ExpDateAntes, plan.NovoExpDate, plan.Orcamento.Total, bson.M{
+		"origem":  autoRenewOrigem,
+		"meses":   d.Meses,
+		"sucesso": true,
+	})
+	if err := models.RecordAutoRenewAttempt(d.UserID, ""); err != nil {
+		log.Printf("⚠️ Erro ao registrar tentativa de renovação automática do cliente %d: %v", d.UserID, err)
+	}
+	return nil
+}
+
+// recordAutoRenewFailure registra a tentativa malsucedida no cliente e no MongoDB
+func recordAutoRenewFailure(d models.AutoRenewDue, msg string) {
+	if msg == "" {
+		msg = "Erro desconhecido"
+	}
+	log.Printf("⚠️ Renovação automática do cliente %d (revenda %d) falhou: %s", d.UserID, d.MemberID, msg)
+	if err := models.RecordAutoRenewAttempt(d.UserID, msg); err != nil {
+		log.Printf("⚠️ Erro ao registrar tentativa de renovação automática do cliente %d: %v", d.UserID, err)
+	}
+	saveRenewLog(d.MemberID, d.UserID, d.ExpDate, d.ExpDate, 0, bson.M{
+		"origem":  autoRenewOrigem,
+		"meses":   d.Meses,
+		"sucesso": false,
+		"erro":    fmt.Sprintf("%.255s", msg),
+	})
+}
@@ -71,6 +71,7 @@ type ClientResponse struct {
 // @Description Retorna todos os clientes associados ao usuário autenticado. Permite filtrar por query string, login ou userID.
 // @Tags Clientes
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Accept  json
 // @Produce  json
 // @Param login path string false "Login do cliente (username)"
@@ -82,15 +83,8 @@ type ClientResponse struct {
 // @Router /api/clients/login/{login} [get]
 // @Router /api/clients/userid/{userid} [get]
 func GetClients(c *gin.Context) {
-	// 📌 Recuperar token do header
-	tokenString := c.GetHeader("Authorization")
-	if tokenString == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token não fornecido"})
-		return
-	}
-
 	// 📌 Validar token e extrair claims
-	claims, timeRemaining, err := utils.ValidateToken(tokenString)
+	claims, timeRemaining, err := utils.RequestClaims(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token inválido ou expirado"})
 		return
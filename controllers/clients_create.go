@@ -0,0 +1,397 @@
+package controllers
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"apiBackEnd/utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Criação de cliente pago
+//
+// Diferente do teste (CreateTest), o cliente é criado já pago (IPTV_API_URL, is_trial=0). O saldo é
+// conferido antes de chamar o painel, sem travar a revenda; o débito vem depois, numa transação curta,
+// só se o painel aceitar o cliente. Se o saldo acabar nesse meio tempo (outra operação da revenda),
+// o cliente recém-criado é excluído logicamente e nada é cobrado. O vencimento segue as regras da
+// renovação (30 dias por mês, na hora RENOVACAO_HORA_VENCIMENTO).
+
+// maxTelasCliente é o limite de telas (max_connections) de um cliente
+const maxTelasCliente = 3
+
+// clientCreateOrigem identifica a criação paga na coleção `renew` do MongoDB
+const clientCreateOrigem = "criacao_cliente"
+
+// maxTentativasUsuario limita as tentativas com nome de usuário gerado (o painel responde EXISTS)
+const maxTentativasUsuario = 3
+
+var errUsuarioExiste = errors.New("nome de usuário já em uso")
+
+// clientUsernamePattern são os caracteres aceitos em nomes de usuário informados pela revenda
+var clientUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// minCaracteresCredencial é o tamanho mínimo de usuário e senha informados pela revenda
+const minCaracteresCredencial = 4
+
+// CreateClientRequest são os dados do cliente pago. Sem username/password, eles são gerados
+// como no teste (PREFIXO_USR, TOTAL_CARACTERES_USER, PREFIXO_SENHA, TOTAL_CARACTERES_SENHA).
+type CreateClientRequest struct {
+	Username         string `json:"username,omitempty"`
+	Password         string `json:"password,omitempty"`
+	Meses            int    `json:"quantidade_meses" binding:"required,min=1"`
+	Telas            int    `json:"telas,omitempty"`   // Padrão 1, máximo 3
+	Bouquet          string `json:"bouquet,omitempty"` // Ex.: "[1, 5, 10]"; padrão BOUQUET do .env
+	NumeroWhats      string `json:"numero_whats"`
+	NomeParaAviso    string `json:"nome_para_aviso"`
+	FranquiaMemberID *int   `json:"franquia_member_id,omitempty"`
+}
+
+// CreateClient cria um cliente pago.
+//
+// @Summary Criar Cliente Pago
+// @Description Cria um cliente completo (não teste) no painel e cobra os créditos da revenda só se o painel aceitar: se o painel recusar a criação, nada é cobrado; se os créditos acabarem durante a criação, o cliente é excluído e nada é cobrado. O custo segue as regras de preço da renovação (meses x telas) e o vencimento segue a renovação: agora + 30 dias por mês, na hora RENOVACAO_HORA_VENCIMENTO. Sem username/password, as credenciais são geradas automaticamente; informados, seguem as regras da importação por CSV (usuário com 4 a TOTAL_CARACTERES_USER caracteres entre letras, números, ponto, hífen e sublinhado; senha com 4 a TOTAL_CARACTERES_SENHA e diferente do login). numero_whats é normalizado para só dígitos (10 a 15).
+// @Tags Clientes
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param body body controllers.CreateClientRequest true "Dados do cliente"
+// @Param Idempotency-Key header string false "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem cobrar de novo"
+// @Success 201 {object} map[string]interface{} "Exemplo: {\"id_cliente\": 10, \"username\": \"usr123\", \"password\": \"abc456\", \"exp_date\": 1719700000, \"telas\": 1, \"creditos_gastos\": 1, \"creditos_restantes\": 9}"
+// @Failure 400 {object} map[string]string "Dados inválidos (usuário, senha ou WhatsApp fora das regras) ou nome de usuário em uso"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 402 {object} map[string]interface{} "Créditos insuficientes (formato padrão: erro, codigo, creditos_disponiveis, creditos_necessarios)"
+// @Failure 409 {object} map[string]string "Idempotency-Key em processamento"
+// @Failure 422 {object} map[string]string "Idempotency-Key reutilizada com corpo diferente"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Failure 502 {object} map[string]string "Painel IPTV recusou a criação"
+// @Router /api/clients [post]
+func CreateClient(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+	memberID := tokenInfo.MemberID
+
+	apiURL := os.Getenv("IPTV_API_URL")
+	maxUserChars, errUser := strconv.Atoi(os.Getenv("TOTAL_CARACTERES_USER"))
+	maxPassChars, errPass := strconv.Atoi(os.Getenv("TOTAL_CARACTERES_SENHA"))
+	if apiURL == "" || errUser != nil || errPass != nil || maxUserChars < 1 || maxPassChars < 1 {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Configuração inválida no .env"})
+		return
+	}
+
+	var req CreateClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos"})
+		return
+	}
+	if req.Telas == 0 {
+		req.Telas = 1
+	}
+	if req.Telas < 1 || req.Telas > maxTelasCliente {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": fmt.Sprintf("telas deve estar entre 1 e %d", maxTelasCliente)})
+		return
+	}
+	if (req.Username == "") != (req.Password == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Informe username e password juntos ou deixe ambos em branco para geração automática"})
+		return
+	}
+	// Mesmas regras da importação por CSV (parseImportRow)
+	if erros := validateClientCredentials(req.Username, req.Password, maxUserChars, maxPassChars); len(erros) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": erros[0]})
+		return
+	}
+	whats, err := normalizeWhatsApp(req.NumeroWhats)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+	req.NumeroWhats = whats
+	bouquet, err := normalizeBouquet(req.Bouquet)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	req.Bouquet = bouquet
+
+	cliente, rerr := createPaidClient(c, apiURL, memberID, req, "Criado Via API")
+	if rerr != nil {
+		c.JSON(rerr.Status, rerr.Body)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":             "Cliente criado com sucesso",
+		"id_cliente":         cliente.UserID,
+		"username":           cliente.Username,
+		"password":           cliente.Password,
+		"exp_date":           cliente.ExpDate.Unix(),
+		"vencimento":         cliente.ExpDate.Format("02/01/2006 15:04"),
+		"telas":              req.Telas,
+		"creditos_gastos":    cliente.Orcamento.Total,
+		"detalhamento_preco": cliente.Orcamento.Detalhamento,
+		"creditos_restantes": cliente.CreditosRestantes,
+	})
+}
+
+// paidClient é um cliente pago recém-criado
+type paidClient struct {
+	UserID            int
+	Username          string
+	Password          string
+	ExpDate           time.Time
+	Orcamento         *models.PriceQuote
+	CreditosRestantes float64
+}
+
+// createPaidClient cobra e cria o cliente no painel (req já validado, com telas e bouquet definidos).
+// Também usado pela importação de clientes; observacao vai para reseller_notes.
+func createPaidClient(c *gin.Context, apiURL string, memberID int, req CreateClientRequest, observacao string) (*paidClient, *renewalError) {
+	// 🔹 Vencimento e custo pelas regras da renovação, a partir de agora
+	agora := time.Now()
+	novoExp, _, err := renewalPeriod{Meses: req.Meses}.resolve(agora)
+	if err != nil {
+		return nil, &renewalError{http.StatusBadRequest, gin.H{"erro": err.Error()}}
+	}
+	orcamento, err := utils.QuotePrice(utils.PricingInput{
+		MemberID: memberID,
+		Produto:  models.ProdutoRenovacao,
+		Meses:    req.Meses,
+		Telas:    req.Telas,
+	})
+	if err != nil {
+		log.Printf("❌ Erro ao calcular preço da criação de cliente: %v", err)
+		return nil, &renewalError{http.StatusInternalServerError, gin.H{"erro": "Erro ao calcular o custo do cliente"}}
+	}
+	descricao := fmt.Sprintf("Criação de cliente: %d mês(es) x %d tela(s)", req.Meses, req.Telas)
+
+	// 🔹 Conferência do saldo sem trava: o painel não é chamado com a linha da revenda presa.
+	// O débito de verdade (com FOR UPDATE) vem depois que o painel aceitar o cliente.
+	var saldo float64
+	if err := config.DB.QueryRow("SELECT credits FROM streamcreed_db.reg_users WHERE id = ?", memberID).Scan(&saldo); err != nil {
+		log.Printf("❌ Erro ao buscar créditos da revenda %d: %v", memberID, err)
+		return nil, &renewalError{http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar créditos da revenda"}}
+	}
+	if saldo < orcamento.Total {
+		body, _ := creditosInsuficientesBody(&models.InsufficientCreditsError{Disponivel: saldo, Necessario: orcamento.Total})
+		return nil, &renewalError{http.StatusPaymentRequired, body}
+	}
+
+	form := url.Values{}
+	form.Add("action", "user")
+	form.Add("sub", "create")
+	form.Add("user_data[max_connections]", strconv.Itoa(req.Telas))
+	form.Add("user_data[is_restreamer]", "0")
+	form.Add("user_data[exp_date]", strconv.FormatInt(novoExp.Unix(), 10))
+	form.Add("user_data[bouquet]", req.Bouquet)
+	form.Add("user_data[member_id]", strconv.Itoa(memberID))
+	form.Add("user_data[is_trial]", "0")
+	form.Add("user_data[NUMERO_WHATS]", req.NumeroWhats)
+	form.Add("user_data[NOME_PARA_AVISO]", req.NomeParaAviso)
+	form.Add("user_data[reseller_notes]", observacao)
+	if req.FranquiaMemberID != nil {
+		form.Add("user_data[franquia_member_id]", strconv.Itoa(*req.FranquiaMemberID))
+	}
+
+	username, password := req.Username, req.Password
+	if username != "" {
+		err = createPanelUser(apiURL, form, username, password)
+	} else {
+		totalUserChars, _ := strconv.Atoi(os.Getenv("TOTAL_CARACTERES_USER"))
+		totalPassChars, _ := strconv.Atoi(os.Getenv("TOTAL_CARACTERES_SENHA"))
+		for tentativa := 1; tentativa <= maxTentativasUsuario; tentativa++ {
+			username = utils.GenerateUsername(totalUserChars, os.Getenv("PREFIXO_USR"))
+			password = utils.GeneratePassword(totalPassChars, os.Getenv("PREFIXO_SENHA"))
+			err = createPanelUser(apiURL, form, username, password)
+			if !errors.Is(err, errUsuarioExiste) {
+				break
+			}
+			log.Printf("⚠️ [Criação de Cliente Tentativa %d] Usuário %s rejeitado (EXISTS).", tentativa, username)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, errUsuarioExiste) {
+			if req.Username != "" {
+				return nil, &renewalError{http.StatusBadRequest, gin.H{"erro": "Nome de usuário já em uso. Tente outro ou deixe em branco para geração automática."}}
+			}
+			return nil, &renewalError{http.StatusInternalServerError, gin.H{"erro": "Não foi possível gerar um nome de usuário disponível. Tente novamente."}}
+		}
+		log.Printf("❌ Erro ao criar cliente no painel (revenda %d): %v", memberID, err)
+		return nil, &renewalError{http.StatusBadGateway, gin.H{"erro": "Erro ao criar cliente no painel"}}
+	}
+
+	// 🔹 O extrato aponta para o cliente recém-criado
+	var userID int
+	err = config.DB.QueryRow("SELECT id FROM streamcreed_db.users WHERE username = ? AND member_id = ? ORDER BY id DESC LIMIT 1", username, memberID).Scan(&userID)
+	if err != nil {
+		log.Printf("🚨 Cliente %s criado no painel, mas não encontrado no banco (revenda %d): %v", username, memberID, err)
+	}
+
+	// 🔹 Débito numa transação curta, já com o cliente criado; se falhar, o cliente é desfeito
+	movimento, err := debitPaidClient(memberID, userID, orcamento.Total, descricao)
+	if err != nil {
+		discardPaidClient(memberID, userID, username, err)
+		if body, ok := creditosInsuficientesBody(err); ok {
+			return nil, &renewalError{http.StatusPaymentRequired, body}
+		}
+		log.Printf("❌ Erro ao debitar créditos da criação de cliente (revenda %d): %v", memberID, err)
+		return nil, &renewalError{http.StatusInternalServerError, gin.H{"erro": "Não foi possível debitar os créditos"}}
+	}
+	log.Printf("✅ Cliente %s (ID %d) criado pela revenda %d. Créditos: %.2f", username, userID, memberID, orcamento.Total)
+
+	// 🔹 Mesma trilha da renovação no MongoDB: coleção renew e log de auditoria
+	saveRenewLog(memberID, userID, agora.Unix(), novoExp.Unix(), orcamento.Total, bson.M{"origem": clientCreateOrigem})
+	if err := saveAuditLogToMongo(c, userID, "create_client", nil, map[string]interface{}{
+		"username":        username,
+		"telas":           req.Telas,
+		"meses":           req.Meses,
+		"exp_date":        novoExp.Unix(),
+		"creditos_gastos": orcamento.Total,
+	}); err != nil {
+		log.Printf("⚠️ Erro ao salvar auditoria da criação do cliente %d: %v", userID, err)
+	}
+
+	return &paidClient{
+		UserID:            userID,
+		Username:          username,
+		Password:          password,
+		ExpDate:           novoExp,
+		Orcamento:         orcamento,
+		CreditosRestantes: movimento.SaldoDepois,
+	}, nil
+}
+
+// debitPaidClient cobra a criação do cliente; a linha da revenda fica travada só durante este débito
+func debitPaidClient(memberID, userID int, custo float64, descricao string) (*models.CreditLedgerEntry, error) {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	movimento, err := models.DebitCredits(tx, custo, models.CreditMovement{
+		MemberID:  memberID,
+		Operacao:  models.LedgerCriacaoCliente,
+		UserID:    userID,
+		ActorID:   memberID,
+		Descricao: descricao,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return movimento, nil
+}
+
+// discardPaidClient exclui logicamente o cliente criado no painel cujo débito falhou,
+// para que a revenda não fique com um cliente que não pagou
+func discardPaidClient(memberID, userID int, username string, motivo error) {
+	if userID == 0 {
+		log.Printf("🚨 Cliente %s criado no painel sem débito (revenda %d: %v) e não encontrado no banco para exclusão", username, memberID, motivo)
+		return
+	}
+	_, err := config.DB.Exec(`
+		UPDATE streamcreed_db.users
+		SET enabled = 0, date_deleted = ?, deleted = 1
+		WHERE id = ? AND member_id = ?`, time.Now(), userID, memberID)
+	if err != nil {
+		log.Printf("🚨 Cliente %s (ID %d) criado no painel sem débito (revenda %d: %v) e a exclusão falhou: %v", username, userID, memberID, motivo, err)
+		return
+	}
+	log.Printf("↩️ Cliente %s (ID %d) excluído: o débito da criação falhou (revenda %d): %v", username, userID, memberID, motivo)
+}
+
+// validateClientCredentials confere usuário e senha informados pela revenda (vazios não são conferidos):
+// tamanho entre minCaracteresCredencial e TOTAL_CARACTERES_USER/TOTAL_CARACTERES_SENHA, caracteres do
+// usuário e senha diferente do login. Usada na criação paga e na importação por CSV.
+func validateClientCredentials(username, password string, maxUserChars, maxPassChars int) []string {
+	var erros []string
+	if username != "" {
+		if len(username) < minCaracteresCredencial || len(username) > maxUserChars {
+			erros = append(erros, fmt.Sprintf("O nome de usuário deve ter entre %d e %d caracteres", minCaracteresCredencial, maxUserChars))
+		} else if !clientUsernamePattern.MatchString(username) {
+			erros = append(erros, "O nome de usuário só pode ter letras, números, ponto, hífen e sublinhado")
+		}
+	}
+	if password != "" {
+		if len(password) < minCaracteresCredencial || len(password) > maxPassChars {
+			erros = append(erros, fmt.Sprintf("A senha deve ter entre %d e %d caracteres", minCaracteresCredencial, maxPassChars))
+		}
+		if password == username {
+			erros = append(erros, "A senha não pode ser igual ao login.")
+		}
+	}
+	return erros
+}
+
+// normalizeBouquet valida o bouquet informado (lista JSON de IDs) ou usa o BOUQUET do .env
+func normalizeBouquet(bouquet string) (string, error) {
+	if strings.TrimSpace(bouquet) == "" {
+		bouquet = os.Getenv("BOUQUET")
+		if bouquet == "" {
+			return "", fmt.Errorf("bouquet não informado e BOUQUET não configurado")
+		}
+		return bouquet, nil
+	}
+	var ids []int
+	if err := json.Unmarshal([]byte(bouquet), &ids); err != nil || len(ids) == 0 {
+		return "", fmt.Errorf("bouquet inválido (use uma lista de IDs, ex.: [1, 5, 10])")
+	}
+	normalizado, _ := json.Marshal(ids)
+	return string(normalizado), nil
+}
+
+// panelHTTPClient limita a espera pelo painel
+var panelHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// createPanelUser cria o usuário no painel IPTV com as credenciais informadas.
+// Retorna errUsuarioExiste quando o painel responde EXISTS.
+func createPanelUser(apiURL string, base url.Values, username, password string) error {
+	form := url.Values{}
+	for k, v := range base {
+		form[k] = v
+	}
+	form.Set("user_data[username]", username)
+	form.Set("user_data[password]", password)
+
+	log.Printf("ℹ️  [Criação de Cliente] Enviando requisição para API IPTV. URL: %s, Usuário: %s", apiURL, username)
+	resp, err := panelHTTPClient.Post(apiURL, "application/x-www-form-urlencoded", bytes.NewBufferString(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("erro ao fazer POST para API IPTV: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("erro ao ler resposta da API IPTV: %w", err)
+	}
+	var responseMap map[string]interface{}
+	if err := json.Unmarshal(body, &responseMap); err != nil {
+		return fmt.Errorf("resposta inválida da API IPTV (%s): %s", resp.Status, string(body))
+	}
+	if result, ok := responseMap["result"].(bool); ok && result {
+		return nil
+	}
+	if errorMsg, ok := responseMap["error"].(string); ok && errorMsg == "EXISTS" {
+		return errUsuarioExiste
+	}
+	return fmt.Errorf("resposta inesperada da API IPTV: %+v", responseMap)
+}
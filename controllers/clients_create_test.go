@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateClientCredentials(t *testing.T) {
+	const maxUser, maxPass = 12, 8
+	casos := []struct {
+		nome     string
+		username string
+		password string
+		erros    int
+	}{
+		{"geração automática", "", "", 0},
+		{"válidos", "joao.silva_1", "abcd1234", 0},
+		{"usuário curto", "abc", "abcd1234", 1},
+		{"usuário longo", "joao_silva_123", "abcd1234", 1},
+		{"usuário com espaço", "joao 12", "abcd1234", 1},
+		{"senha longa", "joao123", "abcd12345", 1},
+		{"senha igual ao login", "joao123", "joao123", 1},
+		{"senha curta e igual ao login", "abc", "abc", 3},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			assert.Len(t, validateClientCredentials(tc.username, tc.password, maxUser, maxPass), tc.erros)
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/utils"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// Exportação da base de clientes
+//
+// Usa os mesmos filtros e ordenação de /api/clients-table e grava as linhas na resposta à medida
+// que são lidas do banco: o CSV vai direto para a conexão e o XLSX usa o StreamWriter do excelize,
+// que guarda as linhas em arquivo temporário em vez de memória. A senha só é exportada quando a
+// coluna password é pedida explicitamente em ?colunas=.
+//
+// Textos que começam com =, +, - ou @ recebem um ' na frente, para que o Excel não os execute
+// como fórmula. Se a leitura falhar no meio do CSV (o status 200 já foi enviado), o arquivo
+// termina com a linha clientExportErroMarcador em vez de parecer completo.
+
+// clientExportColumn é uma coluna exportável
+type clientExportColumn struct {
+	Expr     string // Expressão SQL
+	Titulo   string // Cabeçalho do arquivo
+	Numero   bool   // Gravada como número no XLSX
+	Unix     bool   // Timestamp UNIX formatado como data
+	Sigilosa bool   // Fora do padrão: só sai quando pedida em ?colunas=
+}
+
+var clientExportColumns = map[string]clientExportColumn{
+	"id":                 {Expr: "u.id", Titulo: "ID", Numero: true},
+	"username":           {Expr: "u.username", Titulo: "Usuário"},
+	"password":           {Expr: "u.password", Titulo: "Senha", Sigilosa: true},
+	"exp_date":           {Expr: "u.exp_date", Titulo: "Vencimento", Unix: true},
+	"max_connections":    {Expr: "u.max_connections", Titulo: "Telas", Numero: true},
+	"enabled":            {Expr: "u.enabled", Titulo: "Ativo", Numero: true},
+	"admin_enabled":      {Expr: "u.admin_enabled", Titulo: "Liberado pelo admin", Numero: true},
+	"is_trial":           {Expr: "u.is_trial", Titulo: "Teste", Numero: true},
+	"created_at":         {Expr: "u.created_at", Titulo: "Criado em", Unix: true},
+	"reseller_notes":     {Expr: "u.reseller_notes", Titulo: "Observações"},
+	"numero_whats":       {Expr: "u.numero_whats", Titulo: "WhatsApp"},
+	"nome_para_aviso":    {Expr: "u.nome_para_aviso", Titulo: "Nome para aviso"},
+	"aplicativo":         {Expr: "u.Aplicativo", Titulo: "Aplicativo"},
+	"bouquet":            {Expr: "u.bouquet", Titulo: "Bouquet"},
+	"franquia_member_id": {Expr: "u.franquia_member_id", Titulo: "Franquia", Numero: true},
+}
+
+// clientExportDefault são as colunas exportadas quando ?colunas= não é informado (sem a senha)
+var clientExportDefault = []string{
+	"id", "username", "exp_date", "max_connections", "enabled", "is_trial", "created_at",
+	"reseller_notes", "numero_whats", "nome_para_aviso", "aplicativo", "franquia_member_id",
+}
+
+// clientExportErroMarcador é a última linha de um CSV interrompido por erro
+const clientExportErroMarcador = "#ERRO: exportação interrompida; o arquivo está incompleto"
+
+// escapeFormula neutraliza textos que o Excel interpretaria como fórmula (injeção de CSV)
+func escapeFormula(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+// clientExportFlushLinhas define a cada quantas linhas o CSV é enviado ao cliente
+const clientExportFlushLinhas = 500
+
+// ExportClientsTable exporta a base de clientes em CSV ou XLSX
+// @Summary Exporta clientes em CSV ou XLSX
+// @Description Exporta os clientes da revenda com os mesmos filtros e ordenação de /api/clients-table (sem paginação). O arquivo é gerado enquanto as linhas são lidas do banco. Textos iniciados por =, +, - ou @ saem com um ' na frente (proteção contra fórmulas). Se a leitura falhar no meio de um CSV, a última linha do arquivo é "#ERRO: exportação interrompida; o arquivo está incompleto". Colunas disponíveis: id, username, password, exp_date, max_connections, enabled, admin_enabled, is_trial, created_at, reseller_notes, numero_whats, nome_para_aviso, aplicativo, bouquet, franquia_member_id. A senha (password) só é exportada quando pedida explicitamente em colunas.
+// @Tags ClientsTable
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string true "csv ou xlsx"
+// @Param colunas query string false "Colunas separadas por vírgula, na ordem desejada (padrão: todas exceto password)"
+// @Param search query string false "Termo de pesquisa para filtrar por username ou reseller_notes"
+// @Param online query bool false "Apenas clientes online"
+// @Param expiration_filter query int false "Vencendo nos próximos N dias ou -1 para vencidos (0 = sem filtro)"
+// @Param vencimento_de query int false "Vencimento a partir de (timestamp UNIX)"
+// @Param vencimento_ate query int false "Vencimento até (timestamp UNIX)"
+// @Param franquia_member_id query int false "Filtrar por ID do membro da franquia"
+// @Param is_trial query string false "0 para não trial, 1 para trial"
+// @Param ordenar_por query string false "vencimento, criacao (padrão) ou username"
+// @Param ordem query string false "asc ou desc (padrão: desc)"
+// @Param revenda_id query int false "ID de uma sub-revenda da árvore (padrão: a própria revenda)"
+// @Success 200 {file} file "Arquivo com os clientes"
+// @Failure 400 {object} map[string]string "Formato, coluna ou filtro inválido"
+// @Failure 401 {object} map[string]string "Token inválido ou não fornecido"
+// @Failure 500 {object} map[string]string "Erro ao buscar clientes"
+// @Router /api/clients-table/export [get]
+func ExportClientsTable(c *gin.Context) {
+	claims, _, err := utils.RequestClaims(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token inválido"})
+		return
+	}
+	memberIDFloat, exists := claims["member_id"].(float64)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"erro": "MemberID não encontrado no token"})
+		return
+	}
+	memberID, ok := resolveRevendaAlvo(c, claims, int(memberIDFloat))
+	if !ok {
+		return
+	}
+
+	format := strings.ToLower(c.Query("format"))
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "format deve ser csv ou xlsx"})
+		return
+	}
+
+	nomes := clientExportDefault
+	if raw := strings.TrimSpace(c.Query("colunas")); raw != "" {
+		nomes = nil
+		for _, nome := range strings.Split(raw, ",") {
+			nome = strings.ToLower(strings.TrimSpace(nome))
+			if _, ok := clientExportColumns[nome]; !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"erro": "Coluna desconhecida: " + nome})
+				return
+			}
+			nomes = append(nomes, nome)
+		}
+	}
+	colunas := make([]clientExportColumn, len(nomes))
+	exprs := make([]string, len(nomes))
+	incluiSenha := false
+	for i, nome := range nomes {
+		colunas[i] = clientExportColumns[nome]
+		exprs[i] = colunas[i].Expr
+		incluiSenha = incluiSenha || colunas[i].Sigilosa
+	}
+
+	filtro, ok := parseClientsTableFilter(c, memberID)
+	if !ok {
+		return
+	}
+	query := `SELECT ` + strings.Join(exprs, ", ") + ` FROM users u` + filtro.Where +
+		fmt.Sprintf(` ORDER BY %s %s, u.id %s`, filtro.SortExpr, filtro.Direcao, filtro.Direcao)
+
+	rows, err := config.DB.QueryContext(c.Request.Context(), query, filtro.Args...)
+	if err != nil {
+		log.Printf("❌ Erro ao exportar clientes da revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar clientes"})
+		return
+	}
+	defer rows.Close()
+
+	if incluiSenha {
+		log.Printf("⚠️ Exportação de clientes COM SENHAS pela revenda %d (usuário %v)", memberID, claims["username"])
+	}
+
+	location, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		location = time.Local
+	}
+	valores := make([]sql.NullString, len(colunas))
+	destinos := make([]interface{}, len(colunas))
+	for i := range valores {
+		destinos[i] = &valores[i]
+	}
+	// proximaLinha lê a próxima linha já formatada (datas em America/Sao_Paulo)
+	proximaLinha := func() ([]string, error) {
+		if err := rows.Scan(destinos...); err != nil {
+			return nil, err
+		}
+		linha := make([]string, len(colunas))
+		for i, v := range valores {
+			switch {
+			case colunas[i].Unix:
+				linha[i] = v.String
+				if ts, err := strconv.ParseInt(v.String, 10, 64); err == nil && ts > 0 {
+					linha[i] = time.Unix(ts, 0).In(location).Format("2006-01-02 15:04:05")
+				}
+			case colunas[i].Numero:
+				linha[i] = v.String
+			default:
+				linha[i] = escapeFormula(v.String)
+			}
+		}
+		return linha, nil
+	}
+
+	titulos := make([]string, len(colunas))
+	for i, col := range colunas {
+		titulos[i] = col.Titulo
+	}
+	arquivo := fmt.Sprintf("clientes_%s.%s", time.Now().In(location).Format("20060102_150405"), format)
+	disposicao := `attachment; filename="` + arquivo + `"`
+
+	total := 0
+	if format == "csv" {
+		c.Header("Content-Disposition", disposicao)
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		c.Writer.WriteString("\ufeff") // BOM: o Excel reconhece o UTF-8
+		w := csv.NewWriter(c.Writer)
+		w.Write(titulos)
+		var erroLeitura error
+		for rows.Next() {
+			linha, err := proximaLinha()
+			if err != nil {
+				erroLeitura = err
+				break
+			}
+			w.Write(linha)
+			if total++; total%clientExportFlushLinhas == 0 {
+				w.Flush()
+				c.Writer.Flush()
+			}
+		}
+		if erroLeitura == nil {
+			erroLeitura = rows.Err()
+		}
+		if erroLeitura != nil {
+			log.Printf("❌ Erro ao ler clientes na exportação (revenda %d) após %d linha(s): %v", memberID, total, erroLeitura)
+			w.Write([]string{clientExportErroMarcador})
+			w.Flush()
+			return
+		}
+		w.Flush()
+	} else {
+		f := excelize.NewFile()
+		defer f.Close()
+		sw, err := f.NewStreamWriter("Sheet1")
+		if err != nil {
+			log.Printf("❌ Erro ao criar planilha de exportação: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar planilha"})
+			return
+		}
+		celulas := make([]interface{}, len(colunas))
+		for i, t := range titulos {
+			celulas[i] = t
+		}
+		sw.SetRow("A1", celulas)
+		for rows.Next() {
+			linha, err := proximaLinha()
+			if err != nil {
+				log.Printf("❌ Erro ao ler cliente na exportação (revenda %d): %v", memberID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao processar os dados"})
+				return
+			}
+			celulas := make([]interface{}, len(linha))
+			for i, v := range linha {
+				celulas[i] = v
+				if n, err := strconv.ParseFloat(v, 64); err == nil && colunas[i].Numero {
+					celulas[i] = n
+				}
+			}
+			total++
+			cell, _ := excelize.CoordinatesToCellName(1, total+1)
+			if err := sw.SetRow(cell, celulas); err != nil {
+				log.Printf("❌ Erro ao gravar linha %d da planilha: %v", total+1, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar planilha"})
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			log.Printf("❌ Erro ao ler clientes na exportação (revenda %d): %v", memberID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao processar os dados"})
+			return
+		}
+		if err := sw.Flush(); err != nil {
+			log.Printf("❌ Erro ao finalizar planilha de exportação: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar planilha"})
+			return
+		}
+		c.Header("Content-Disposition", disposicao)
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Status(http.StatusOK)
+		if err := f.Write(c.Writer); err != nil {
+			log.Printf("❌ Erro ao enviar planilha de exportação: %v", err)
+			return
+		}
+	}
+	log.Printf("✅ Exportação %s da revenda %d: %d cliente(s)", format, memberID, total)
+}
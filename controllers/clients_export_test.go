@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	casos := []struct {
+		entrada string
+		want    string
+	}{
+		{"", ""},
+		{"joao123", "joao123"},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+5511999998888", "'+5511999998888"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"a=b", "a=b"},
+	}
+	for _, tc := range casos {
+		assert.Equal(t, tc.want, escapeFormula(tc.entrada), tc.entrada)
+	}
+}
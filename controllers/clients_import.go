@@ -0,0 +1,630 @@
+package controllers
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"apiBackEnd/utils"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Importação de clientes por CSV
+//
+// Cada linha é validada antes de qualquer cobrança: tamanho do usuário (4 a TOTAL_CARACTERES_USER) e da
+// senha (4 a TOTAL_CARACTERES_SENHA), usuários repetidos no arquivo ou já existentes no painel e o
+// formato do WhatsApp. Com dry_run=true a resposta é só o relatório (custo de cada linha e total).
+// Sem dry_run, os clientes válidos são criados em segundo plano, um a um, com a mesma cobrança da
+// criação paga (createPaidClient); o andamento e os erros por linha ficam em GET /api/clients/import/:job_id.
+// Uma importação por revenda de cada vez; se os créditos acabarem, as linhas restantes ficam como
+// nao_processada. As senhas (informadas ou geradas) nunca vão para o andamento: ao final, os logins
+// dos clientes criados podem ser lidos uma única vez em POST /api/clients/import/:job_id/credenciais (POST porque a
+// leitura apaga as credenciais; assim a personificação, que só permite leitura, não as consome).
+//
+// Colunas (cabeçalho obrigatório, separador vírgula ou ponto e vírgula):
+//
+//	username, password, meses, telas, numero_whats, nome_para_aviso, bouquet, franquia_member_id
+//
+// Só meses é obrigatória; username/password em branco são gerados, telas padrão 1, bouquet padrão BOUQUET.
+
+// maxImportacaoBytes limita o tamanho do arquivo enviado
+const maxImportacaoBytes = 5 << 20
+
+// importacaoTrava é o TTL da trava de importação da revenda; renovado enquanto a importação executa (utils.KeepJobLock)
+const importacaoTrava = 2 * time.Hour
+
+// importCSVAliases aceita nomes de coluna alternativos (ex.: planilhas de outros painéis)
+var importCSVAliases = map[string]string{
+	"usuario":          "username",
+	"login":            "username",
+	"senha":            "password",
+	"quantidade_meses": "meses",
+	"max_connections":  "telas",
+	"whatsapp":         "numero_whats",
+	"nome":             "nome_para_aviso",
+	"franquia":         "franquia_member_id",
+}
+
+// normalizeWhatsApp remove formatação e valida o número (DDI + DDD + número, 10 a 15 dígitos)
+func normalizeWhatsApp(numero string) (string, error) {
+	limpo := strings.NewReplacer("+", "", " ", "", "-", "", "(", "", ")", "", ".", "").Replace(strings.TrimSpace(numero))
+	if limpo == "" {
+		return "", nil
+	}
+	if len(limpo) < 10 || len(limpo) > 15 || strings.Trim(limpo, "0123456789") != "" {
+		return "", fmt.Errorf("WhatsApp inválido: use DDI + DDD + número, ex.: 5511999998888")
+	}
+	return limpo, nil
+}
+
+// clientImportPlan é o resultado da validação: o relatório e os dados de criação das linhas válidas
+type clientImportPlan struct {
+	Linhas     []models.ClientImportRow
+	Requests   map[int]CreateClientRequest // Índice em Linhas -> dados de criação (com a senha)
+	Validas    int
+	CustoTotal float64
+}
+
+// ImportClientsHandler valida um CSV de clientes e, fora do dry-run, inicia a importação em segundo plano
+// @Summary Importar clientes por CSV
+// @Description Valida cada linha do CSV (usuário com 4 a TOTAL_CARACTERES_USER caracteres, senha com 4 a TOTAL_CARACTERES_SENHA, usuários repetidos no arquivo ou existentes no painel, formato do WhatsApp) e calcula o custo pelas regras da criação paga. Com dry_run=true devolve apenas o relatório. Sem dry_run, cria os clientes válidos em segundo plano e devolve o job_id para acompanhar em GET /api/clients/import/{job_id} (os logins e senhas dos clientes criados são lidos uma única vez em POST /api/clients/import/{job_id}/credenciais); linhas inválidas bloqueiam a importação, a menos que ignorar_invalidas=true. Colunas: username, password, meses (obrigatória), telas, numero_whats, nome_para_aviso, bouquet, franquia_member_id.
+// @Tags Clientes
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param arquivo formData file true "Arquivo CSV (cabeçalho obrigatório, separador vírgula ou ponto e vírgula)"
+// @Param dry_run query bool false "Apenas validar e orçar, sem criar clientes"
+// @Param ignorar_invalidas query bool false "Importar as linhas válidas mesmo havendo linhas inválidas"
+// @Success 200 {object} map[string]interface{} "Relatório do dry-run (total, validas, invalidas, custo_total, creditos_disponiveis, creditos_suficientes, linhas)"
+// @Success 202 {object} map[string]interface{} "Importação iniciada: {\"job_id\": \"...\", \"status\": \"pendente\", \"total\": 120}"
+// @Failure 400 {object} map[string]interface{} "Arquivo inválido ou linhas inválidas (com o relatório)"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Param Idempotency-Key header string false "Chave única da operação: repetições com a mesma chave e o mesmo arquivo retornam a resposta original sem importar de novo"
+// @Failure 409 {object} map[string]string "Já existe uma importação em andamento para a revenda"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/clients/import [post]
+func ImportClientsHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+	memberID := tokenInfo.MemberID
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	ignorarInvalidas, _ := strconv.ParseBool(c.DefaultQuery("ignorar_invalidas", "false"))
+
+	apiURL := os.Getenv("IPTV_API_URL")
+	maxUserChars, errUser := strconv.Atoi(os.Getenv("TOTAL_CARACTERES_USER"))
+	maxPassChars, errPass := strconv.Atoi(os.Getenv("TOTAL_CARACTERES_SENHA"))
+	if apiURL == "" || errUser != nil || errPass != nil || maxUserChars < 1 || maxPassChars < 1 {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Configuração inválida no .env"})
+		return
+	}
+
+	arquivo, err := c.FormFile("arquivo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Envie o CSV no campo 'arquivo'"})
+		return
+	}
+	if arquivo.Size > maxImportacaoBytes {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": fmt.Sprintf("Arquivo maior que %d MB", maxImportacaoBytes>>20)})
+		return
+	}
+	f, err := arquivo.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Não foi possível ler o arquivo"})
+		return
+	}
+	defer f.Close()
+	conteudo, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Não foi possível ler o arquivo"})
+		return
+	}
+
+	registros, err := readImportCSV(conteudo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	plano, err := validateImportRows(memberID, registros, maxUserChars, maxPassChars)
+	if err != nil {
+		log.Printf("❌ Erro ao validar importação da revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao validar o arquivo"})
+		return
+	}
+
+	var creditos float64
+	if err := config.DB.QueryRow("SELECT credits FROM streamcreed_db.reg_users WHERE id = ?", memberID).Scan(&creditos); err != nil {
+		log.Printf("❌ Erro ao buscar créditos da revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar créditos"})
+		return
+	}
+	relatorio := gin.H{
+		"total":                len(plano.Linhas),
+		"validas":              plano.Validas,
+		"invalidas":            len(plano.Linhas) - plano.Validas,
+		"custo_total":          plano.CustoTotal,
+		"creditos_disponiveis": creditos,
+		"creditos_suficientes": creditos >= plano.CustoTotal,
+		"linhas":               plano.Linhas,
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, relatorio)
+		return
+	}
+	if plano.Validas == 0 {
+		relatorio["erro"] = "Nenhuma linha válida para importar"
+		c.JSON(http.StatusBadRequest, relatorio)
+		return
+	}
+	if plano.Validas < len(plano.Linhas) && !ignorarInvalidas {
+		relatorio["erro"] = "O arquivo tem linhas inválidas. Corrija-as ou envie ignorar_invalidas=true para importar apenas as válidas."
+		c.JSON(http.StatusBadRequest, relatorio)
+		return
+	}
+
+	travaNome := "importacao_clientes:" + strconv.Itoa(memberID)
+	token, obtida, err := utils.AcquireJobLock(c, travaNome, importacaoTrava)
+	if err != nil {
+		log.Printf("❌ Erro ao obter trava da importação da revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao iniciar importação"})
+		return
+	}
+	if !obtida {
+		c.JSON(http.StatusConflict, gin.H{"erro": "Já existe uma importação em andamento para esta revenda"})
+		return
+	}
+
+	job := &models.ClientImportJob{
+		MemberID: memberID,
+		Status:   models.ImportacaoPendente,
+		Total:    plano.Validas,
+		Linhas:   plano.Linhas,
+	}
+	if err := utils.SaveImportJob(c, job); err != nil {
+		utils.ReleaseJobLock(c, travaNome, token)
+		log.Printf("❌ Erro ao gravar importação da revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao iniciar importação"})
+		return
+	}
+	log.Printf("📥 Importação %s iniciada pela revenda %d: %d cliente(s), custo estimado %.2f", job.ID, memberID, plano.Validas, plano.CustoTotal)
+
+	// O contexto da requisição termina com a resposta: o job usa uma cópia (auditoria) e o seu próprio contexto
+	cc := c.Copy()
+	go func() {
+		// Renovada enquanto a importação executa: um CSV grande num painel lento não libera a trava no meio
+		stopKeep := utils.KeepJobLock(travaNome, token, importacaoTrava)
+		defer func() {
+			stopKeep()
+			if err := utils.ReleaseJobLock(context.Background(), travaNome, token); err != nil {
+				log.Printf("⚠️ Erro ao liberar trava da importação %s: %v", job.ID, err)
+			}
+		}()
+		// Um panic aqui derrubaria a API inteira: a importação é marcada como interrompida
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("❌ Panic na importação %s da revenda %d: %v", job.ID, memberID, r)
+				abortClientImport(job)
+			}
+		}()
+		runClientImport(cc, apiURL, job, plano.Requests)
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"job_id":      job.ID,
+		"status":      job.Status,
+		"total":       job.Total,
+		"custo_total": plano.CustoTotal,
+	})
+}
+
+// GetImportJobHandler retorna o andamento de uma importação
+// @Summary Andamento da importação de clientes
+// @Description Retorna o estado da importação (pendente, processando, concluida, interrompida), os contadores e o resultado de cada linha (criada, falhou, invalida, nao_processada) com os erros. Disponível por IMPORTACAO_RETENCAO_HORAS horas (padrão 24).
+// @Tags Clientes
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param job_id path string true "ID da importação"
+// @Success 200 {object} models.ClientImportJob
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 404 {object} map[string]string "Importação não encontrada ou expirada"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/clients/import/{job_id} [get]
+func GetImportJobHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+	job, err := utils.GetImportJob(c, c.Param("job_id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrImportJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"erro": err.Error()})
+			return
+		}
+		log.Printf("❌ Erro ao buscar importação %s: %v", c.Param("job_id"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar importação"})
+		return
+	}
+	// Outra revenda recebe 404, sem confirmar que o ID existe
+	if job.MemberID != tokenInfo.MemberID {
+		c.JSON(http.StatusNotFound, gin.H{"erro": utils.ErrImportJobNotFound.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// ClaimImportCredentialsHandler devolve, uma única vez, os logins e senhas dos clientes criados
+// @Summary Credenciais dos clientes importados
+// @Description Devolve o usuário e a senha (informada no CSV ou gerada) de cada cliente criado pela importação. Disponível só depois que a importação termina, apenas para a revenda que a iniciou, por IMPORTACAO_CREDENCIAIS_MINUTOS minutos (padrão 60), e apagado na primeira leitura. É POST porque consome as credenciais: bloqueado durante personificação.
+// @Tags Clientes
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param job_id path string true "ID da importação"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"job_id\": \"...\", \"credenciais\": [{\"linha\": 2, \"id_cliente\": 10, \"username\": \"joao123\", \"password\": \"x8k2m4\"}]}"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 403 {object} map[string]string "Bloqueado durante personificação"
+// @Failure 404 {object} map[string]string "Importação não encontrada ou credenciais já lidas/expiradas"
+// @Failure 409 {object} map[string]string "Importação ainda em andamento"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/clients/import/{job_id}/credenciais [post]
+func ClaimImportCredentialsHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+	job, err := utils.GetImportJob(c, c.Param("job_id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrImportJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"erro": err.Error()})
+			return
+		}
+		log.Printf("❌ Erro ao buscar importação %s: %v", c.Param("job_id"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar importação"})
+		return
+	}
+	if job.MemberID != tokenInfo.MemberID {
+		c.JSON(http.StatusNotFound, gin.H{"erro": utils.ErrImportJobNotFound.Error()})
+		return
+	}
+	// Lidas antes do fim, as credenciais dos clientes criados depois voltariam junto com as já entregues
+	if job.Status == models.ImportacaoPendente || job.Status == models.ImportacaoProcessando {
+		c.JSON(http.StatusConflict, gin.H{"erro": "Importação ainda em andamento. Aguarde a conclusão para ler as credenciais."})
+		return
+	}
+
+	creds, err := utils.TakeImportCredentials(c, job.ID)
+	if err != nil {
+		if errors.Is(err, utils.ErrImportCredentialsNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"erro": err.Error()})
+			return
+		}
+		log.Printf("❌ Erro ao ler credenciais da importação %s: %v", job.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao ler credenciais"})
+		return
+	}
+	log.Printf("🔑 Credenciais da importação %s entregues à revenda %d (%d cliente(s))", job.ID, job.MemberID, len(creds))
+
+	job.CredenciaisDisponiveis = false
+	if err := utils.SaveImportJob(c, job); err != nil {
+		log.Printf("⚠️ Erro ao gravar andamento da importação %s: %v", job.ID, err)
+	}
+	c.JSON(http.StatusOK, gin.H{"job_id": job.ID, "credenciais": creds})
+}
+
+// readImportCSV lê o CSV (com ou sem BOM, separador vírgula ou ponto e vírgula) e devolve as linhas
+// como mapas coluna -> valor; a linha do arquivo de cada registro é o índice + 2
+func readImportCSV(conteudo []byte) ([]map[string]string, error) {
+	conteudo = bytes.TrimPrefix(conteudo, []byte("\ufeff"))
+	cabecalho := conteudo
+	if i := bytes.IndexByte(conteudo, '\n'); i >= 0 {
+		cabecalho = conteudo[:i]
+	}
+	r := csv.NewReader(bytes.NewReader(conteudo))
+	if bytes.Count(cabecalho, []byte(";")) > bytes.Count(cabecalho, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	colunas, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("CSV vazio ou inválido")
+	}
+	for i, col := range colunas {
+		col = strings.ToLower(strings.TrimSpace(col))
+		if alias, ok := importCSVAliases[col]; ok {
+			col = alias
+		}
+		colunas[i] = col
+	}
+	temMeses := false
+	for _, col := range colunas {
+		temMeses = temMeses || col == "meses"
+	}
+	if !temMeses {
+		return nil, fmt.Errorf("o cabeçalho precisa da coluna 'meses'")
+	}
+
+	var registros []map[string]string
+	for {
+		campos, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("CSV inválido: %v", err)
+		}
+		registro := make(map[string]string, len(colunas))
+		for i, col := range colunas {
+			if i < len(campos) {
+				registro[col] = strings.TrimSpace(campos[i])
+			}
+		}
+		registros = append(registros, registro)
+		if len(registros) > utils.GetImportacaoMaxLinhas() {
+			return nil, fmt.Errorf("o arquivo tem mais de %d linhas", utils.GetImportacaoMaxLinhas())
+		}
+	}
+	if len(registros) == 0 {
+		return nil, fmt.Errorf("o arquivo não tem clientes")
+	}
+	return registros, nil
+}
+
+// validateImportRows valida as linhas e calcula o custo de cada uma pelas regras da criação paga
+func validateImportRows(memberID int, registros []map[string]string, maxUserChars, maxPassChars int) (*clientImportPlan, error) {
+	plano := &clientImportPlan{Requests: make(map[int]CreateClientRequest)}
+	vistos := make(map[string]int) // username em minúsculas -> linha
+	orcamentos := make(map[[2]int]*models.PriceQuote)
+
+	for i, registro := range registros {
+		linha, req := parseImportRow(i+2, registro, maxUserChars, maxPassChars)
+		if req.Username != "" {
+			chave := strings.ToLower(req.Username)
+			if anterior, ok := vistos[chave]; ok {
+				linha.Erros = append(linha.Erros, fmt.Sprintf("Nome de usuário repetido no arquivo (linha %d)", anterior))
+			} else {
+				vistos[chave] = linha.Linha
+			}
+		}
+
+		if len(linha.Erros) == 0 {
+			chave := [2]int{req.Meses, req.Telas}
+			orcamento, ok := orcamentos[chave]
+			if !ok {
+				var err error
+				orcamento, err = utils.QuotePrice(utils.PricingInput{
+					MemberID: memberID,
+					Produto:  models.ProdutoRenovacao,
+					Meses:    req.Meses,
+					Telas:    req.Telas,
+				})
+				if err != nil {
+					return nil, err
+				}
+				orcamentos[chave] = orcamento
+			}
+			linha.Custo = orcamento.Total
+		}
+		plano.Linhas = append(plano.Linhas, linha)
+		plano.Requests[i] = req
+	}
+
+	// Usuários que já existem no painel (em lotes, para não montar um IN gigante)
+	const lote = 500
+	nomes := make([]string, 0, len(vistos))
+	for i := range plano.Linhas {
+		if plano.Requests[i].Username != "" {
+			nomes = append(nomes, plano.Requests[i].Username)
+		}
+	}
+	existentes := make(map[string]bool)
+	for inicio := 0; inicio < len(nomes); inicio += lote {
+		fim := inicio + lote
+		if fim > len(nomes) {
+			fim = len(nomes)
+		}
+		args := make([]interface{}, 0, fim-inicio)
+		for _, nome := range nomes[inicio:fim] {
+			args = append(args, nome)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+		rows, err := config.DB.Query("SELECT username FROM streamcreed_db.users WHERE username IN ("+placeholders+")", args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var nome string
+			if err := rows.Scan(&nome); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			existentes[strings.ToLower(nome)] = true
+		}
+		rows.Close()
+	}
+
+	for i := range plano.Linhas {
+		linha := &plano.Linhas[i]
+		if existentes[strings.ToLower(plano.Requests[i].Username)] {
+			linha.Erros = append(linha.Erros, "Nome de usuário já em uso no painel")
+		}
+		if len(linha.Erros) > 0 {
+			linha.Status = models.LinhaInvalida
+			linha.Custo = 0
+			delete(plano.Requests, i)
+			continue
+		}
+		plano.Validas++
+		plano.CustoTotal += linha.Custo
+	}
+	plano.CustoTotal = math.Round(plano.CustoTotal*100) / 100
+	return plano, nil
+}
+
+// parseImportRow valida os campos de uma linha (sem consultar o banco) e monta os dados de criação;
+// a senha é gerada quando só o usuário é informado
+func parseImportRow(numero int, registro map[string]string, maxUserChars, maxPassChars int) (models.ClientImportRow, CreateClientRequest) {
+	linha := models.ClientImportRow{Linha: numero, Username: registro["username"], Status: models.LinhaValida}
+	req := CreateClientRequest{
+		Username:      registro["username"],
+		Password:      registro["password"],
+		NomeParaAviso: registro["nome_para_aviso"],
+	}
+	erro := func(msg string) { linha.Erros = append(linha.Erros, msg) }
+
+	for _, msg := range validateClientCredentials(req.Username, req.Password, maxUserChars, maxPassChars) {
+		erro(msg)
+	}
+	if req.Username != "" && req.Password == "" {
+		req.Password = utils.GeneratePassword(maxPassChars, os.Getenv("PREFIXO_SENHA"))
+	}
+	if req.Username == "" && req.Password != "" {
+		erro("Senha informada sem nome de usuário")
+	}
+
+	whats, err := normalizeWhatsApp(registro["numero_whats"])
+	if err != nil {
+		erro(err.Error())
+	}
+	req.NumeroWhats = whats
+	linha.NumeroWhats = whats
+
+	req.Meses, err = strconv.Atoi(registro["meses"])
+	if err != nil || req.Meses < 1 || req.Meses > maxRenovacaoDias/30 {
+		erro(fmt.Sprintf("meses deve ser um número entre 1 e %d", maxRenovacaoDias/30))
+	}
+	req.Telas = 1
+	if v := registro["telas"]; v != "" {
+		if req.Telas, err = strconv.Atoi(v); err != nil || req.Telas < 1 || req.Telas > maxTelasCliente {
+			erro(fmt.Sprintf("telas deve estar entre 1 e %d", maxTelasCliente))
+		}
+	}
+	linha.Meses, linha.Telas = req.Meses, req.Telas
+
+	if req.Bouquet, err = normalizeBouquet(registro["bouquet"]); err != nil {
+		erro(err.Error())
+	}
+	if v := registro["franquia_member_id"]; v != "" {
+		franquia, err := strconv.Atoi(v)
+		if err != nil || franquia <= 0 {
+			erro("franquia_member_id inválido")
+		} else {
+			req.FranquiaMemberID = &franquia
+		}
+	}
+	return linha, req
+}
+
+// runClientImport cria os clientes válidos um a um, gravando o andamento após cada linha
+func runClientImport(c *gin.Context, apiURL string, job *models.ClientImportJob, requests map[int]CreateClientRequest) {
+	ctx := context.Background()
+	job.Status = models.ImportacaoProcessando
+	if err := utils.SaveImportJob(ctx, job); err != nil {
+		log.Printf("⚠️ Erro ao gravar andamento da importação %s: %v", job.ID, err)
+	}
+
+	semCreditos := false
+	var credenciais []models.ClientImportCredential
+	for i := range job.Linhas {
+		linha := &job.Linhas[i]
+		req, ok := requests[i]
+		if !ok {
+			continue // Linha inválida
+		}
+		if semCreditos {
+			linha.Status = models.LinhaNaoProcessada
+			linha.Erros = append(linha.Erros, "Créditos insuficientes")
+			job.Processadas++
+			continue
+		}
+
+		cliente, rerr := createPaidClient(c, apiURL, job.MemberID, req, "Importado via CSV")
+		job.Processadas++
+		if rerr != nil {
+			msg, _ := rerr.Body["erro"].(string)
+			linha.Status = models.LinhaFalhou
+			linha.Erros = append(linha.Erros, msg)
+			job.Falhas++
+			if rerr.Status == http.StatusPaymentRequired {
+				semCreditos = true
+			}
+			log.Printf("⚠️ Importação %s, linha %d (%s): %s", job.ID, linha.Linha, req.Username, msg)
+		} else {
+			linha.Status = models.LinhaCriada
+			linha.Username = cliente.Username
+			linha.IDCliente = cliente.UserID
+			linha.Custo = cliente.Orcamento.Total
+			job.Criadas++
+			job.CreditosGastos += cliente.Orcamento.Total
+
+			credenciais = append(credenciais, models.ClientImportCredential{
+				Linha:     linha.Linha,
+				IDCliente: cliente.UserID,
+				Username:  cliente.Username,
+				Password:  cliente.Password,
+			})
+			if err := utils.SaveImportCredentials(ctx, job.ID, credenciais); err != nil {
+				log.Printf("⚠️ Erro ao gravar credenciais da importação %s: %v", job.ID, err)
+			} else {
+				job.CredenciaisDisponiveis = true
+			}
+		}
+		if err := utils.SaveImportJob(ctx, job); err != nil {
+			log.Printf("⚠️ Erro ao gravar andamento da importação %s: %v", job.ID, err)
+		}
+	}
+
+	job.Status = models.ImportacaoConcluida
+	if err := utils.SaveImportJob(ctx, job); err != nil {
+		log.Printf("⚠️ Erro ao gravar andamento da importação %s: %v", job.ID, err)
+	}
+	log.Printf("✅ Importação %s concluída: %d criado(s), %d falha(s), %.2f créditos", job.ID, job.Criadas, job.Falhas, job.CreditosGastos)
+
+	if err := utils.SaveToMongo("clients_import", bson.M{
+		"job_id":          job.ID,
+		"member_id":       job.MemberID,
+		"total":           job.Total,
+		"criadas":         job.Criadas,
+		"falhas":          job.Falhas,
+		"creditos_gastos": job.CreditosGastos,
+		"criado_em":       job.CriadoEm,
+		"concluido_em":    time.Now(),
+	}); err != nil {
+		log.Printf("⚠️ Erro ao salvar log da importação %s no MongoDB: %v", job.ID, err)
+	}
+}
+
+// abortClientImport marca a importação como interrompida após um erro inesperado; as linhas válidas
+// ainda não processadas ficam como nao_processada. As credenciais já criadas continuam disponíveis.
+func abortClientImport(job *models.ClientImportJob) {
+	for i := range job.Linhas {
+		if job.Linhas[i].Status == models.LinhaValida {
+			job.Linhas[i].Status = models.LinhaNaoProcessada
+			job.Linhas[i].Erros = append(job.Linhas[i].Erros, "Importação interrompida")
+		}
+	}
+	job.Status = models.ImportacaoInterrompida
+	job.Erro = "Erro inesperado durante a importação; as linhas restantes não foram processadas"
+	if err := utils.SaveImportJob(context.Background(), job); err != nil {
+		log.Printf("⚠️ Erro ao gravar andamento da importação %s: %v", job.ID, err)
+	}
+}
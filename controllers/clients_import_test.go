@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"apiBackEnd/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeWhatsApp(t *testing.T) {
+	casos := []struct {
+		entrada string
+		want    string
+		erro    bool
+	}{
+		{"", "", false},
+		{"   ", "", false},
+		{"5511999998888", "5511999998888", false},
+		{"+55 (11) 99999-8888", "5511999998888", false},
+		{"55.11.3333.4444", "551133334444", false},
+		{"119999", "", true},
+		{"5511999998888123456", "", true},
+		{"55 11 9999x-8888", "", true},
+	}
+	for _, tc := range casos {
+		t.Run(tc.entrada, func(t *testing.T) {
+			got, err := normalizeWhatsApp(tc.entrada)
+			if tc.erro {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestReadImportCSV(t *testing.T) {
+	t.Setenv("IMPORTACAO_MAX_LINHAS", "3")
+
+	casos := []struct {
+		nome      string
+		conteudo  string
+		registros []map[string]string
+		erro      string
+	}{
+		{
+			nome:     "vírgula",
+			conteudo: "username,password,meses\njoao123,senha1,1\n",
+			registros: []map[string]string{
+				{"username": "joao123", "password": "senha1", "meses": "1"},
+			},
+		},
+		{
+			nome:     "ponto e vírgula com BOM e apelidos de coluna",
+			conteudo: "\ufeffUsuario;Senha;Quantidade_Meses;WhatsApp\n maria ; abcd ;3;+55 11 99999-8888\n",
+			registros: []map[string]string{
+				{"username": "maria", "password": "abcd", "meses": "3", "numero_whats": "+55 11 99999-8888"},
+			},
+		},
+		{
+			nome:     "linha com menos colunas",
+			conteudo: "meses,telas\n2\n",
+			registros: []map[string]string{
+				{"meses": "2"},
+			},
+		},
+		{nome: "vazio", conteudo: "", erro: "CSV vazio"},
+		{nome: "sem coluna meses", conteudo: "username\njoao\n", erro: "coluna 'meses'"},
+		{nome: "só cabeçalho", conteudo: "meses\n", erro: "não tem clientes"},
+		{nome: "acima do limite de linhas", conteudo: "meses\n1\n1\n1\n1\n", erro: "mais de 3 linhas"},
+		{nome: "aspas não fechadas", conteudo: "meses,username\n1,\"joao\n", erro: "CSV inválido"},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			registros, err := readImportCSV([]byte(tc.conteudo))
+			if tc.erro != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.erro)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.registros, registros)
+		})
+	}
+}
+
+func TestParseImportRow(t *testing.T) {
+	t.Setenv("BOUQUET", "[1,2]")
+	t.Setenv("PREFIXO_SENHA", "")
+	const maxUser, maxPass = 12, 8
+
+	casos := []struct {
+		nome     string
+		registro map[string]string
+		erros    []string
+		check    func(t *testing.T, linha models.ClientImportRow, req CreateClientRequest)
+	}{
+		{
+			nome:     "completa",
+			registro: map[string]string{"username": "joao123", "password": "abcd1234", "meses": "2", "telas": "3", "numero_whats": "+55 11 99999-8888", "bouquet": "[5, 6]", "franquia_member_id": "7"},
+			check: func(t *testing.T, linha models.ClientImportRow, req CreateClientRequest) {
+				assert.Equal(t, models.LinhaValida, linha.Status)
+				assert.Equal(t, 2, req.Meses)
+				assert.Equal(t, 3, req.Telas)
+				assert.Equal(t, "5511999998888", req.NumeroWhats)
+				assert.Equal(t, "[5,6]", req.Bouquet)
+				if assert.NotNil(t, req.FranquiaMemberID) {
+					assert.Equal(t, 7, *req.FranquiaMemberID)
+				}
+			},
+		},
+		{
+			nome:     "padrões: uma tela e bouquet de BOUQUET",
+			registro: map[string]string{"meses": "1"},
+			check: func(t *testing.T, linha models.ClientImportRow, req CreateClientRequest) {
+				assert.Equal(t, 1, req.Telas)
+				assert.Equal(t, "[1,2]", req.Bouquet)
+				assert.Empty(t, req.Username)
+				assert.Empty(t, req.Password)
+			},
+		},
+		{
+			nome:     "senha gerada quando só o usuário é informado",
+			registro: map[string]string{"username": "joao123", "meses": "1"},
+			check: func(t *testing.T, linha models.ClientImportRow, req CreateClientRequest) {
+				assert.Len(t, req.Password, maxPass)
+			},
+		},
+		{nome: "usuário curto", registro: map[string]string{"username": "abc", "meses": "1"}, erros: []string{"O nome de usuário deve ter entre 4 e 12 caracteres"}},
+		{nome: "usuário com caracteres inválidos", registro: map[string]string{"username": "joão 1", "meses": "1"}, erros: []string{"O nome de usuário só pode ter letras, números, ponto, hífen e sublinhado"}},
+		{nome: "senha igual ao login", registro: map[string]string{"username": "joao123", "password": "joao123", "meses": "1"}, erros: []string{"A senha não pode ser igual ao login."}},
+		{nome: "senha sem usuário", registro: map[string]string{"password": "abcd1234", "meses": "1"}, erros: []string{"Senha informada sem nome de usuário"}},
+		{nome: "meses ausente", registro: map[string]string{}, erros: []string{"meses deve ser um número entre 1 e 121"}},
+		{nome: "telas fora do limite", registro: map[string]string{"meses": "1", "telas": "0"}, erros: []string{"telas deve estar entre 1 e"}},
+		{nome: "franquia inválida", registro: map[string]string{"meses": "1", "franquia_member_id": "x"}, erros: []string{"franquia_member_id inválido"}},
+		{nome: "vários erros na mesma linha", registro: map[string]string{"meses": "0", "numero_whats": "123", "bouquet": "abc"}, erros: []string{"WhatsApp inválido", "meses deve ser", "bouquet inválido"}},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			linha, req := parseImportRow(5, tc.registro, maxUser, maxPass)
+			assert.Equal(t, 5, linha.Linha)
+			if assert.Len(t, linha.Erros, len(tc.erros), "erros: %v", linha.Erros) {
+				for i, esperado := range tc.erros {
+					assert.True(t, strings.HasPrefix(linha.Erros[i], esperado), "erro %q, esperado %q", linha.Erros[i], esperado)
+				}
+			}
+			if tc.check != nil {
+				tc.check(t, linha, req)
+			}
+		})
+	}
+}
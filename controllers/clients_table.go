@@ -5,42 +5,91 @@ import (
 	"apiBackEnd/models"
 	"apiBackEnd/utils"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Tabela de clientes
+//
+// Filtros, ordenação e paginação são feitos no banco: busca, vencimento, teste, franquia e
+// online (sessão em user_activity_now) entram no WHERE, e só a página pedida é lida. Os dados da
+// sessão (canal, IP, tempo online) são lidos de user_activity_now só para os IDs da página.
+//
+// Paginação por página (page/limit, com OFFSET) ou por cursor (keyset): cada resposta traz
+// proximo_cursor enquanto houver mais registros; passe-o em ?cursor= com a mesma ordenação.
+
+// maxClientsTableLimit limita os registros por página
+const maxClientsTableLimit = 500
+
+// clientsTableSorts mapeia ordenar_por para a expressão SQL (aceita os nomes das colunas também)
+var clientsTableSorts = map[string]string{
+	"vencimento": "COALESCE(u.exp_date, 0)",
+	"exp_date":   "COALESCE(u.exp_date, 0)",
+	"criacao":    "COALESCE(u.created_at, 0)",
+	"created_at": "COALESCE(u.created_at, 0)",
+	"username":   "u.username",
+}
+
+// clientsTableCursor é a posição da última linha entregue (valor da ordenação + id)
+type clientsTableCursor struct {
+	Ordem   string `json:"o"`
+	Direcao string `json:"d"`
+	Valor   string `json:"v"`
+	ID      int    `json:"id"`
+}
+
+func encodeClientsTableCursor(cur clientsTableCursor) string {
+	data, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeClientsTableCursor(raw string) (clientsTableCursor, error) {
+	var cur clientsTableCursor
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cur, err
+	}
+	err = json.Unmarshal(data, &cur)
+	return cur, err
+}
+
 // GetClientsTable retorna clientes paginados e filtrados para o DataTable, incluindo status online e expiração
 // @Summary Retorna clientes paginados e filtrados
-// @Description Retorna uma lista de clientes paginada e filtrada para uso em DataTables, associados ao member_id do token. Inclui filtro de status online e expiração.
+// @Description Retorna uma lista de clientes paginada e filtrada para uso em DataTables, associados ao member_id do token. Filtros, ordenação e paginação são feitos no banco. Além de page/limit, aceita paginação por cursor (keyset): use o proximo_cursor da resposta em ?cursor= mantendo a mesma ordenação.
 // @Tags ClientsTable
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
-// @Param page query int false "Número da página (padrão: 1)"
-// @Param limit query int false "Limite de registros por página (padrão: 10)"
+// @Param page query int false "Número da página (padrão: 1; ignorado com cursor)"
+// @Param limit query int false "Limite de registros por página (padrão: 20, máximo: 500)"
+// @Param cursor query string false "Cursor da próxima página (proximo_cursor da resposta anterior)"
 // @Param search query string false "Termo de pesquisa para filtrar por username ou reseller_notes"
 // @Param online query bool false "Filtrar usuários online (true para listar apenas online, false para todos)"
-// @Param expiration_filter query int false "Filtrar clientes por vencimento (7, 15, 30, custom até 90 ou \'0\' para vencidos)"
+// @Param expiration_filter query int false "Filtrar clientes por vencimento (vencendo nos próximos N dias ou -1 para vencidos; 0 = sem filtro)"
+// @Param vencimento_de query int false "Vencimento a partir de (timestamp UNIX)"
+// @Param vencimento_ate query int false "Vencimento até (timestamp UNIX)"
 // @Param franquia_member_id query int false "Filtrar por ID do membro da franquia"
 // @Param is_trial query string false "Filtrar por status de trial (0 para não trial, 1 para trial)"
-// @Success 200 {object} map[string]interface{} "Retorna a lista de clientes paginada e informações de paginação"
+// @Param ordenar_por query string false "Ordenação: vencimento, criacao (padrão) ou username"
+// @Param ordem query string false "asc ou desc (padrão: desc)"
+// @Param revenda_id query int false "ID de uma sub-revenda da árvore (padrão: a própria revenda)"
+// @Success 200 {object} map[string]interface{} "Retorna a lista de clientes paginada e informações de paginação (total_paginas, pagina_atual, total_registros, clientes, proximo_cursor)"
+// @Failure 400 {object} map[string]string "Parâmetros inválidos"
 // @Failure 401 {object} map[string]string "Token inválido ou não fornecido"
 // @Failure 500 {object} map[string]string "Erro interno ao buscar ou processar os dados"
 // @Router /api/clients-table [get]
-// GetClientsTable retorna clientes paginados e filtrados para o DataTable, incluindo status online e expiração
 func GetClientsTable(c *gin.Context) {
 	// 📌 Extrair `member_id` do token
-	tokenString := c.GetHeader("Authorization")
-	if tokenString == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token não fornecido"})
-		return
-	}
-
-	claims, _, err := utils.ValidateToken(tokenString)
+	claims, _, err := utils.RequestClaims(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token inválido"})
 		return
@@ -53,204 +102,289 @@ func GetClientsTable(c *gin.Context) {
 	}
 	memberID := int(memberIDFloat)
 
-	// 📌 Obtém o parâmetro `online` (true/false)
-	onlineFilter, _ := strconv.ParseBool(c.DefaultQuery("online", "false"))
-
-	// 📌 Obtém o parâmetro `expiration_filter` (dias até expiração ou `-1` para vencidos)
-	expirationFilter, _ := strconv.Atoi(c.DefaultQuery("expiration_filter", "0"))
-
-	// 📌 Obtém o parâmetro `franquia_member_id` para filtro
-	franquiaMemberIDFilterStr := c.Query("franquia_member_id")
-	var franquiaMemberIDFilter sql.NullInt64
-	if franquiaMemberIDFilterStr != "" {
-		fmID, err := strconv.ParseInt(franquiaMemberIDFilterStr, 10, 64)
-		if err == nil {
-			franquiaMemberIDFilter.Int64 = fmID
-			franquiaMemberIDFilter.Valid = true
-		} else {
-			log.Printf("⚠️ Aviso: Valor inválido para o filtro franquia_member_id: %s", franquiaMemberIDFilterStr)
-			// Considerar retornar um erro 400 se o valor for inválido e o filtro for crucial
-		}
+	// 📌 Master pode consultar uma sub-revenda via ?revenda_id=
+	memberID, ok := resolveRevendaAlvo(c, claims, memberID)
+	if !ok {
+		return
 	}
 
-	// 📌 Parâmetros de paginação e pesquisa
+	// 📌 Parâmetros de paginação
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	search := c.Query("search")
-
 	if page < 1 {
 		page = 1
 	}
 	if limit < 1 {
 		limit = 20
 	}
+	if limit > maxClientsTableLimit {
+		limit = maxClientsTableLimit
+	}
 
-	offset := (page - 1) * limit
-
-	// 📌 Obtém status online de todos os usuários ANTES da paginação
-	onlineStatuses, err := getAllUsersOnlineStatus(memberID) // TODO: Revisar se este memberID é o correto para buscar status online quando filtrando por franquia_member_id
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar status online"})
+	// 📌 Filtros e ordenação (os mesmos da exportação)
+	filtro, ok := parseClientsTableFilter(c, memberID)
+	if !ok {
 		return
 	}
+	where, args := filtro.Where, filtro.Args
+	ordenarPor, sortExpr, direcao := filtro.OrdenarPor, filtro.SortExpr, filtro.Direcao
 
-	// 📌 Consulta base para buscar todos os usuários do membro
-	query := `SELECT id, username, password, exp_date, enabled, admin_enabled, max_connections, created_at, reseller_notes, is_trial, Aplicativo, franquia_member_id 
-			FROM users WHERE member_id = ? and deleted != '1'`
-	var args []interface{}
-	args = append(args, memberID)
+	// 📌 Total de registros com os filtros (a paginação por cursor não altera o total)
+	var total int
+	if err := config.DB.QueryRow(`SELECT COUNT(*) FROM users u`+where, args...).Scan(&total); err != nil {
+		log.Printf("❌ Erro ao contar clientes da revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar clientes"})
+		return
+	}
 
-	// 📌 Aplica filtro de pesquisa
-	if search != "" {
-		query += ` AND (username LIKE ? OR reseller_notes LIKE ?)`
-		args = append(args, "%"+search+"%", "%"+search+"%")
+	// 📌 Posição: cursor (keyset) ou página (OFFSET)
+	comparador := "<"
+	if direcao == "asc" {
+		comparador = ">"
 	}
-	// 📌 Aplica filtro `is_trial=0` ou `is_trial=1` (caso informado)
-	isTrialFilter := c.Query("is_trial")
-	if isTrialFilter != "" {
-		query += ` AND is_trial = ?`
-		args = append(args, isTrialFilter)
+	pageArgs := append([]interface{}{}, args...)
+	paginacao := ""
+	if raw := c.Query("cursor"); raw != "" {
+		cur, err := decodeClientsTableCursor(raw)
+		if err != nil || cur.Ordem != ordenarPor || cur.Direcao != direcao {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "cursor inválido ou de outra ordenação"})
+			return
+		}
+		var valor interface{} = cur.Valor
+		if ordenarPor != "username" {
+			n, err := strconv.ParseInt(cur.Valor, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"erro": "cursor inválido ou de outra ordenação"})
+				return
+			}
+			valor = n
+		}
+		paginacao = fmt.Sprintf(` AND (%s %s ? OR (%s = ? AND u.id %s ?))`, sortExpr, comparador, sortExpr, comparador)
+		pageArgs = append(pageArgs, valor, valor, cur.ID)
 	}
-
-	// 📌 Aplica filtro de franquia_member_id (se fornecido)
-	if franquiaMemberIDFilter.Valid {
-		query += ` AND franquia_member_id = ?`
-		args = append(args, franquiaMemberIDFilter.Int64)
+	query := `SELECT u.id, u.username, u.password, u.exp_date, u.enabled, u.admin_enabled, u.max_connections, u.created_at, u.reseller_notes, u.is_trial, u.Aplicativo, u.franquia_member_id
+			FROM users u` + where + paginacao +
+		fmt.Sprintf(` ORDER BY %s %s, u.id %s LIMIT ?`, sortExpr, direcao, direcao)
+	pageArgs = append(pageArgs, limit+1) // Uma linha a mais indica se há próxima página
+	if paginacao == "" {
+		query += ` OFFSET ?`
+		pageArgs = append(pageArgs, (page-1)*limit)
 	}
 
-	// 📌 Ordenação antes da paginação
-	query += " ORDER BY created_at DESC"
-
-	// 📌 Executa busca de todos os usuários, sem paginação inicial
-	rows, err := config.DB.Query(query, args...)
+	rows, err := config.DB.Query(query, pageArgs...)
 	if err != nil {
+		log.Printf("❌ Erro ao buscar clientes da revenda %d: %v", memberID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar clientes"})
 		return
 	}
 	defer rows.Close()
 
-	var allClients []models.ClientTableData
-
-	// 🔄 Processa todos os usuários antes de paginar
+	clients := make([]models.ClientTableData, 0, limit)
 	for rows.Next() {
 		var client models.ClientTableData
-		var expDate, createdAt, resellerNotes sql.NullString
 		var aplicativo sql.NullString
-		var franquiaMemberIDScanned sql.NullInt64 // Variável para escanear o franquia_member_id
 
 		if err := rows.Scan(
-			&client.ID, &client.Username, &client.Password, &expDate, &client.Enabled,
-			&client.AdminEnabled, &client.MaxConnections, &createdAt, &resellerNotes, &client.IsTrial,
-			&aplicativo, &franquiaMemberIDScanned, // Adicionado para scan
+			&client.ID, &client.Username, &client.Password, &client.ExpDate, &client.Enabled,
+			&client.AdminEnabled, &client.MaxConnections, &client.CreatedAt, &client.ResellerNotes, &client.IsTrial,
+			&aplicativo, &client.FranquiaMemberID,
 		); err != nil {
 			log.Printf("❌ Erro ao escanear dados do cliente: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao processar os dados"})
 			return
 		}
-		client.ExpDate = expDate
-		client.CreatedAt = createdAt
-		client.ResellerNotes = resellerNotes
 		client.Aplicativo = aplicativo.String
-		client.FranquiaMemberID = franquiaMemberIDScanned // Atribui o valor escaneado
-
-		// 📌 Associa status online se existir
-		if status, exists := onlineStatuses[client.ID]; exists {
-			client.Online = map[string]interface{}{
-				"username":            status.Username,
-				"stream_display_name": status.StreamDisplayName,
-				"date_start":          status.DateStart,
-				"tempo_online":        status.TempoOnline,
-				"user_agent":          status.UserAgent,
-				"user_ip":             status.UserIP,
-				"container":           status.Container,
-				"geoip_country_code":  status.GeoIPCountryCode,
-				"isp":                 status.ISP,
-				"city":                status.City,
-				"divergence":          status.Divergence,
-				"stream_icon":         status.StreamIcon,
+		client.Online = map[string]interface{}{} // 🔹 Retorna `{}` se não estiver online
+		clients = append(clients, client)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("❌ Erro ao ler clientes da revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao processar os dados"})
+		return
+	}
+
+	proximoCursor := ""
+	if len(clients) > limit {
+		clients = clients[:limit]
+		ultimo := clients[limit-1]
+		valor := ultimo.Username
+		switch sortExpr {
+		case clientsTableSorts["vencimento"]:
+			valor = nullIntString(ultimo.ExpDate)
+		case clientsTableSorts["criacao"]:
+			valor = nullIntString(ultimo.CreatedAt)
+		}
+		proximoCursor = encodeClientsTableCursor(clientsTableCursor{Ordem: ordenarPor, Direcao: direcao, Valor: valor, ID: ultimo.ID})
+	}
+
+	// 📌 Dados da sessão apenas para os clientes da página
+	if len(clients) > 0 {
+		ids := make([]int, len(clients))
+		for i := range clients {
+			ids[i] = clients[i].ID
+		}
+		onlineStatuses, err := getOnlineStatusByIDs(ids)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar status online"})
+			return
+		}
+		for i := range clients {
+			if status, exists := onlineStatuses[clients[i].ID]; exists {
+				clients[i].Online = map[string]interface{}{
+					"username":            status.Username,
+					"stream_display_name": status.StreamDisplayName,
+					"date_start":          status.DateStart,
+					"tempo_online":        status.TempoOnline,
+					"user_agent":          status.UserAgent,
+					"user_ip":             status.UserIP,
+					"container":           status.Container,
+					"geoip_country_code":  status.GeoIPCountryCode,
+					"isp":                 status.ISP,
+					"city":                status.City,
+					"divergence":          status.Divergence,
+					"stream_icon":         status.StreamIcon,
+				}
 			}
-		} else {
-			client.Online = map[string]interface{}{} // 🔹 Retorna `{}` se não estiver online
 		}
+	}
+
+	// 📌 Retorno formatado
+	c.JSON(http.StatusOK, gin.H{
+		"total_paginas":   (total + limit - 1) / limit,
+		"pagina_atual":    page,
+		"total_registros": total,
+		"clientes":        clients,
+		"proximo_cursor":  proximoCursor,
+	})
+}
+
+// clientsTableFilter é o WHERE (com argumentos) e a ordenação pedidos na query string
+type clientsTableFilter struct {
+	Where      string
+	Args       []interface{}
+	OrdenarPor string
+	SortExpr   string
+	Direcao    string
+}
 
-		allClients = append(allClients, client)
+// parseClientsTableFilter lê busca, vencimento, teste, franquia, online e ordenação da tabela de
+// clientes da revenda. Em parâmetro inválido responde 400 e retorna false.
+func parseClientsTableFilter(c *gin.Context, memberID int) (*clientsTableFilter, bool) {
+	// 📌 Ordenação (id desempata e garante a paginação por cursor)
+	f := &clientsTableFilter{}
+	f.OrdenarPor = strings.ToLower(c.DefaultQuery("ordenar_por", "criacao"))
+	var ok bool
+	if f.SortExpr, ok = clientsTableSorts[f.OrdenarPor]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "ordenar_por deve ser vencimento, criacao ou username"})
+		return nil, false
+	}
+	f.Direcao = strings.ToLower(c.DefaultQuery("ordem", "desc"))
+	if f.Direcao != "asc" && f.Direcao != "desc" {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "ordem deve ser asc ou desc"})
+		return nil, false
 	}
 
-	// 📌 Filtro `expiration_filter`
-	if expirationFilter != 0 { // 🔹 Só filtra se `expiration_filter` for diferente de 0
-		filteredClients := make([]models.ClientTableData, 0, len(allClients))
-		currentTime := time.Now().Unix()
-		expirationDays := int64(expirationFilter) * 86400 // 🔹 Converte dias para segundos
+	// 📌 Filtros no WHERE
+	f.Where = ` WHERE u.member_id = ? AND u.deleted != '1'`
+	f.Args = []interface{}{memberID}
 
-		for _, client := range allClients {
-			expDateInt, err := strconv.ParseInt(client.ExpDate.String, 10, 64)
-			if err != nil {
-				expDateInt = 0 // 🔹 Se falhar, assume vencido
-			}
+	if search := c.Query("search"); search != "" {
+		f.Where += ` AND (u.username LIKE ? OR u.reseller_notes LIKE ?)`
+		f.Args = append(f.Args, "%"+search+"%", "%"+search+"%")
+	}
 
-			// 📌 Se `expiration_filter = -1` ou `0`, traz apenas vencidos (`exp_date < agora`)
-			if (expirationFilter == -1 || expirationFilter == 0) && expDateInt < currentTime {
-				filteredClients = append(filteredClients, client)
-			} else if expirationFilter > 0 && expDateInt >= currentTime && expDateInt <= (currentTime+expirationDays) {
-				filteredClients = append(filteredClients, client) // 🔹 Próximos X dias
-			}
+	if isTrialFilter := c.Query("is_trial"); isTrialFilter != "" {
+		if isTrialFilter != "0" && isTrialFilter != "1" {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "is_trial deve ser 0 ou 1"})
+			return nil, false
 		}
-		allClients = filteredClients // 🔹 Substitui a lista original
+		f.Where += ` AND u.is_trial = ?`
+		f.Args = append(f.Args, isTrialFilter)
 	}
 
-	// 📌 Filtro `online=true`
-	if onlineFilter {
-		filteredClients := make([]models.ClientTableData, 0, len(allClients))
-		for _, client := range allClients {
-			if _, exists := onlineStatuses[client.ID]; exists {
-				filteredClients = append(filteredClients, client)
-			}
+	if franquiaStr := c.Query("franquia_member_id"); franquiaStr != "" {
+		franquiaID, err := strconv.ParseInt(franquiaStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "franquia_member_id inválido"})
+			return nil, false
 		}
-		allClients = filteredClients
+		f.Where += ` AND u.franquia_member_id = ?`
+		f.Args = append(f.Args, franquiaID)
 	}
 
-	// 📌 Atualiza total de registros após filtros
-	total := len(allClients)
-	totalPages := (total + limit - 1) / limit
-
-	// 📌 Paginação final
-	start := offset
-	end := offset + limit
-	if start > total {
-		start = total
+	// 📌 `expiration_filter`: -1 = vencidos (sem vencimento conta como vencido), N > 0 = vencendo nos próximos N dias
+	now := time.Now().Unix()
+	expirationFilter, _ := strconv.Atoi(c.DefaultQuery("expiration_filter", "0"))
+	if expirationFilter < 0 {
+		f.Where += ` AND (u.exp_date IS NULL OR u.exp_date < ?)`
+		f.Args = append(f.Args, now)
+	} else if expirationFilter > 0 {
+		f.Where += ` AND u.exp_date BETWEEN ? AND ?`
+		f.Args = append(f.Args, now, now+int64(expirationFilter)*86400)
+	}
+	for param, cond := range map[string]string{"vencimento_de": ` AND u.exp_date >= ?`, "vencimento_ate": ` AND u.exp_date <= ?`} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		ts, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": param + " deve ser um timestamp UNIX"})
+			return nil, false
+		}
+		f.Where += cond
+		f.Args = append(f.Args, ts)
 	}
-	if end > total {
-		end = total
+
+	// 📌 `online=true`: apenas clientes com sessão ativa
+	if onlineFilter, _ := strconv.ParseBool(c.DefaultQuery("online", "false")); onlineFilter {
+		f.Where += ` AND EXISTS (SELECT 1 FROM streamcreed_db.user_activity_now a WHERE a.user_id = u.id)`
 	}
+	return f, true
+}
 
-	// 📌 Retorno formatado
-	c.JSON(http.StatusOK, gin.H{
-		"total_paginas":   totalPages,
-		"pagina_atual":    page,
-		"total_registros": total,
-		"clientes":        allClients[start:end],
-	})
+// nullIntString retorna o valor numérico da coluna como string ("0" se nulo, como no COALESCE da ordenação)
+func nullIntString(ns sql.NullString) string {
+	if !ns.Valid || ns.String == "" {
+		return "0"
+	}
+	return ns.String
 }
 
-// getAllUsersOnlineStatus busca o status online de todos os clientes do membro
-func getAllUsersOnlineStatus(memberID int) (map[int]models.OnlineStatusData, error) {
-	query := "CALL getUserOnlineStatus(0, ?);"
+// getOnlineStatusByIDs busca as sessões ativas (user_activity_now) apenas dos clientes informados,
+// com as mesmas colunas da procedure getUserOnlineStatus. Com mais de uma sessão, vale a mais recente.
+func getOnlineStatusByIDs(ids []int) (map[int]models.OnlineStatusData, error) {
+	onlineUsers := make(map[int]models.OnlineStatusData)
+	if len(ids) == 0 {
+		return onlineUsers, nil
+	}
 
-	// 📡 Log da query sendo executada
-	//log.Printf("📡 DEBUG: Executando Query: %s | Params: (0, %d)", query, memberID)
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := `SELECT a.user_id, u.username, COALESCE(s.stream_display_name, ''),
+			DATE_FORMAT(FROM_UNIXTIME(a.date_start), '%d/%m/%Y %H:%i:%s'),
+			SEC_TO_TIME(GREATEST(UNIX_TIMESTAMP() - a.date_start, 0)),
+			COALESCE(a.user_agent, ''), COALESCE(a.user_ip, ''), COALESCE(a.container, ''),
+			COALESCE(a.geoip_country_code, ''), COALESCE(a.isp, ''), COALESCE(a.city, ''),
+			COALESCE(a.divergence, 0), COALESCE(s.stream_icon, '')
+		FROM streamcreed_db.user_activity_now a
+		INNER JOIN streamcreed_db.users u ON u.id = a.user_id
+		LEFT JOIN streamcreed_db.streams s ON s.id = a.stream_id
+		WHERE a.user_id IN (` + placeholders + `)
+		ORDER BY a.date_start`
 
-	rows, err := config.DB.Query(query, memberID)
+	rows, err := config.DB.Query(query, args...)
 	if err != nil {
-		log.Printf("❌ ERRO ao executar a procedure: %v", err)
+		log.Printf("❌ ERRO ao buscar sessões ativas da página: %v", err)
 		return nil, err
 	}
 	defer rows.Close()
 
-	onlineUsers := make(map[int]models.OnlineStatusData)
-
 	for rows.Next() {
 		var onlineData models.OnlineStatusData
-
 		err := rows.Scan(
 			&onlineData.Id,
 			&onlineData.Username,
@@ -266,20 +400,12 @@ func getAllUsersOnlineStatus(memberID int) (map[int]models.OnlineStatusData, err
 			&onlineData.Divergence,
 			&onlineData.StreamIcon,
 		)
-
 		if err != nil {
 			log.Printf("❌ ERRO ao escanear os dados retornados: %v", err)
 			return nil, err
 		}
-
-		// 📡 Log de cada usuário online retornado
-		//log.Printf("✅ Usuário Online Carregado: ID %d | Canal: %s | IP: %s", onlineData.Id, onlineData.StreamDisplayName, onlineData.UserIP)
-
+		// Ordenado por date_start: a sessão mais recente sobrescreve as anteriores
 		onlineUsers[onlineData.Id] = onlineData
 	}
-
-	// 📡 Log final do total de usuários online carregados
-	//log.Printf("🔍 Total de usuários online carregados: %d", len(onlineUsers))
-
-	return onlineUsers, nil
+	return onlineUsers, rows.Err()
 }
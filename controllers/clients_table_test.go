@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientsTableCursor(t *testing.T) {
+	casos := []clientsTableCursor{
+		{Ordem: "criacao", Direcao: "desc", Valor: "1719700000", ID: 42},
+		{Ordem: "vencimento", Direcao: "asc", Valor: "0", ID: 1},
+		{Ordem: "username", Direcao: "asc", Valor: "joão/+=?&", ID: 7},
+		{Ordem: "username", Direcao: "desc", Valor: "", ID: 0},
+	}
+	for _, cur := range casos {
+		raw := encodeClientsTableCursor(cur)
+		assert.NotContains(t, raw, "=", "o cursor vai na query string sem padding")
+		got, err := decodeClientsTableCursor(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, cur, got)
+	}
+}
+
+func TestDecodeClientsTableCursorInvalido(t *testing.T) {
+	for _, raw := range []string{"%%%", "bm90LWpzb24", "eyJvIjoxfQ"} { // não base64, não JSON, tipo errado
+		_, err := decodeClientsTableCursor(raw)
+		assert.Error(t, err, raw)
+	}
+}
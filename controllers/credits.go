@@ -2,8 +2,13 @@ package controllers
 
 import (
 	"apiBackEnd/config"
+	"apiBackEnd/models"
 	"apiBackEnd/utils"
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,21 +19,15 @@ import (
 // @Description Retorna o total de créditos do usuário autenticado e o tempo restante do token em segundos.
 // @Tags Créditos
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Produce json
 // @Success 200 {object} map[string]interface{} "Dados de créditos e tempo restante"
 // @Failure 401 {object} map[string]string "Token inválido ou expirado"
 // @Failure 500 {object} map[string]string "Erro ao buscar créditos"
 // @Router /api/credits [get]
 func GetCredits(c *gin.Context) {
-	// 📌 Recuperar token do header
-	tokenString := c.GetHeader("Authorization")
-	if tokenString == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token não fornecido"})
-		return
-	}
-
 	// 📌 Validar token e extrair claims + tempo restante
-	claims, timeRemaining, err := utils.ValidateToken(tokenString)
+	claims, timeRemaining, err := utils.RequestClaims(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token inválido ou expirado"})
 		return
@@ -57,3 +56,126 @@ func GetCredits(c *gin.Context) {
 		"token_expira_em": timeRemaining, // Tempo do token em segundos
 	})
 }
+
+// GetCreditsHistory retorna o extrato de créditos da revenda, com filtros e paginação.
+//
+// @Summary Extrato de créditos
+// @Description Lista as movimentações de créditos da revenda (renovações, telas adicionais, reversões e transferências), com saldo antes/depois de cada operação, para conferência do saldo.
+// @Tags Créditos
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param page query int false "Número da página (padrão: 1)"
+// @Param limit query int false "Registros por página (padrão: 20, máximo: 100)"
+// @Param operacao query string false "Filtrar por operação (renovacao, reversao_renovacao, tela_adicional, transferencia_enviada, transferencia_recebida)"
+// @Param user_id query int false "Filtrar por cliente relacionado"
+// @Param data_inicio query string false "Data inicial (AAAA-MM-DD)"
+// @Param data_fim query string false "Data final, inclusiva (AAAA-MM-DD)"
+// @Param revenda_id query int false "ID de uma sub-revenda da árvore (padrão: a própria revenda)"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"total_paginas\": 1, \"pagina_atual\": 1, \"total_registros\": 1, \"movimentacoes\": [{\"id\": 10, \"operacao\": \"renovacao\", \"user_id\": 123, \"valor\": -2, \"saldo_antes\": 50, \"saldo_depois\": 48, \"actor_id\": 7, \"descricao\": \"Renovação de 1 mês(es) x 2 tela(s)\", \"criado_em\": 1716200000}]}"
+// @Failure 400 {object} map[string]string "Parâmetros inválidos"
+// @Failure 401 {object} map[string]string "Token inválido ou expirado"
+// @Failure 403 {object} map[string]string "Revenda fora da árvore"
+// @Failure 500 {object} map[string]string "Erro ao buscar extrato"
+// @Router /api/credits/history [get]
+func GetCreditsHistory(c *gin.Context) {
+	claims, _, err := utils.RequestClaims(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token inválido ou expirado"})
+		return
+	}
+	memberIDFloat, exists := claims["member_id"].(float64)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"erro": "MemberID não encontrado no token"})
+		return
+	}
+	memberID, ok := resolveRevendaAlvo(c, claims, int(memberIDFloat))
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	filter := models.CreditLedgerFilter{
+		MemberID: memberID,
+		Operacao: c.Query("operacao"),
+		Limit:    limit,
+		Offset:   (page - 1) * limit,
+	}
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		filter.UserID, err = strconv.Atoi(userIDStr)
+		if err != nil || filter.UserID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "user_id inválido"})
+			return
+		}
+	}
+	if dataInicio := c.Query("data_inicio"); dataInicio != "" {
+		t, err := time.ParseInLocation("2006-01-02", dataInicio, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "data_inicio inválida. Use o formato AAAA-MM-DD"})
+			return
+		}
+		filter.DataInicio = t.Unix()
+	}
+	if dataFim := c.Query("data_fim"); dataFim != "" {
+		t, err := time.ParseInLocation("2006-01-02", dataFim, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "data_fim inválida. Use o formato AAAA-MM-DD"})
+			return
+		}
+		filter.DataFim = t.AddDate(0, 0, 1).Unix() - 1 // Inclui o dia inteiro
+	}
+
+	movimentacoes, total, err := models.ListCreditLedger(filter)
+	if err != nil {
+		log.Printf("❌ Erro ao buscar extrato de créditos da revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar extrato de créditos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total_paginas":   (total + limit - 1) / limit,
+		"pagina_atual":    page,
+		"total_registros": total,
+		"movimentacoes":   movimentacoes,
+	})
+}
+
+// respondCreditosInsuficientes responde 402 no formato padrão de saldo insuficiente, usado por
+// todos os endpoints que cobram créditos. Retorna false se err não for de saldo insuficiente.
+func respondCreditosInsuficientes(c *gin.Context, err error) bool {
+	body, ok := creditosInsuficientesBody(err)
+	if !ok {
+		return false
+	}
+	c.JSON(http.StatusPaymentRequired, body)
+	return true
+}
+
+// creditosInsuficientesBody monta o corpo padrão de saldo insuficiente (também usado nos
+// resultados por cliente das operações em lote)
+func creditosInsuficientesBody(err error) (gin.H, bool) {
+	if !errors.Is(err, models.ErrCreditosInsuficientes) {
+		return nil, false
+	}
+	body := gin.H{
+		"erro":   "Créditos insuficientes",
+		"codigo": "creditos_insuficientes",
+	}
+	var detalhe *models.InsufficientCreditsError
+	if errors.As(err, &detalhe) {
+		body["creditos_disponiveis"] = detalhe.Disponivel
+		body["creditos_necessarios"] = detalhe.Necessario
+	}
+	return body, true
+}
@@ -29,6 +29,7 @@ type DashboardResponse struct {
 // @Tags Dashboard
 // @Security BearerAuth
 // @Produce json
+// @Param revenda_id query int false "ID de uma sub-revenda da árvore (padrão: a própria revenda)"
 // @Success 200 {object} DashboardResponse "Dados do dashboard"
 // @example response.200.success
 //
@@ -49,14 +50,8 @@ type DashboardResponse struct {
 // @Failure 500 {object} map[string]string "Erro interno"
 // @Router /api/dashboard [get]
 func DashboardHandler(c *gin.Context) {
-	// 📌 Recuperar o token e validar
-	tokenString := c.GetHeader("Authorization")
-	if tokenString == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token não fornecido"})
-		return
-	}
-
-	claims, _, err := utils.ValidateToken(tokenString)
+	// 📌 Recuperar a identidade autenticada
+	claims, _, err := utils.RequestClaims(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token inválido"})
 		return
@@ -70,6 +65,12 @@ func DashboardHandler(c *gin.Context) {
 	}
 	memberID := int(memberIDFloat)
 
+	// 📌 Master pode consultar uma sub-revenda via ?revenda_id=
+	memberID, ok := resolveRevendaAlvo(c, claims, memberID)
+	if !ok {
+		return
+	}
+
 	// 📌 Executar as procedures e obter os counts
 	counts, err := models.ObterDadosDashboard(memberID) // ✅ Corrigido
 	if err != nil {
@@ -32,6 +32,7 @@ type UserError struct {
 // @Description Retorna os erros registrados na conta de um usuário, incluindo IP, dispositivo e motivo do erro.
 // @Tags Erros
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Accept  json
 // @Produce  json
 // @Param id_usuario path int true "ID do usuário"
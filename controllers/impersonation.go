@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"apiBackEnd/models"
+	"apiBackEnd/utils"
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonationResponse é retornado ao abrir uma sessão de personificação
+type ImpersonationResponse struct {
+	Token          string `json:"token"`
+	ExpiresIn      int64  `json:"expires_in"` // Segundos até a sessão expirar (não há refresh token)
+	SessionID      string `json:"session_id"`
+	MemberID       int    `json:"member_id"`
+	Username       string `json:"username"`
+	MemberGroupID  int    `json:"member_group_id"`
+	SomenteLeitura bool   `json:"somente_leitura"`
+}
+
+// ImpersonateResellerHandler godoc
+// @Summary Personificar Revenda
+// @Description Emite um token de acesso em nome da revenda para o suporte ver exatamente o que ela vê (ex.: /api/clients-table, /api/dashboard). O token carrega também o operador real (impersonated_by), não tem refresh, expira em IMPERSONACAO_MINUTOS e só permite leitura. Todo acesso feito com ele é auditado. Restrito a administradores.
+// @Tags Sessões
+// @Security BearerAuth
+// @Produce json
+// @Param member_id path int true "ID da revenda"
+// @Success 201 {object} ImpersonationResponse "Sessão de personificação aberta"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 403 {object} map[string]string "Sem permissão ou revenda administradora"
+// @Failure 404 {object} map[string]string "Revenda não encontrada"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/admin/resellers/{member_id}/impersonate [post]
+func ImpersonateResellerHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.Atoi(c.Param("member_id"))
+	if err != nil || memberID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "ID da revenda inválido"})
+		return
+	}
+	if memberID == tokenInfo.MemberID {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Não é possível personificar a própria conta"})
+		return
+	}
+
+	target, err := models.GetUserByID(memberID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"erro": "Revenda não encontrada"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar revenda"})
+		return
+	}
+	// Personificar outro admin daria acesso a permissões administrativas em nome de terceiros
+	if utils.RoleFromGroup(target.MemberGroupID) == utils.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"erro": "Não é permitido personificar um administrador"})
+		return
+	}
+
+	tokens, err := utils.GenerateImpersonationToken(target, tokenInfo.MemberID, tokenInfo.Username, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		log.Printf("❌ Erro ao abrir personificação da revenda %d pelo admin %d: %v", memberID, tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar token de personificação"})
+		return
+	}
+	log.Printf("🕵️ Admin %d (%s) personificando a revenda %d (%s), sessão %s", tokenInfo.MemberID, tokenInfo.Username, memberID, target.Username, tokens.SessionID)
+
+	saveAuthEvent("impersonation_start", memberID, tokenInfo.MemberID, gin.H{
+		"admin_username": tokenInfo.Username,
+		"session_id":     tokens.SessionID,
+		"ip":             c.ClientIP(),
+		"expires_in":     tokens.ExpiresIn,
+	})
+
+	c.JSON(http.StatusCreated, ImpersonationResponse{
+		Token:          tokens.AccessToken,
+		ExpiresIn:      tokens.ExpiresIn,
+		SessionID:      tokens.SessionID,
+		MemberID:       target.MemberID,
+		Username:       target.Username,
+		MemberGroupID:  target.MemberGroupID,
+		SomenteLeitura: true,
+	})
+}
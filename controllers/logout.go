@@ -1,19 +1,17 @@
 package controllers
 
 import (
-	"apiBackEnd/config"
 	"apiBackEnd/utils"
 	"context"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Logout encerra a sessão do usuário, invalidando o token no Redis.
+// Logout encerra a sessão atual do usuário, invalidando-a no Redis.
 //
 // @Summary Logout do Usuário
-// @Description Remove o token ativo do usuário, invalidando sua sessão.
+// @Description Encerra apenas a sessão (dispositivo) do token informado; as demais sessões da revenda continuam ativas.
 // @Tags Logout
 // @Security BearerAuth
 // @Accept  json
@@ -45,10 +43,10 @@ func Logout(c *gin.Context) {
 		return
 	}
 
-	redisKey := "token:" + strconv.Itoa(int(memberID))
+	sessionID, _ := claims["sid"].(string)
 
-	// 📌 **Remover o token do Redis**
-	err = config.RedisClient.Del(ctx, redisKey).Err()
+	// 📌 **Remover somente a sessão atual do Redis**
+	err = utils.RevokeSession(ctx, int(memberID), sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao invalidar token"})
 		return
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"apiBackEnd/models"
+	"apiBackEnd/utils"
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChangePasswordHandler godoc
+// @Summary Alterar Própria Senha
+// @Description Troca a senha da revenda autenticada. Exige a senha atual. As demais sessões da revenda são encerradas (a sessão usada na requisição continua válida) e todas as chaves de API são revogadas.
+// @Tags Conta
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.ChangePasswordPayload true "Senha atual e nova senha"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"message\": \"Senha alterada com sucesso\", \"sessoes_encerradas\": 2, \"chaves_api_revogadas\": 1}"
+// @Failure 400 {object} map[string]string "Dados inválidos"
+// @Failure 401 {object} map[string]string "Token inválido ou senha atual incorreta"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/me/password [put]
+func ChangePasswordHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangePasswordPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "details": err.Error()})
+		return
+	}
+	if req.NovaSenha == req.SenhaAtual {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "A nova senha deve ser diferente da atual"})
+		return
+	}
+
+	user, err := models.GetUserByID(tokenInfo.MemberID)
+	if err != nil {
+		log.Printf("❌ Erro ao buscar revenda %d para troca de senha: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar dados da revenda"})
+		return
+	}
+
+	valid, err := utils.VerifyPassword(req.SenhaAtual, user.PasswordHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao processar senha"})
+		return
+	}
+	if !valid {
+		saveAuthEvent("password_change_failed", tokenInfo.MemberID, tokenInfo.MemberID, gin.H{"ip": c.ClientIP()})
+		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Senha atual incorreta"})
+		return
+	}
+
+	revoked, keysRevoked, ok := applyNewPassword(c, tokenInfo.MemberID, req.NovaSenha, tokenInfo.SessionID)
+	if !ok {
+		return
+	}
+
+	saveAuthEvent("password_change", tokenInfo.MemberID, tokenInfo.MemberID, gin.H{
+		"ip":                   c.ClientIP(),
+		"sessoes_encerradas":   revoked,
+		"chaves_api_revogadas": keysRevoked,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":              "Senha alterada com sucesso",
+		"sessoes_encerradas":   revoked,
+		"chaves_api_revogadas": keysRevoked,
+	})
+}
+
+// IssuePasswordResetHandler godoc
+// @Summary Emitir Token de Redefinição de Senha
+// @Description Gera um token de uso único para a revenda definir uma nova senha em /password-reset. Emitir um novo token invalida o anterior. Restrito a administradores e não pode ser usado contra outro administrador.
+// @Tags Conta
+// @Security BearerAuth
+// @Produce json
+// @Param member_id path int true "ID da revenda"
+// @Success 201 {object} map[string]interface{} "Exemplo: {\"token\": \"Vx3k...\", \"expires_in\": 1800}"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 403 {object} map[string]string "Sem permissão ou revenda alvo é administrador"
+// @Failure 404 {object} map[string]string "Revenda não encontrada"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/admin/resellers/{member_id}/password-reset [post]
+func IssuePasswordResetHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.Atoi(c.Param("member_id"))
+	if err != nil || memberID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "ID da revenda inválido"})
+		return
+	}
+
+	user, err := models.GetUserByID(memberID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"erro": "Revenda não encontrada"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar revenda"})
+		return
+	}
+
+	// Redefinir a senha de outro admin daria acesso às permissões administrativas dele
+	if utils.RoleFromGroup(user.MemberGroupID) == utils.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"erro": "Não é permitido redefinir a senha de um administrador"})
+		return
+	}
+
+	token, err := utils.CreatePasswordResetToken(c.Request.Context(), memberID)
+	if err != nil {
+		log.Printf("❌ Erro ao emitir token de redefinição para a revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao emitir token de redefinição"})
+		return
+	}
+
+	saveAuthEvent("password_reset_issued", memberID, tokenInfo.MemberID, gin.H{"username": user.Username})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Token de redefinição emitido. Ele só pode ser usado uma vez.",
+		"member_id":  memberID,
+		"username":   user.Username,
+		"token":      token,
+		"expires_in": int64(utils.GetPasswordResetExpiration().Seconds()),
+	})
+}
+
+// ResetPasswordHandler godoc
+// @Summary Redefinir Senha com Token
+// @Description Troca um token de redefinição emitido pelo admin por uma nova senha. O token é consumido no primeiro uso, todas as sessões da revenda são encerradas e todas as chaves de API são revogadas.
+// @Tags Conta
+// @Accept json
+// @Produce json
+// @Param body body models.PasswordResetPayload true "Token de redefinição e nova senha"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"message\": \"Senha redefinida com sucesso\"}"
+// @Failure 400 {object} map[string]string "Dados inválidos"
+// @Failure 401 {object} map[string]string "Token de redefinição inválido ou expirado"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /password-reset [post]
+func ResetPasswordHandler(c *gin.Context) {
+	var req models.PasswordResetPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "details": err.Error()})
+		return
+	}
+
+	memberID, err := utils.ConsumePasswordResetToken(c.Request.Context(), req.Token)
+	if err != nil {
+		if err == utils.ErrPasswordResetInvalid {
+			c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token de redefinição inválido ou expirado"})
+			return
+		}
+		log.Printf("❌ Erro ao validar token de redefinição: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao validar token de redefinição"})
+		return
+	}
+
+	revoked, keysRevoked, ok := applyNewPassword(c, memberID, req.NovaSenha, "")
+	if !ok {
+		return
+	}
+
+	if user, err := models.GetUserByID(memberID); err == nil {
+		utils.ResetLoginFailures(c.Request.Context(), user.Username)
+	}
+
+	saveAuthEvent("password_reset", memberID, memberID, gin.H{
+		"ip":                   c.ClientIP(),
+		"sessoes_encerradas":   revoked,
+		"chaves_api_revogadas": keysRevoked,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":              "Senha redefinida com sucesso",
+		"sessoes_encerradas":   revoked,
+		"chaves_api_revogadas": keysRevoked,
+	})
+}
+
+// applyNewPassword grava o hash da nova senha, encerra as sessões da revenda (exceto keepSessionID)
+// e revoga todas as chaves de API. Em caso de falha já responde e retorna ok=false.
+func applyNewPassword(c *gin.Context, memberID int, newPassword string, keepSessionID string) (int, int, bool) {
+	// Mesmo crypt SHA-512 de salt fixo do painel, para que o login no painel continue funcionando
+	passwordHash, err := utils.CryptPassword(newPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao processar senha"})
+		return 0, 0, false
+	}
+
+	if err := models.UpdateResellerPassword(memberID, passwordHash); err != nil {
+		log.Printf("❌ Erro ao gravar nova senha da revenda %d: %v", memberID, err)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"erro": "Revenda não encontrada"})
+			return 0, 0, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao alterar senha"})
+		return 0, 0, false
+	}
+
+	revoked, err := utils.RevokeAllSessions(c.Request.Context(), memberID, keepSessionID)
+	if err != nil {
+		// A senha já foi trocada; as sessões antigas expiram sozinhas, mas registramos a falha
+		log.Printf("⚠️ Senha da revenda %d alterada, mas houve erro ao encerrar sessões: %v", memberID, err)
+	}
+
+	// Chaves de API não expiram sozinhas: quem conhecia a senha antiga pode ter criado chaves
+	keysRevoked, err := models.RevokeAllAPIKeys(memberID)
+	if err != nil {
+		log.Printf("⚠️ Senha da revenda %d alterada, mas houve erro ao revogar chaves de API: %v", memberID, err)
+	}
+	return revoked, keysRevoked, true
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"apiBackEnd/utils"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuotePriceHandler godoc
+// @Summary Orçamento de Renovação ou Tela Adicional
+// @Description Calcula o custo exato de uma renovação ou de uma tela adicional pelas regras de preço da revenda, com o detalhamento de cada regra aplicada. Nada é cobrado. Com user_id, telas, dias restantes e conversão de teste são lidos do cliente. Renovações podem ser orçadas por meses, por dias ou até uma data alvo (proporcional, com o arredondamento configurado); com user_id, a data alvo conta a partir do vencimento do cliente.
+// @Tags Preços
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param body body models.PriceQuoteRequest true "Operação a orçar"
+// @Success 200 {object} models.PriceQuote "Orçamento calculado"
+// @Failure 400 {object} map[string]string "Dados inválidos"
+// @Failure 401 {object} map[string]string "Token inválido ou cliente de outra revenda"
+// @Failure 404 {object} map[string]string "Cliente não encontrado"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/pricing/quote [post]
+func QuotePriceHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	var req models.PriceQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "details": err.Error()})
+		return
+	}
+
+	input := utils.PricingInput{
+		MemberID:       tokenInfo.MemberID,
+		Produto:        req.Produto,
+		Meses:          req.Meses,
+		Telas:          req.Telas,
+		DiasRestantes:  req.DiasRestantes,
+		ConversaoTeste: req.ConversaoTeste,
+	}
+
+	var expDate sql.NullInt64
+	if req.UserID > 0 {
+		var userMemberID, maxConnections, isTrial int
+		err := config.DB.QueryRow("SELECT member_id, max_connections, exp_date, is_trial FROM streamcreed_db.users WHERE id = ?", req.UserID).
+			Scan(&userMemberID, &maxConnections, &expDate, &isTrial)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"erro": "Cliente não encontrado"})
+				return
+			}
+			log.Printf("❌ Erro ao buscar cliente %d para orçamento: %v", req.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar cliente"})
+			return
+		}
+		permitido, err := utils.PodeGerenciarRevenda(tokenInfo.MemberID, userMemberID, tokenInfo.Role)
+		if err != nil {
+			log.Printf("❌ Erro ao verificar hierarquia de revendas: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar permissões"})
+			return
+		}
+		if !permitido {
+			c.JSON(http.StatusUnauthorized, gin.H{"erro": "Cliente não pertence à sua revenda"})
+			return
+		}
+
+		input.ConversaoTeste = isTrial == 1
+		input.Telas = maxConnections
+		if req.Produto == models.ProdutoTelaAdicional {
+			input.Telas = maxConnections + 1
+			input.DiasRestantes = int((expDate.Int64 - time.Now().Unix()) / 86400)
+		}
+	}
+
+	if req.Produto == models.ProdutoRenovacao && (req.Dias != 0 || req.DataAlvo != "") {
+		periodo, err := parseRenewalPeriod(req.Meses, req.Dias, req.DataAlvo)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+			return
+		}
+		base := time.Now()
+		if expDate.Valid && expDate.Int64 >= base.Unix() {
+			base = time.Unix(expDate.Int64, 0)
+		}
+		_, dias, err := periodo.resolve(base)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+			return
+		}
+		input.Dias = dias
+	}
+
+	if req.Produto == models.ProdutoRenovacao && ((input.Meses < 1 && input.Dias < 1) || input.Telas < 1) {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Informe meses (ou dias/data_alvo) e telas (ou user_id) maiores que zero para orçar a renovação"})
+		return
+	}
+
+	quote, err := utils.QuotePrice(input)
+	if err != nil {
+		log.Printf("❌ Erro ao calcular orçamento para a revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao calcular orçamento"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
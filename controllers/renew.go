@@ -8,43 +8,123 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"os"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
-// Estrutura para receber a requisição de renovação
+// Estrutura para receber a requisição de renovação.
+// Informe apenas um período: meses (30 dias cada), dias ou data alvo.
 type RenewRequest struct {
-	IDCliente              int `json:"id_cliente"`
-	QuantidadeRenovacaoMes int `json:"quantidade_renovacao_em_meses"`
+	IDCliente              int    `json:"id_cliente"`
+	QuantidadeRenovacaoMes int    `json:"quantidade_renovacao_em_meses"`
+	QuantidadeDias         int    `json:"quantidade_dias"` // Renovação proporcional por dias
+	DataAlvo               string `json:"data_alvo"`       // Novo vencimento: AAAA-MM-DD (na hora padrão) ou AAAA-MM-DD HH:MM
+}
+
+// renewalPeriod é o período pedido na renovação (apenas um dos campos é usado)
+type renewalPeriod struct {
+	Meses int
+	Dias  int
+	Alvo  time.Time
+}
+
+// maxRenovacaoDias limita renovações por dias ou data alvo (10 anos)
+const maxRenovacaoDias = 3650
+
+// getHoraVencimento retorna a hora do dia em que os vencimentos terminam (RENOVACAO_HORA_VENCIMENTO, padrão 23)
+func getHoraVencimento() int {
+	hora, err := strconv.Atoi(os.Getenv("RENOVACAO_HORA_VENCIMENTO"))
+	if err != nil || hora < 0 || hora > 23 {
+		return 23
+	}
+	return hora
+}
+
+// parseRenewalPeriod valida que exatamente um período foi informado e interpreta a data alvo
+func parseRenewalPeriod(meses, dias int, dataAlvo string) (renewalPeriod, error) {
+	informados := 0
+	for _, v := range []bool{meses != 0, dias != 0, dataAlvo != ""} {
+		if v {
+			informados++
+		}
+	}
+	if informados != 1 {
+		return renewalPeriod{}, fmt.Errorf("informe apenas um período: quantidade_renovacao_em_meses, quantidade_dias ou data_alvo")
+	}
+	if meses < 0 || dias < 0 {
+		return renewalPeriod{}, fmt.Errorf("o período da renovação deve ser maior que zero")
+	}
+	if dias > maxRenovacaoDias {
+		return renewalPeriod{}, fmt.Errorf("quantidade_dias deve ser no máximo %d", maxRenovacaoDias)
+	}
+	if dataAlvo == "" {
+		return renewalPeriod{Meses: meses, Dias: dias}, nil
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04"} {
+		if alvo, err := time.ParseInLocation(layout, dataAlvo, time.Local); err == nil {
+			return renewalPeriod{Alvo: alvo}, nil
+		}
+	}
+	dia, err := time.ParseInLocation("2006-01-02", dataAlvo, time.Local)
+	if err != nil {
+		return renewalPeriod{}, fmt.Errorf("data_alvo inválida (use AAAA-MM-DD ou AAAA-MM-DD HH:MM)")
+	}
+	return renewalPeriod{Alvo: dia.Add(time.Duration(getHoraVencimento()) * time.Hour)}, nil
+}
+
+// resolve calcula, a partir de `base` (vencimento atual ou agora), o novo vencimento e os dias
+// cobrados proporcionalmente (0 quando a renovação é por meses)
+func (p renewalPeriod) resolve(base time.Time) (time.Time, int, error) {
+	hora := getHoraVencimento()
+	atHora := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), hora, 0, 0, 0, time.Local)
+	}
+	switch {
+	case p.Meses > 0:
+		return atHora(base.AddDate(0, 0, p.Meses*30)), 0, nil
+	case p.Dias > 0:
+		return atHora(base.AddDate(0, 0, p.Dias)), p.Dias, nil
+	default:
+		if !p.Alvo.After(base) {
+			return time.Time{}, 0, fmt.Errorf("data_alvo deve ser posterior ao vencimento atual (%s)", base.Format("2006-01-02 15:04"))
+		}
+		dias := int(math.Ceil(p.Alvo.Sub(base).Hours() / 24))
+		if dias > maxRenovacaoDias {
+			return time.Time{}, 0, fmt.Errorf("data_alvo deve estar a no máximo %d dias do vencimento atual", maxRenovacaoDias)
+		}
+		return p.Alvo, dias, nil
+	}
 }
 
 // RenewAccount renova a conta de um cliente.
 //
 // @Summary Renovar Conta
-// @Description Atualiza a data de expiração da conta com base no tempo selecionado.
+// @Description Atualiza a data de expiração da conta com base no tempo selecionado: meses (30 dias cada), quantidade_dias ou data_alvo (ex.: alinhar ao dia de pagamento do cliente). Dias e data alvo são cobrados proporcionalmente ao preço mensal, com o arredondamento de RENOVACAO_ARREDONDAMENTO; o custo e o detalhamento voltam na resposta. Testes não são renovados aqui: use /api/clients/{id}/convert.
 // @Tags Renovação
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Accept  json
 // @Produce  json
 // @Param renew body controllers.RenewRequest true "Dados para renovação"
+// @Param Idempotency-Key header string false "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem cobrar de novo"
 // @Success 200 {object} map[string]interface{} "Conta renovada com sucesso"
 // @Failure 400 {object} map[string]string "Erro na requisição"
 // @Failure 401 {object} map[string]string "Token inválido ou conta bloqueada"
-// @Failure 402 {object} map[string]string "Créditos insuficientes"
+// @Failure 402 {object} map[string]interface{} "Créditos insuficientes (formato padrão: erro, codigo, creditos_disponiveis, creditos_necessarios)"
+// @Failure 409 {object} map[string]string "Idempotency-Key em processamento, cliente é teste (use /api/clients/{id}/convert) ou cliente alterado por outra operação durante a renovação"
+// @Failure 422 {object} map[string]string "Idempotency-Key reutilizada com corpo diferente"
 // @Router /api/renew [post]
 func RenewAccount(c *gin.Context) {
-	// 🔹 1️⃣ Autenticação e validação do token
-	tokenString := c.GetHeader("Authorization")
-	if tokenString == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token não fornecido"})
-		return
-	}
-
-	claims, timeRemaining, err := utils.ValidateToken(tokenString)
+	// 🔹 1️⃣ Autenticação (JWT ou chave de API, validados pelo AuthMiddleware)
+	claims, timeRemaining, err := utils.RequestClaims(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token inválido"})
 		return
@@ -66,182 +146,251 @@ func RenewAccount(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos"})
 		return
 	}
+	periodo, err := parseRenewalPeriod(req.QuantidadeRenovacaoMes, req.QuantidadeDias, req.DataAlvo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
 
 	log.Printf("[DEBUG] Iniciando RenewAccount do userID: %d, Membro: %d", req.IDCliente, memberID)
 
-	// 🔹 4️⃣ Validar se o cliente pertence ao `member_id`
-	var userID, maxConnections int
-	var currentExpDate sql.NullInt64
-
-	query := `SELECT id, exp_date, max_connections FROM streamcreed_db.users WHERE id = ? AND member_id = ?`
-
-	log.Printf("🔍 Executando query para buscar cliente: %s\n", query)
+	// 🔹 4️⃣ a 6️⃣ Validar o cliente (ou sub-revenda), calcular custo e nova data de expiração
+	plan, rerr := planRenewal(claims, memberID, req.IDCliente, periodo)
+	if rerr != nil {
+		c.JSON(rerr.Status, rerr.Body)
+		return
+	}
 
-	err = config.DB.QueryRow(query, req.IDCliente, memberID).Scan(&userID, &currentExpDate, &maxConnections)
+	// 🔹 7️⃣ Transação para atualização segura (o saldo é validado no débito atômico)
+	log.Printf("[DEBUG] Iniciando transação para renovação")
+	tx, err := config.DB.Begin()
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusUnauthorized, gin.H{"erro": "Cliente não pertence a este MemberID"})
-			return
-		}
-		log.Printf("❌ Erro ao buscar cliente: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar cliente"})
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao iniciar transação"})
 		return
 	}
 
-	log.Printf("[DEBUG] userID encontrado: %d - maxConnections: %d - expDate: %v", userID, maxConnections, currentExpDate)
-
-	// 🔹 5️⃣ Validar créditos disponíveis (antes da renovação)
-	creditsFloat, exists := claims["credits"].(float64)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Créditos não encontrados no token"})
+	movimento, rerr := applyRenewal(tx, memberID, plan)
+	if rerr != nil {
+		tx.Rollback()
+		c.JSON(rerr.Status, rerr.Body)
 		return
 	}
-	creditosDisponiveis := int(creditsFloat)
+	creditosRestantes := movimento.SaldoDepois
 
-	// 🔹 6️⃣ Definir custo e duração da renovação
-	diasRenovacao := req.QuantidadeRenovacaoMes * 30
-	log.Printf("Quantidade de meses para renovação: %d, Dias de renovação: %d", req.QuantidadeRenovacaoMes, diasRenovacao)
+	// 🔹 **Finalizar transação**
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Erro ao finalizar transação para memberID %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao finalizar transação"})
+		return
+	}
 
-	// 🔹 **Calcular custo total com base em quantidade de meses e telas (max_connections)**
-	custoTotal := req.QuantidadeRenovacaoMes * maxConnections
-	log.Printf("Custo total calculado: %d (Quantidade de meses: %d * Máximo de conexões: %d)", custoTotal, req.QuantidadeRenovacaoMes, maxConnections)
+	// 🔹 Backup para reversão (renew_backup:) e log no MongoDB
+	operacaoID := finishRenewal(c, memberID, claims["username"].(string), plan)
 
-	log.Printf("[DEBUG] Créditos disponíveis: %d - Custo total: %d", creditosDisponiveis, custoTotal)
+	// Converter `timeRemaining` (segundos) para dias, horas, minutos e segundos
+	dias := timeRemaining / 86400
+	horas := (timeRemaining % 86400) / 3600
+	minutos := (timeRemaining % 3600) / 60
+	segundos := timeRemaining % 60
 
-	if creditosDisponiveis < custoTotal {
-		log.Printf("Créditos insuficientes. Disponível: %d, Necessário: %d", creditosDisponiveis, custoTotal)
-		c.JSON(http.StatusPaymentRequired, gin.H{
-			"erro":                 "Créditos insuficientes para renovação",
-			"creditos_disponiveis": creditosDisponiveis,
-			"creditos_necessarios": custoTotal,
-		})
-		return
-	}
+	// Formatar a string de tempo restante
+	tempoRestanteFormatado := fmt.Sprintf("%d dias, %d horas, %d minutos, %d segundos", dias, horas, minutos, segundos)
 
-	// 🔹 7️⃣ Calcular nova data de expiração (sempre às 23h00)
-	now := time.Now().Unix()
-	var newExpDate time.Time
+	// 🔹 ✅ Retorno da renovação
+	c.JSON(http.StatusOK, gin.H{
+		"status":             "Renovação concluída com sucesso",
+		"id_cliente":         plan.UserID,
+		"novo_exp_date":      plan.NovoExpDate,
+		"dias_renovados":     plan.DiasRenovados,
+		"creditos_gastos":    plan.Orcamento.Total,
+		"detalhamento_preco": plan.Orcamento.Detalhamento,
+		"creditos_restantes": creditosRestantes,
+		"token_expira_em":    tempoRestanteFormatado, // 🔥 Agora formatado corretamente!
+		"operacao_id":        operacaoID,             // Para desfazer em /api/undo/:operacao_id
+	})
+}
 
-	if currentExpDate.Valid && currentExpDate.Int64 >= now {
-		// Se exp_date for maior ou igual à data atual, assume exp_date
-		newExpDate = time.Unix(currentExpDate.Int64, 0).AddDate(0, 0, diasRenovacao)
-	} else {
-		// Se exp_date for menor ou igual à data atual, assume a data de hoje
-		newExpDate = time.Now().AddDate(0, 0, diasRenovacao)
-	}
+// renewalError é uma falha de renovação com o status e o corpo da resposta
+type renewalError struct {
+	Status int
+	Body   gin.H
+}
 
-	// Ajustar a nova data de expiração para sempre ser às 23h00
-	newExpDate = time.Date(newExpDate.Year(), newExpDate.Month(), newExpDate.Day(), 23, 0, 0, 0, time.Local)
-	newExpDateEpoch := newExpDate.Unix()
+// renewalPlan é uma renovação validada e orçada, pronta para ser aplicada
+type renewalPlan struct {
+	UserID         int
+	Meses          int // 0 quando a renovação é proporcional (Dias)
+	Dias           int // Dias cobrados proporcionalmente (0 quando a renovação é por meses)
+	DiasRenovados  int // Dias entre o vencimento base e o novo vencimento
+	MaxConnections int
+	ExpDateAtual   sql.NullInt64
+	ExpDateAntes   int64 // Guardado no backup para reversão
+	NovoExpDate    int64
+	Orcamento      *models.PriceQuote
+	ActorID        int // Quem executou (gravado no extrato); 0 = sistema
+}
 
-	log.Printf("[DEBUG] Nova exp_date calculada (Epoch): %d", newExpDateEpoch)
+// planRenewal valida se o cliente pertence à revenda (ou a uma sub-revenda), calcula a nova data de
+// expiração e o custo pelas regras de preço (proporcional quando o período é em dias ou data alvo)
+func planRenewal(claims jwt.MapClaims, memberID, clientID int, periodo renewalPeriod) (*renewalPlan, *renewalError) {
+	var userID, maxConnections, userMemberID, isTrial int
+	var currentExpDate sql.NullInt64
 
-	// 🔹 8️⃣ Transação para atualização segura
-	log.Printf("[DEBUG] Iniciando transação para renovação")
-	tx, err := config.DB.Begin()
+	query := `SELECT id, exp_date, max_connections, member_id, is_trial FROM streamcreed_db.users WHERE id = ?`
+	err := config.DB.QueryRow(query, clientID).Scan(&userID, &currentExpDate, &maxConnections, &userMemberID, &isTrial)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao iniciar transação"})
-		return
+		if err == sql.ErrNoRows {
+			return nil, &renewalError{http.StatusUnauthorized, gin.H{"erro": "Cliente não pertence a este MemberID"}}
+		}
+		log.Printf("❌ Erro ao buscar cliente: %v", err)
+		return nil, &renewalError{http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar cliente"}}
 	}
-
-	// 🔹 **Debitar créditos antes de renovar**
-	log.Printf("Tentando debitar créditos. MemberID: %d, CustoTotal: %d", memberID, custoTotal)
-	result, err := tx.Exec("UPDATE streamcreed_db.reg_users SET credits = credits - ? WHERE id = ? AND credits >= ?", custoTotal, memberID, custoTotal)
+	permitido, err := utils.PodeGerenciarRevenda(memberID, userMemberID, utils.RoleFromClaims(claims))
 	if err != nil {
-		tx.Rollback()
-		log.Printf("Erro ao debitar créditos para memberID %d: %v", memberID, err)
-		c.JSON(http.StatusPaymentRequired, gin.H{"erro": "Não foi possível debitar os créditos para renovação"})
-		return
+		log.Printf("❌ Erro ao verificar hierarquia de revendas: %v", err)
+		return nil, &renewalError{http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar cliente"}}
+	}
+	if !permitido {
+		return nil, &renewalError{http.StatusUnauthorized, gin.H{"erro": "Cliente não pertence a este MemberID"}}
 	}
 
-	// 🔹 **Verificar se o débito foi realizado**
-	rowsAffected, err := result.RowsAffected()
-	if err != nil || rowsAffected == 0 {
-		tx.Rollback()
-		log.Printf("Erro: débito de créditos não realizado para memberID %d. RowsAffected: %d", memberID, rowsAffected)
-		c.JSON(http.StatusPaymentRequired, gin.H{"erro": "Créditos insuficientes ou débito não realizado"})
-		return
+	// Testes têm política de vencimento e evento próprios: só viram pagos pela conversão
+	if isTrial == 1 {
+		return nil, &renewalError{http.StatusConflict, gin.H{"erro": fmt.Sprintf("Cliente é um teste. Use /api/clients/%d/convert para convertê-lo em cliente pago.", userID)}}
 	}
 
-	log.Printf("Créditos debitados com sucesso para memberID %d. RowsAffected: %d", memberID, rowsAffected)
+	log.Printf("[DEBUG] userID encontrado: %d - maxConnections: %d - expDate: %v", userID, maxConnections, currentExpDate)
 
-	// 🔹 **Verificar se os créditos foram debitados corretamente**
-	var creditosRestantes int
-	err = tx.QueryRow("SELECT credits FROM streamcreed_db.reg_users WHERE id = ?", memberID).Scan(&creditosRestantes)
-	if err != nil || creditosRestantes < 0 {
-		tx.Rollback()
-		log.Printf("Erro ao verificar créditos restantes para memberID %d: %v", memberID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar créditos após débito"})
-		return
+	// 🔹 Nova data de expiração: soma ao vencimento atual ou, se já venceu, a partir de agora
+	now := time.Now().Unix()
+	base := time.Now()
+	if currentExpDate.Valid && currentExpDate.Int64 >= now {
+		base = time.Unix(currentExpDate.Int64, 0)
+	}
+	newExpDate, dias, err := periodo.resolve(base)
+	if err != nil {
+		return nil, &renewalError{http.StatusBadRequest, gin.H{"erro": err.Error()}}
 	}
 
-	log.Printf("[DEBUG] Créditos restantes para memberID %d: %d", memberID, creditosRestantes)
-
-	// 🔹 **Renovar assinatura**
-	_, err = tx.Exec("UPDATE streamcreed_db.users SET exp_date = ?, is_trial = '0' WHERE id = ?", newExpDateEpoch, userID)
+	// 🔹 Calcular custo total pelas regras de preço (meses ou dias, telas, promoções)
+	orcamento, err := utils.QuotePrice(utils.PricingInput{
+		MemberID: memberID,
+		Produto:  models.ProdutoRenovacao,
+		Meses:    periodo.Meses,
+		Dias:     dias,
+		Telas:    maxConnections,
+	})
 	if err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao atualizar exp_date"})
-		return
+		log.Printf("❌ Erro ao calcular preço da renovação: %v", err)
+		return nil, &renewalError{http.StatusInternalServerError, gin.H{"erro": "Erro ao calcular o custo da renovação"}}
 	}
+	log.Printf("Custo total calculado: %.2f (Meses: %d, Dias: %d, Máximo de conexões: %d)", orcamento.Total, periodo.Meses, dias, maxConnections)
 
-	// Salve o exp_date antes da renovação (precisa ser ANTES de atualizar no banco)
-	expDateAntes := int64(0)
+	expDateAntes := now
 	if currentExpDate.Valid {
 		expDateAntes = currentExpDate.Int64
-	} else {
-		expDateAntes = time.Now().Unix()
 	}
 
-	// 🔹 **Finalizar transação**
-	err = tx.Commit()
+	return &renewalPlan{
+		UserID:         userID,
+		Meses:          periodo.Meses,
+		Dias:           dias,
+		DiasRenovados:  int(math.Round(newExpDate.Sub(base).Hours() / 24)),
+		MaxConnections: maxConnections,
+		ExpDateAtual:   currentExpDate,
+		ExpDateAntes:   expDateAntes,
+		NovoExpDate:    newExpDate.Unix(),
+		Orcamento:      orcamento,
+		ActorID:        memberID,
+	}, nil
+}
+
+// applyRenewal debita os créditos (saldo e extrato) e grava o novo vencimento dentro de tx
+func applyRenewal(tx *sql.Tx, memberID int, plan *renewalPlan) (*models.CreditLedgerEntry, *renewalError) {
+	log.Printf("Tentando debitar créditos. MemberID: %d, CustoTotal: %.2f", memberID, plan.Orcamento.Total)
+	movimento, err := models.DebitCredits(tx, plan.Orcamento.Total, models.CreditMovement{
+		MemberID:  memberID,
+		Operacao:  models.LedgerRenovacao,
+		UserID:    plan.UserID,
+		ActorID:   plan.ActorID,
+		Descricao: plan.descricao(),
+	})
 	if err != nil {
-		log.Printf("Erro ao finalizar transação para memberID %d: %v", memberID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao finalizar transação"})
-		return
+		if body, ok := creditosInsuficientesBody(err); ok {
+			log.Printf("Créditos insuficientes para memberID %d. Necessário: %.2f", memberID, plan.Orcamento.Total)
+			return nil, &renewalError{http.StatusPaymentRequired, body}
+		}
+		log.Printf("Erro ao debitar créditos para memberID %d: %v", memberID, err)
+		return nil, &renewalError{http.StatusInternalServerError, gin.H{"erro": "Não foi possível debitar os créditos para renovação"}}
+	}
+
+	// Condicional: o plano foi calculado fora da transação. Se outra renovação (ou alteração de telas)
+	// gravou o cliente nesse meio tempo, o novo vencimento e o preço estão desatualizados e o débito
+	// é desfeito com o rollback, em vez de cobrar duas vezes pela mesma extensão.
+	result, err := tx.Exec("UPDATE streamcreed_db.users SET exp_date = ? WHERE id = ? AND exp_date <=> ? AND max_connections = ? AND is_trial = '0'",
+		plan.NovoExpDate, plan.UserID, plan.ExpDateAtual, plan.MaxConnections)
+	if err != nil {
+		log.Printf("❌ Erro ao atualizar exp_date do cliente %d: %v", plan.UserID, err)
+		return nil, &renewalError{http.StatusInternalServerError, gin.H{"erro": "Erro ao atualizar exp_date"}}
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		log.Printf("⚠️ Cliente %d alterado durante a renovação; renovação não aplicada", plan.UserID)
+		return nil, &renewalError{http.StatusConflict, gin.H{"erro": "O cliente foi alterado por outra operação durante a renovação. Consulte o novo vencimento e tente novamente."}}
+	}
+	return movimento, nil
+}
+
+// descricao descreve a renovação no extrato de créditos
+func (p *renewalPlan) descricao() string {
+	if p.Dias > 0 {
+		return fmt.Sprintf("Renovação proporcional de %d dia(s) x %d tela(s)", p.Dias, p.MaxConnections)
 	}
-	log.Printf("[DEBUG] Transação finalizada. Salvando backup no Redis...")
+	return fmt.Sprintf("Renovação de %d mês(es) x %d tela(s)", p.Meses, p.MaxConnections)
+}
+
+// finishRenewal grava, depois do commit, o registro para desfazer, o backup usado pela reversão
+// (renew_backup:) e o log no MongoDB. Retorna o ID da operação para /api/undo.
+func finishRenewal(ctx context.Context, memberID int, username string, plan *renewalPlan) string {
+	undoID := saveRenewBackup(ctx, memberID, username, plan)
+	saveRenewLog(memberID, plan.UserID, plan.ExpDateAtual.Int64, plan.NovoExpDate, plan.Orcamento.Total, nil)
+	return undoID
+}
+
+// saveRenewBackup grava o registro para desfazer a renovação e guarda no Redis o estado anterior
+// à renovação, usado por RenewRollbackHandler. Retorna o ID do registro para desfazer.
+func saveRenewBackup(ctx context.Context, memberID int, username string, plan *renewalPlan) string {
+	undoID := recordUndo(ctx, models.UndoRecord{
+		Tipo:            models.UndoRenovacao,
+		UserID:          plan.UserID,
+		MemberIDCobrado: memberID,
+		ActorID:         plan.ActorID,
+		ActorUsername:   username,
+		ExpDateAntes:    plan.ExpDateAntes,
+		ExpDateDepois:   plan.NovoExpDate,
+		Creditos:        plan.Orcamento.Total,
+		Descricao:       plan.descricao(),
+	})
 
-	// --- SALVAR BACKUP DA RENOVAÇÃO NO REDIS ---
 	backup := models.RenewBackup{
-		ExpDateAnterior: expDateAntes,
-		CreditosGastos:  float64(custoTotal),
+		ExpDateAnterior: plan.ExpDateAntes,
+		CreditosGastos:  plan.Orcamento.Total,
 		DataRenovacao:   time.Now(),
-		AdminRenovou:    claims["username"].(string),
+		AdminRenovou:    username,
+		MemberIDRenovou: memberID,
+		UndoID:          undoID,
 	}
-	redisKey := "renew_backup:" + strconv.Itoa(userID)
-
-	if err := utils.SaveToRedisJSON(c, redisKey, backup, utils.GetRollbackPermitidoDias()*86400); err != nil {
+	redisKey := "renew_backup:" + strconv.Itoa(plan.UserID)
+	if err := utils.SaveToRedisJSON(ctx, redisKey, backup, utils.GetRollbackPermitidoDias()*86400); err != nil {
 		log.Printf("❌ Erro ao salvar backup no Redis: %v", err)
 	} else {
 		log.Printf("[DEBUG] Backup de renovação salvo no Redis: %s", redisKey)
 	}
-
-	// 🔹 **Salvar log no MongoDB**
-	saveRenewLog(memberID, userID, currentExpDate.Int64, newExpDateEpoch, custoTotal)
-
-	// Converter `timeRemaining` (segundos) para dias, horas, minutos e segundos
-	dias := timeRemaining / 86400
-	horas := (timeRemaining % 86400) / 3600
-	minutos := (timeRemaining % 3600) / 60
-	segundos := timeRemaining % 60
-
-	// Formatar a string de tempo restante
-	tempoRestanteFormatado := fmt.Sprintf("%d dias, %d horas, %d minutos, %d segundos", dias, horas, minutos, segundos)
-
-	// 🔹 ✅ Retorno da renovação
-	c.JSON(http.StatusOK, gin.H{
-		"status":             "Renovação concluída com sucesso",
-		"id_cliente":         userID,
-		"novo_exp_date":      newExpDateEpoch,
-		"creditos_gastos":    custoTotal,
-		"creditos_restantes": creditosRestantes,
-		"token_expira_em":    tempoRestanteFormatado, // 🔥 Agora formatado corretamente!
-	})
+	return undoID
 }
 
-// saveRenewLog salva os detalhes da renovação no MongoDB
-func saveRenewLog(memberID, userID int, oldExpDate, newExpDate int64, creditsSpent int) {
+// saveRenewLog salva os detalhes da renovação no MongoDB (extra: campos adicionais, opcional)
+func saveRenewLog(memberID, userID int, oldExpDate, newExpDate int64, creditsSpent float64, extra bson.M) {
 	if config.MongoDB == nil {
 		log.Println("⚠️ MongoDB não inicializado, ignorando log!")
 		return
@@ -265,6 +414,13 @@ func saveRenewLog(memberID, userID int, oldExpDate, newExpDate int64, creditsSpe
 		"new_exp_date":  time.Unix(newExpDate, 0).In(location).Format("2006-01-02 15:04:05"),
 		"credits_spent": creditsSpent,
 		"timestamp":     timestamp.Format("2006-01-02 15:04:05"), // Salva como string formatada
+		// Mesmos instantes sem formatação, lidos pelo histórico de renovações
+		"old_exp_date_unix": oldExpDate,
+		"new_exp_date_unix": newExpDate,
+		"created_at":        timestamp,
+	}
+	for k, v := range extra {
+		logEntry[k] = v
 	}
 
 	// Obtém a referência para a coleção "renew"
@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"apiBackEnd/utils"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Modos da renovação em lote
+const (
+	RenovacaoLoteTudoOuNada    = "tudo_ou_nada"
+	RenovacaoLoteMelhorEsforco = "melhor_esforco"
+)
+
+// BulkRenewItem é um cliente da renovação em lote (um período por cliente, como em /api/renew)
+type BulkRenewItem struct {
+	IDCliente              int    `json:"id_cliente" binding:"required"`
+	QuantidadeRenovacaoMes int    `json:"quantidade_renovacao_em_meses"`
+	QuantidadeDias         int    `json:"quantidade_dias"`
+	DataAlvo               string `json:"data_alvo"`
+}
+
+// BulkRenewRequest é o corpo de /api/renew/bulk
+type BulkRenewRequest struct {
+	// tudo_ou_nada: qualquer falha desfaz todas as renovações; melhor_esforco: renova o que for possível
+	Modo     string          `json:"modo" binding:"required,oneof=tudo_ou_nada melhor_esforco"`
+	Clientes []BulkRenewItem `json:"clientes" binding:"required,min=1,dive"`
+}
+
+// BulkRenewResult é o resultado de um cliente na renovação em lote
+type BulkRenewResult struct {
+	IDCliente         int                     `json:"id_cliente"`
+	Status            string                  `json:"status"` // renovado, falhou, revertido, nao_processado
+	NovoExpDate       int64                   `json:"novo_exp_date,omitempty"`
+	CreditosGastos    float64                 `json:"creditos_gastos"`
+	DetalhamentoPreco []models.PriceQuoteLine `json:"detalhamento_preco,omitempty"`
+	Erro              gin.H                   `json:"erro,omitempty"`
+	OperacaoID        string                  `json:"operacao_id,omitempty"` // Para desfazer em /api/undo/:operacao_id
+}
+
+// getRenovacaoLoteMax retorna o máximo de clientes por lote (RENOVACAO_LOTE_MAX, padrão 100)
+func getRenovacaoLoteMax() int {
+	val, err := strconv.Atoi(os.Getenv("RENOVACAO_LOTE_MAX"))
+	if err != nil || val <= 0 {
+		return 100
+	}
+	return val
+}
+
+// BulkRenewHandler renova vários clientes em uma única requisição.
+//
+// @Summary Renovação em Lote
+// @Description Renova vários clientes de uma vez. No modo "tudo_ou_nada", todos são validados e orçados antes e qualquer falha desfaz o lote inteiro (nenhum crédito é cobrado). No modo "melhor_esforco", cada cliente é renovado em sua própria transação e as falhas não afetam os demais. Cada renovação gera o mesmo backup (renew_backup:) usado pela reversão.
+// @Tags Renovação
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param body body BulkRenewRequest true "Modo e lista de clientes"
+// @Param Idempotency-Key header string false "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem cobrar de novo"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"modo\": \"melhor_esforco\", \"renovados\": 1, \"falhas\": 1, \"creditos_gastos\": 2, \"creditos_restantes\": 48, \"resultados\": [{\"id_cliente\": 10, \"status\": \"renovado\", \"novo_exp_date\": 1719000000, \"creditos_gastos\": 2}, {\"id_cliente\": 11, \"status\": \"falhou\", \"creditos_gastos\": 0, \"erro\": {\"erro\": \"Cliente não pertence a este MemberID\"}}]}"
+// @Failure 400 {object} map[string]string "Dados inválidos"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 402 {object} map[string]interface{} "Tudo ou nada: créditos insuficientes para o lote (resultados por cliente incluídos)"
+// @Failure 409 {object} map[string]string "Idempotency-Key em processamento ou, no tudo ou nada, cliente alterado por outra operação (ou repetido no lote) durante a renovação"
+// @Failure 422 {object} map[string]interface{} "Tudo ou nada: algum cliente falhou na validação (resultados por cliente incluídos)"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/renew/bulk [post]
+func BulkRenewHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+	claims, _, _ := utils.RequestClaims(c)
+
+	var req BulkRenewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "details": err.Error()})
+		return
+	}
+	if max := getRenovacaoLoteMax(); len(req.Clientes) > max {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Máximo de " + strconv.Itoa(max) + " clientes por lote"})
+		return
+	}
+	// Cada cliente só pode aparecer uma vez: o backup de reversão é um por cliente
+	vistos := make(map[int]bool, len(req.Clientes))
+	periodos := make([]renewalPeriod, len(req.Clientes))
+	for i, item := range req.Clientes {
+		if vistos[item.IDCliente] {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "Cliente repetido no lote", "id_cliente": item.IDCliente})
+			return
+		}
+		vistos[item.IDCliente] = true
+
+		periodo, err := parseRenewalPeriod(item.QuantidadeRenovacaoMes, item.QuantidadeDias, item.DataAlvo)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error(), "id_cliente": item.IDCliente})
+			return
+		}
+		periodos[i] = periodo
+	}
+
+	log.Printf("[DEBUG] Iniciando renovação em lote (%s) de %d clientes, Membro: %d", req.Modo, len(req.Clientes), tokenInfo.MemberID)
+
+	resultados := make([]BulkRenewResult, len(req.Clientes))
+	for i, item := range req.Clientes {
+		resultados[i] = BulkRenewResult{IDCliente: item.IDCliente, Status: "nao_processado"}
+	}
+
+	if req.Modo == RenovacaoLoteTudoOuNada {
+		bulkRenewAllOrNothing(c, tokenInfo, claims, req.Clientes, periodos, resultados)
+		return
+	}
+
+	// Melhor esforço: cada cliente na sua própria transação
+	var creditosRestantes *float64
+	for i, item := range req.Clientes {
+		plan, rerr := planRenewal(claims, tokenInfo.MemberID, item.IDCliente, periodos[i])
+		if rerr != nil {
+			resultados[i].Status, resultados[i].Erro = "falhou", rerr.Body
+			continue
+		}
+		tx, err := config.DB.Begin()
+		if err != nil {
+			resultados[i].Status, resultados[i].Erro = "falhou", gin.H{"erro": "Erro ao iniciar transação"}
+			continue
+		}
+		movimento, rerr := applyRenewal(tx, tokenInfo.MemberID, plan)
+		if rerr != nil {
+			tx.Rollback()
+			resultados[i].Status, resultados[i].Erro = "falhou", rerr.Body
+			continue
+		}
+		if err := tx.Commit(); err != nil {
+			log.Printf("Erro ao finalizar transação do cliente %d no lote: %v", item.IDCliente, err)
+			resultados[i].Status, resultados[i].Erro = "falhou", gin.H{"erro": "Erro ao finalizar transação"}
+			continue
+		}
+		resultados[i].OperacaoID = finishRenewal(c, tokenInfo.MemberID, tokenInfo.Username, plan)
+		markRenewed(&resultados[i], plan)
+		creditosRestantes = &movimento.SaldoDepois
+	}
+
+	respondBulkRenew(c, http.StatusOK, req.Modo, resultados, creditosRestantes)
+}
+
+// bulkRenewAllOrNothing valida e orça todos os clientes antes e aplica o lote em uma única transação
+func bulkRenewAllOrNothing(c *gin.Context, tokenInfo *utils.TokenInfo, claims map[string]interface{}, itens []BulkRenewItem, periodos []renewalPeriod, resultados []BulkRenewResult) {
+	plans := make([]*renewalPlan, len(itens))
+	falhou := false
+	for i, item := range itens {
+		plan, rerr := planRenewal(claims, tokenInfo.MemberID, item.IDCliente, periodos[i])
+		if rerr != nil {
+			resultados[i].Status, resultados[i].Erro = "falhou", rerr.Body
+			falhou = true
+			continue
+		}
+		plans[i] = plan
+	}
+	if falhou {
+		respondBulkRenew(c, http.StatusUnprocessableEntity, RenovacaoLoteTudoOuNada, resultados, nil)
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao iniciar transação"})
+		return
+	}
+	var creditosRestantes float64
+	for i, plan := range plans {
+		movimento, rerr := applyRenewal(tx, tokenInfo.MemberID, plan)
+		if rerr != nil {
+			tx.Rollback()
+			for j := 0; j < i; j++ {
+				resultados[j].Status = "revertido"
+			}
+			resultados[i].Status, resultados[i].Erro = "falhou", rerr.Body
+			respondBulkRenew(c, rerr.Status, RenovacaoLoteTudoOuNada, resultados, nil)
+			return
+		}
+		creditosRestantes = movimento.SaldoDepois
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Erro ao finalizar transação da renovação em lote do membro %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao finalizar transação"})
+		return
+	}
+
+	for i, plan := range plans {
+		resultados[i].OperacaoID = finishRenewal(c, tokenInfo.MemberID, tokenInfo.Username, plan)
+		markRenewed(&resultados[i], plan)
+	}
+	respondBulkRenew(c, http.StatusOK, RenovacaoLoteTudoOuNada, resultados, &creditosRestantes)
+}
+
+func markRenewed(result *BulkRenewResult, plan *renewalPlan) {
+	result.Status = "renovado"
+	result.NovoExpDate = plan.NovoExpDate
+	result.CreditosGastos = plan.Orcamento.Total
+	result.DetalhamentoPreco = plan.Orcamento.Detalhamento
+}
+
+// respondBulkRenew responde com o resumo do lote e o resultado de cada cliente
+func respondBulkRenew(c *gin.Context, status int, modo string, resultados []BulkRenewResult, creditosRestantes *float64) {
+	renovados, falhas := 0, 0
+	var gastos float64
+	for _, r := range resultados {
+		switch r.Status {
+		case "renovado":
+			renovados++
+			gastos += r.CreditosGastos
+		case "falhou":
+			falhas++
+		}
+	}
+
+	resp := gin.H{
+		"modo":            modo,
+		"renovados":       renovados,
+		"falhas":          falhas,
+		"creditos_gastos": gastos,
+		"resultados":      resultados,
+	}
+	if creditosRestantes != nil {
+		resp["creditos_restantes"] = *creditosRestantes
+	}
+	if status != http.StatusOK {
+		resp["erro"] = "Nenhum cliente foi renovado: o lote foi cancelado por falha em um ou mais clientes"
+	}
+	c.JSON(status, resp)
+}
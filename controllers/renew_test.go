@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRenewalPeriod(t *testing.T) {
+	t.Setenv("RENOVACAO_HORA_VENCIMENTO", "23")
+
+	casos := []struct {
+		nome     string
+		meses    int
+		dias     int
+		dataAlvo string
+		want     renewalPeriod
+		erro     bool
+	}{
+		{"meses", 3, 0, "", renewalPeriod{Meses: 3}, false},
+		{"dias", 0, 10, "", renewalPeriod{Dias: 10}, false},
+		{"data alvo na hora padrão", 0, 0, "2030-05-10", renewalPeriod{Alvo: time.Date(2030, 5, 10, 23, 0, 0, 0, time.Local)}, false},
+		{"data alvo com hora", 0, 0, "2030-05-10 08:30", renewalPeriod{Alvo: time.Date(2030, 5, 10, 8, 30, 0, 0, time.Local)}, false},
+		{"nenhum período", 0, 0, "", renewalPeriod{}, true},
+		{"dois períodos", 1, 10, "", renewalPeriod{}, true},
+		{"meses negativos", -1, 0, "", renewalPeriod{}, true},
+		{"dias acima do máximo", 0, maxRenovacaoDias + 1, "", renewalPeriod{}, true},
+		{"data alvo inválida", 0, 0, "10/05/2030", renewalPeriod{}, true},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			got, err := parseRenewalPeriod(tc.meses, tc.dias, tc.dataAlvo)
+			if tc.erro {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.Meses, got.Meses)
+			assert.Equal(t, tc.want.Dias, got.Dias)
+			assert.True(t, tc.want.Alvo.Equal(got.Alvo), "alvo %v, esperado %v", got.Alvo, tc.want.Alvo)
+		})
+	}
+}
+
+func TestRenewalPeriodResolve(t *testing.T) {
+	t.Setenv("RENOVACAO_HORA_VENCIMENTO", "23")
+	base := time.Date(2030, 1, 15, 10, 0, 0, 0, time.Local)
+
+	casos := []struct {
+		nome    string
+		periodo renewalPeriod
+		novoExp time.Time
+		dias    int
+		erro    bool
+	}{
+		{"um mês são 30 dias, na hora do vencimento", renewalPeriod{Meses: 1}, time.Date(2030, 2, 14, 23, 0, 0, 0, time.Local), 0, false},
+		{"meses não cobram dias", renewalPeriod{Meses: 12}, time.Date(2031, 1, 10, 23, 0, 0, 0, time.Local), 0, false},
+		{"dias proporcionais", renewalPeriod{Dias: 10}, time.Date(2030, 1, 25, 23, 0, 0, 0, time.Local), 10, false},
+		{"data alvo arredonda os dias para cima", renewalPeriod{Alvo: time.Date(2030, 1, 20, 23, 0, 0, 0, time.Local)}, time.Date(2030, 1, 20, 23, 0, 0, 0, time.Local), 6, false},
+		{"data alvo anterior ao vencimento", renewalPeriod{Alvo: base.Add(-time.Hour)}, time.Time{}, 0, true},
+		{"data alvo igual ao vencimento", renewalPeriod{Alvo: base}, time.Time{}, 0, true},
+		{"data alvo além do máximo", renewalPeriod{Alvo: base.AddDate(0, 0, maxRenovacaoDias+1)}, time.Time{}, 0, true},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			novoExp, dias, err := tc.periodo.resolve(base)
+			if tc.erro {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tc.novoExp.Equal(novoExp), "novo vencimento %v, esperado %v", novoExp, tc.novoExp)
+			assert.Equal(t, tc.dias, dias)
+		})
+	}
+}
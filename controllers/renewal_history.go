@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"apiBackEnd/utils"
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Histórico de renovações
+//
+// Junta os logs que alteram o vencimento do cliente: Logs.renew (criação paga, conversão de teste, renovações manuais e automáticas)
+// e Logs.actions_log (reversões, bônus de confiança, alterações de vencimento e operações desfeitas).
+// Logs antigos de renovação só têm as datas formatadas em America/Sao_Paulo; elas são convertidas de volta.
+
+// maxEventosPorColecao limita a leitura de cada coleção do MongoDB aos eventos mais recentes
+const maxEventosPorColecao = 1000
+
+// GetClientRenewalsHandler godoc
+// @Summary Histórico de Renovações do Cliente
+// @Description Lista, em ordem cronológica, a criação paga, a conversão de teste, as renovações (manuais e automáticas), reversões, bônus de confiança, alterações de vencimento e operações desfeitas do cliente. Datas em timestamp UNIX. Traz no máximo os 1000 eventos mais recentes de cada origem; quando há mais, a resposta vem com truncado=true e começa no evento mais antigo que coube. Apenas clientes da revenda (ou de suas sub-revendas) podem ser consultados.
+// @Tags Renovação
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path int true "ID do cliente"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"id_cliente\": 10, \"total\": 2, \"truncado\": false, \"eventos\": [{\"tipo\": \"renovacao\", \"ocorrido_em\": 1717000000, \"exp_date_anterior\": 1717100000, \"novo_exp_date\": 1719700000, \"creditos\": 2, \"member_id\": 5}]}"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 403 {object} map[string]string "Cliente não pertence à revenda"
+// @Failure 404 {object} map[string]string "Cliente não encontrado"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/clients/{id}/renewals [get]
+func GetClientRenewalsHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "ID de usuário inválido"})
+		return
+	}
+
+	permitido, _, err := utils.VerificaPermissaoUsuario(userID, tokenInfo.MemberID, tokenInfo.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"erro": "Usuário não encontrado"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar permissões"})
+		return
+	}
+	if !permitido {
+		c.JSON(http.StatusForbidden, gin.H{"erro": "Usuário não pertence à sua revenda"})
+		return
+	}
+
+	if config.MongoDB == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "MongoDB não inicializado"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	eventos, renovacoesTruncadas, err := loadRenewEvents(ctx, userID)
+	if err != nil {
+		log.Printf("❌ Erro ao buscar renovações do cliente %d no MongoDB: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar histórico de renovações"})
+		return
+	}
+	acoes, acoesTruncadas, err := loadActionEvents(ctx, userID)
+	if err != nil {
+		log.Printf("❌ Erro ao buscar ações do cliente %d no MongoDB: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar histórico de renovações"})
+		return
+	}
+	// Com uma coleção truncada, o histórico começa no evento mais antigo que coube nela:
+	// eventos anteriores da outra coleção deixariam um buraco sem aviso no meio da lista
+	var corte int64
+	if renovacoesTruncadas {
+		corte = oldestHistoryEvent(eventos)
+	}
+	if acoesTruncadas {
+		if oldest := oldestHistoryEvent(acoes); oldest > corte {
+			corte = oldest
+		}
+	}
+	eventos = append(eventos, acoes...)
+	sort.SliceStable(eventos, func(i, j int) bool { return eventos[i].OcorridoEm < eventos[j].OcorridoEm })
+	if corte > 0 {
+		inicio := sort.Search(len(eventos), func(i int) bool { return eventos[i].OcorridoEm >= corte })
+		eventos = eventos[inicio:]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id_cliente": userID,
+		"total":      len(eventos),
+		"truncado":   renovacoesTruncadas || acoesTruncadas,
+		"eventos":    eventos,
+	})
+}
+
+// oldestHistoryEvent devolve o OcorridoEm mais antigo da lista, ignorando datas desconhecidas (0 se não houver)
+func oldestHistoryEvent(eventos []models.ClientHistoryEvent) int64 {
+	var oldest int64
+	for _, ev := range eventos {
+		if ev.OcorridoEm > 0 && (oldest == 0 || ev.OcorridoEm < oldest) {
+			oldest = ev.OcorridoEm
+		}
+	}
+	return oldest
+}
+
+// findRecentHistoryDocs lê os maxEventosPorColecao documentos mais recentes pela chave de ordenação;
+// um documento a mais indica que a coleção tinha eventos mais antigos que ficaram de fora
+func findRecentHistoryDocs(ctx context.Context, collection string, filtro bson.M, ordem string) ([]bson.M, bool, error) {
+	opts := options.Find().SetSort(bson.D{{Key: ordem, Value: -1}}).SetLimit(maxEventosPorColecao + 1)
+	cursor, err := config.MongoDB.Database("Logs").Collection(collection).Find(ctx, filtro, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, false, err
+	}
+	truncado := len(docs) > maxEventosPorColecao
+	if truncado {
+		docs = docs[:maxEventosPorColecao]
+	}
+	return docs, truncado, nil
+}
+
+// loadRenewEvents lê as renovações mais recentes do cliente gravadas por saveRenewLog
+func loadRenewEvents(ctx context.Context, userID int) ([]models.ClientHistoryEvent, bool, error) {
+	docs, truncado, err := findRecentHistoryDocs(ctx, "renew", bson.M{"user_id": userID}, "_id")
+	if err != nil {
+		return nil, false, err
+	}
+
+	eventos := make([]models.ClientHistoryEvent, 0, len(docs))
+	for _, doc := range docs {
+		ev := models.ClientHistoryEvent{Tipo: models.HistoricoRenovacao}
+		switch origem, _ := doc["origem"].(string); origem {
+		case autoRenewOrigem:
+			ev.Tipo = models.HistoricoRenovacaoAutomatica
+		case clientCreateOrigem:
+			ev.Tipo = models.HistoricoCriacaoCliente
+		case trialConversionOrigem:
+			ev.Tipo = models.HistoricoConversaoTeste
+		}
+		ev.OcorridoEm = historyTime(doc["created_at"], doc["timestamp"])
+		ev.ExpDateAnterior = historyTimePtr(doc["old_exp_date_unix"], doc["old_exp_date"])
+		ev.NovoExpDate = historyTimePtr(doc["new_exp_date_unix"], doc["new_exp_date"])
+		if v, ok := bsonFloat(doc["credits_spent"]); ok {
+			ev.Creditos = &v
+		}
+		if v, ok := bsonFloat(doc["member_id"]); ok {
+			ev.MemberID = int(v)
+		}
+		if v, ok := doc["sucesso"].(bool); ok {
+			ev.Sucesso = &v
+			if !v {
+				// Tentativa sem renovação: o vencimento não mudou
+				ev.NovoExpDate = nil
+			}
+		}
+		ev.Erro, _ = doc["erro"].(string)
+		eventos = append(eventos, ev)
+	}
+	return eventos, truncado, nil
+}
+
+// loadActionEvents lê reversões, bônus de confiança e alterações de vencimento (SaveActionLog)
+func loadActionEvents(ctx context.Context, userID int) ([]models.ClientHistoryEvent, bool, error) {
+	tipos := map[string]string{
+		"renew_rollback":  models.HistoricoReversaoRenovacao,
+		"trust_bonus":     models.HistoricoBonusConfianca,
+		"change_due_date": models.HistoricoAlteracaoVencimento,
+		"undo_operation":  models.HistoricoOperacaoDesfeita,
+	}
+	actions := make([]string, 0, len(tipos))
+	for action := range tipos {
+		actions = append(actions, action)
+	}
+
+	docs, truncado, err := findRecentHistoryDocs(ctx, "actions_log", bson.M{"user_id": userID, "action": bson.M{"$in": actions}}, "timestamp")
+	if err != nil {
+		return nil, false, err
+	}
+
+	eventos := make([]models.ClientHistoryEvent, 0, len(docs))
+	for _, doc := range docs {
+		action, _ := doc["action"].(string)
+		ev := models.ClientHistoryEvent{Tipo: tipos[action], OcorridoEm: historyTime(doc["timestamp"])}
+		ev.ExecutadoPor, _ = doc["admin_id"].(string)
+
+		// Os detalhes são structs de models gravadas sem tags bson: as chaves são os nomes dos campos em minúsculas
+		details, _ := doc["details"].(bson.M)
+		if details == nil {
+			eventos = append(eventos, ev)
+			continue
+		}
+		ev.Motivo, _ = details["motivo"].(string)
+		switch action {
+		case "renew_rollback":
+			// O vencimento volta para o anterior à renovação e os créditos são devolvidos
+			ev.NovoExpDate = historyTimePtr(details["expdateanterior"])
+			if v, ok := bsonFloat(details["creditosgastos"]); ok {
+				ev.Creditos = &v
+			}
+			if v, ok := bsonFloat(details["memberidrenovou"]); ok {
+				ev.MemberID = int(v)
+			}
+		case "trust_bonus":
+			if v, ok := bsonFloat(details["diasadicionados"]); ok {
+				dias := int(v)
+				ev.Dias = &dias
+			}
+			ev.ExpDateAnterior = historyTimePtr(details["expdateanterior"])
+			ev.NovoExpDate = historyTimePtr(details["novoexpdate"])
+		case "undo_operation":
+			// details: {operacao: UndoRecord, motivo}; só interessa quando o vencimento voltou
+			operacao, _ := details["operacao"].(bson.M)
+			ev.TipoDesfeito, _ = operacao["tipo"].(string)
+			if ev.TipoDesfeito == models.UndoTelaAdicional {
+				continue
+			}
+			ev.ExpDateAnterior = historyTimePtr(operacao["expdatedepois"])
+			ev.NovoExpDate = historyTimePtr(operacao["expdateantes"])
+			if v, ok := bsonFloat(operacao["creditos"]); ok && v > 0 {
+				ev.Creditos = &v
+			}
+			ev.ExecutadoPor, _ = doc["admin_id"].(string)
+		case "change_due_date":
+			if v, ok := bsonFloat(details["novadatavencimento"]); ok {
+				dia := int(v)
+				ev.DiaVencimento = &dia
+			}
+			ev.ExpDateAnterior = historyTimePtr(details["expdateanterior"])
+			ev.NovoExpDate = historyTimePtr(details["novoexpdate"])
+		}
+		eventos = append(eventos, ev)
+	}
+	return eventos, truncado, nil
+}
+
+// bsonFloat converte os tipos numéricos do MongoDB em float64
+func bsonFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// historyTimePtr converte o primeiro valor disponível em timestamp UNIX (nil se nenhum for válido)
+func historyTimePtr(valores ...interface{}) *int64 {
+	if ts := historyTime(valores...); ts > 0 {
+		return &ts
+	}
+	return nil
+}
+
+// historyTime converte o primeiro valor disponível (data do MongoDB, timestamp numérico ou string
+// "2006-01-02 15:04:05" em America/Sao_Paulo, formato dos logs antigos) em timestamp UNIX
+func historyTime(valores ...interface{}) int64 {
+	for _, v := range valores {
+		switch t := v.(type) {
+		case primitive.DateTime:
+			return t.Time().Unix()
+		case time.Time:
+			return t.Unix()
+		case string:
+			location, err := time.LoadLocation("America/Sao_Paulo")
+			if err != nil {
+				location = time.Local
+			}
+			if parsed, err := time.ParseInLocation("2006-01-02 15:04:05", t, location); err == nil {
+				return parsed.Unix()
+			}
+		default:
+			if n, ok := bsonFloat(v); ok && n > 0 {
+				return int64(n)
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"apiBackEnd/utils"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// resolveRevendaAlvo retorna a revenda cujos dados serão exibidos: a do token ou, com
+// ?revenda_id=, uma sub-revenda dela. Em caso de falha já responde e retorna ok=false.
+func resolveRevendaAlvo(c *gin.Context, claims jwt.MapClaims, memberID int) (int, bool) {
+	revendaIDStr := c.Query("revenda_id")
+	if revendaIDStr == "" {
+		return memberID, true
+	}
+	revendaID, err := strconv.Atoi(revendaIDStr)
+	if err != nil || revendaID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "revenda_id inválido"})
+		return 0, false
+	}
+	permitido, err := utils.PodeGerenciarRevenda(memberID, revendaID, utils.RoleFromClaims(claims))
+	if err != nil {
+		log.Printf("❌ Erro ao verificar hierarquia de revendas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar permissões"})
+		return 0, false
+	}
+	if !permitido {
+		c.JSON(http.StatusForbidden, gin.H{"erro": "Revenda não pertence à sua árvore de sub-revendas"})
+		return 0, false
+	}
+	return revendaID, true
+}
+
+// getSubRevendaGrupoID retorna o member_group_id das novas sub-revendas (SUB_REVENDA_GRUPO_ID).
+// Obrigatório e nunca um grupo de admin: a sub-revenda não herda o grupo de quem a cria.
+func getSubRevendaGrupoID() (int, error) {
+	val, err := strconv.Atoi(os.Getenv("SUB_REVENDA_GRUPO_ID"))
+	if err != nil || val <= 0 {
+		return 0, fmt.Errorf("SUB_REVENDA_GRUPO_ID não configurado")
+	}
+	if utils.RoleFromGroup(val) == utils.RoleAdmin {
+		return 0, fmt.Errorf("SUB_REVENDA_GRUPO_ID (%d) é um grupo de admin", val)
+	}
+	return val, nil
+}
+
+// ListSubResellersHandler godoc
+// @Summary Listar Sub-revendas
+// @Description Lista todas as sub-revendas abaixo da revenda autenticada (em todos os níveis), com créditos e status.
+// @Tags Sub-revendas
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"total\": 1, \"sub_revendas\": [{\"id\": 42, \"username\": \"sub1\", \"owner_id\": 7, \"member_group_id\": 2, \"credits\": 50, \"status\": 1, \"nivel\": 1}]}"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/resellers [get]
+func ListSubResellersHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	subs, err := models.ListSubResellers(tokenInfo.MemberID)
+	if err != nil {
+		log.Printf("❌ Erro ao listar sub-revendas de %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao listar sub-revendas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":        len(subs),
+		"sub_revendas": subs,
+	})
+}
+
+// CreateSubResellerHandler godoc
+// @Summary Criar Sub-revenda
+// @Description Cria uma sub-revenda abaixo da revenda autenticada. Créditos iniciais (opcionais) são transferidos da master na mesma transação.
+// @Tags Sub-revendas
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.CreateSubResellerPayload true "Dados da sub-revenda"
+// @Success 201 {object} map[string]interface{} "Exemplo: {\"message\": \"Sub-revenda criada com sucesso\", \"id\": 42, \"creditos_transferidos\": 10}"
+// @Failure 400 {object} map[string]string "Dados inválidos"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 402 {object} map[string]interface{} "Créditos insuficientes (formato padrão: erro, codigo, creditos_disponiveis, creditos_necessarios)"
+// @Failure 409 {object} map[string]string "Username já existe"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/resellers [post]
+func CreateSubResellerHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateSubResellerPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "details": err.Error()})
+		return
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Credits < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Créditos iniciais não podem ser negativos"})
+		return
+	}
+
+	exists, err := models.ResellerUsernameExists(req.Username)
+	if err != nil {
+		log.Printf("❌ Erro ao verificar username de revenda: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao criar sub-revenda"})
+		return
+	}
+	if exists {
+		c.JSON(http.StatusConflict, gin.H{"erro": "Já existe uma revenda com este username"})
+		return
+	}
+
+	grupoID, err := getSubRevendaGrupoID()
+	if err != nil {
+		log.Printf("❌ Criação de sub-revenda recusada: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Grupo das sub-revendas não configurado (SUB_REVENDA_GRUPO_ID)"})
+		return
+	}
+
+	hashedPassword, err := utils.CryptPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao processar senha"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao iniciar transação"})
+		return
+	}
+
+	now := time.Now().Unix()
+	result, err := tx.Exec(`
+		INSERT INTO streamcreed_db.reg_users (username, password, email, member_group_id, credits, status, owner_id, date_registered, notes, verified)
+		VALUES (?, ?, ?, ?, 0, 1, ?, ?, ?, 1)`,
+		req.Username, hashedPassword, req.Email, grupoID, tokenInfo.MemberID, now, req.Notes)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("❌ Erro ao criar sub-revenda para %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao criar sub-revenda"})
+		return
+	}
+	subID, _ := result.LastInsertId()
+
+	if req.Credits > 0 {
+		if _, err := transferCredits(tx, tokenInfo.MemberID, tokenInfo.Username, int(subID), req.Username, req.Credits, "Créditos iniciais"); err != nil {
+			tx.Rollback()
+			if respondCreditosInsuficientes(c, err) {
+				return
+			}
+			log.Printf("❌ Erro ao transferir créditos iniciais para a sub-revenda: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao transferir créditos"})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao finalizar transação"})
+		return
+	}
+
+	utils.SaveActionLog(int(subID), "create_sub_reseller", gin.H{
+		"username":              req.Username,
+		"creditos_transferidos": req.Credits,
+	}, strconv.Itoa(tokenInfo.MemberID))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":               "Sub-revenda criada com sucesso",
+		"id":                    subID,
+		"username":              req.Username,
+		"creditos_transferidos": req.Credits,
+	})
+}
+
+// TransferCreditsHandler godoc
+// @Summary Transferir Créditos para Sub-revenda
+// @Description Move créditos da revenda autenticada para uma sub-revenda da sua árvore. Débito e crédito acontecem na mesma transação.
+// @Tags Sub-revendas
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param member_id path int true "ID da sub-revenda"
+// @Param body body models.CreditTransferPayload true "Quantidade de créditos"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"message\": \"Créditos transferidos com sucesso\", \"creditos_restantes\": 90}"
+// @Failure 400 {object} map[string]string "Dados inválidos"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 402 {object} map[string]interface{} "Créditos insuficientes (formato padrão: erro, codigo, creditos_disponiveis, creditos_necessarios)"
+// @Failure 403 {object} map[string]string "Revenda fora da árvore"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/resellers/{member_id}/credits [post]
+func TransferCreditsHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	subID, err := strconv.Atoi(c.Param("member_id"))
+	if err != nil || subID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "ID da revenda inválido"})
+		return
+	}
+	var req models.CreditTransferPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "details": err.Error()})
+		return
+	}
+
+	// Só é possível transferir para baixo na árvore (nunca para si mesmo ou para a master)
+	isSub, err := models.IsSubReseller(tokenInfo.MemberID, subID)
+	if err != nil {
+		log.Printf("❌ Erro ao verificar hierarquia de revendas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar permissões"})
+		return
+	}
+	if !isSub {
+		c.JSON(http.StatusForbidden, gin.H{"erro": "A revenda informada não é sua sub-revenda"})
+		return
+	}
+	sub, err := models.GetUserByID(subID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Sub-revenda não encontrada"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao iniciar transação"})
+		return
+	}
+
+	creditosRestantes, err := transferCredits(tx, tokenInfo.MemberID, tokenInfo.Username, subID, sub.Username, req.Credits, req.Motivo)
+	if err != nil {
+		tx.Rollback()
+		if respondCreditosInsuficientes(c, err) {
+			return
+		}
+		log.Printf("❌ Erro ao transferir créditos de %d para %d: %v", tokenInfo.MemberID, subID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao transferir créditos"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao finalizar transação"})
+		return
+	}
+
+	utils.SaveActionLog(subID, "credit_transfer", gin.H{
+		"de":       tokenInfo.MemberID,
+		"para":     subID,
+		"creditos": req.Credits,
+		"motivo":   req.Motivo,
+	}, strconv.Itoa(tokenInfo.MemberID))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Créditos transferidos com sucesso",
+		"creditos":           req.Credits,
+		"creditos_restantes": creditosRestantes,
+	})
+}
+
+// transferCredits move créditos da master para a sub-revenda dentro de tx: grava as duas
+// movimentações no extrato e no credits_log do painel. Retorna o saldo final da master.
+func transferCredits(tx *sql.Tx, masterID int, masterUsername string, subID int, subUsername string, credits float64, motivo string) (float64, error) {
+	descricaoSaida := fmt.Sprintf("Transferência para sub-revenda %s", subUsername)
+	descricaoEntrada := fmt.Sprintf("Transferência recebida de %s", masterUsername)
+	if motivo != "" {
+		descricaoSaida += " (" + motivo + ")"
+		descricaoEntrada += " (" + motivo + ")"
+	}
+
+	saida, err := models.DebitCredits(tx, credits, models.CreditMovement{
+		MemberID:  masterID,
+		Operacao:  models.LedgerTransferenciaEnviada,
+		ActorID:   masterID,
+		Descricao: descricaoSaida,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := models.ApplyCreditMovement(tx, models.CreditMovement{
+		MemberID:  subID,
+		Operacao:  models.LedgerTransferenciaRecebida,
+		Valor:     credits,
+		ActorID:   masterID,
+		Descricao: descricaoEntrada,
+	}); err != nil {
+		return 0, err
+	}
+
+	now := time.Now().Unix()
+	_, err = tx.Exec("INSERT INTO streamcreed_db.credits_log (target_id, admin_id, amount, `date`, reason) VALUES (?, ?, ?, ?, ?)",
+		masterID, masterID, -credits, now, descricaoSaida)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec("INSERT INTO streamcreed_db.credits_log (target_id, admin_id, amount, `date`, reason) VALUES (?, ?, ?, ?, ?)",
+		subID, masterID, credits, now, descricaoEntrada)
+	if err != nil {
+		return 0, err
+	}
+	return saida.SaldoDepois, nil
+}
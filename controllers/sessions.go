@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"apiBackEnd/utils"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListSessionsHandler godoc
+// @Summary Listar Sessões Ativas
+// @Description Lista as sessões (dispositivos) em que a revenda está logada, com IP, user agent, data de criação e último uso. A sessão do token usado na requisição vem marcada com "atual".
+// @Tags Sessões
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"total\": 2, \"sessoes\": [{\"session_id\": \"9f1c...\", \"ip\": \"200.1.2.3\", \"user_agent\": \"Mozilla/5.0\", \"criado_em\": \"2025-05-20T10:00:00-03:00\", \"ultimo_uso\": \"2025-05-20T10:15:00-03:00\", \"expira_em\": \"2025-06-19T10:00:00-03:00\", \"atual\": true}]}"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/sessions [get]
+func ListSessionsHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := utils.ListSessions(c.Request.Context(), tokenInfo.MemberID, tokenInfo.SessionID)
+	if err != nil {
+		log.Printf("❌ Erro ao listar sessões da revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao listar sessões"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":   len(sessions),
+		"sessoes": sessions,
+	})
+}
+
+// RevokeSessionHandler godoc
+// @Summary Encerrar Sessão
+// @Description Encerra uma sessão específica da própria revenda (ex.: dispositivo perdido ou roubado).
+// @Tags Sessões
+// @Security BearerAuth
+// @Produce json
+// @Param session_id path string true "ID da sessão"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"message\": \"Sessão encerrada com sucesso\"}"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 404 {object} map[string]string "Sessão não encontrada"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/sessions/{session_id} [delete]
+func RevokeSessionHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+	sessionID := c.Param("session_id")
+
+	// A sessão precisa pertencer à revenda do token
+	if _, err := utils.GetSession(c.Request.Context(), tokenInfo.MemberID, sessionID); err != nil {
+		if err == utils.ErrSessionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"erro": "Sessão não encontrada"})
+			return
+		}
+		log.Printf("❌ Erro ao buscar sessão %s da revenda %d: %v", sessionID, tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar sessão"})
+		return
+	}
+
+	if err := utils.RevokeSession(c.Request.Context(), tokenInfo.MemberID, sessionID); err != nil {
+		log.Printf("❌ Erro ao encerrar sessão %s da revenda %d: %v", sessionID, tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao encerrar sessão"})
+		return
+	}
+
+	saveAuthEvent("session_revoked", tokenInfo.MemberID, tokenInfo.MemberID, gin.H{"session_id": sessionID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sessão encerrada com sucesso"})
+}
+
+// RevokeAllSessionsHandler godoc
+// @Summary Encerrar Todas as Sessões
+// @Description Encerra todas as sessões da própria revenda. Com exceto_atual=true a sessão do token usado na requisição é mantida.
+// @Tags Sessões
+// @Security BearerAuth
+// @Produce json
+// @Param exceto_atual query bool false "Manter a sessão atual (padrão: false)"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"message\": \"Sessões encerradas com sucesso\", \"sessoes_encerradas\": 3}"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/sessions [delete]
+func RevokeAllSessionsHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	keepSessionID := ""
+	if keepCurrent, _ := strconv.ParseBool(c.DefaultQuery("exceto_atual", "false")); keepCurrent {
+		keepSessionID = tokenInfo.SessionID
+	}
+
+	revoked, err := utils.RevokeAllSessions(c.Request.Context(), tokenInfo.MemberID, keepSessionID)
+	if err != nil {
+		log.Printf("❌ Erro ao encerrar sessões da revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao encerrar sessões"})
+		return
+	}
+
+	saveAuthEvent("sessions_revoked", tokenInfo.MemberID, tokenInfo.MemberID, gin.H{"sessoes_encerradas": revoked, "exceto_atual": keepSessionID != ""})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Sessões encerradas com sucesso",
+		"sessoes_encerradas": revoked,
+	})
+}
+
+// ListResellerSessionsHandler godoc
+// @Summary Listar Sessões de uma Revenda
+// @Description Lista as sessões ativas de qualquer revenda. Exige a permissão sessions:admin.
+// @Tags Sessões
+// @Security BearerAuth
+// @Produce json
+// @Param member_id path int true "ID da revenda"
+// @Success 200 {object} map[string]interface{} "Lista de sessões da revenda"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 403 {object} map[string]string "Sem permissão"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/admin/resellers/{member_id}/sessions [get]
+func ListResellerSessionsHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.Atoi(c.Param("member_id"))
+	if err != nil || memberID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "ID da revenda inválido"})
+		return
+	}
+
+	sessions, err := utils.ListSessions(c.Request.Context(), memberID, tokenInfo.SessionID)
+	if err != nil {
+		log.Printf("❌ Erro ao listar sessões da revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao listar sessões"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"member_id": memberID,
+		"total":     len(sessions),
+		"sessoes":   sessions,
+	})
+}
+
+// ForceLogoutResellerHandler godoc
+// @Summary Forçar Logout de uma Revenda
+// @Description Encerra todas as sessões de qualquer revenda, ou apenas uma quando session_id é informado. Exige a permissão sessions:admin.
+// @Tags Sessões
+// @Security BearerAuth
+// @Produce json
+// @Param member_id path int true "ID da revenda"
+// @Param session_id query string false "Encerrar somente esta sessão"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"message\": \"Logout forçado com sucesso\", \"sessoes_encerradas\": 2}"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 403 {object} map[string]string "Sem permissão"
+// @Failure 404 {object} map[string]string "Sessão não encontrada"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/admin/resellers/{member_id}/sessions [delete]
+func ForceLogoutResellerHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.Atoi(c.Param("member_id"))
+	if err != nil || memberID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "ID da revenda inválido"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	revoked := 0
+	if sessionID := c.Query("session_id"); sessionID != "" {
+		if _, err := utils.GetSession(ctx, memberID, sessionID); err != nil {
+			if err == utils.ErrSessionNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"erro": "Sessão não encontrada"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar sessão"})
+			return
+		}
+		if err := utils.RevokeSession(ctx, memberID, sessionID); err != nil {
+			log.Printf("❌ Erro ao encerrar sessão %s da revenda %d: %v", sessionID, memberID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao encerrar sessão"})
+			return
+		}
+		revoked = 1
+	} else {
+		revoked, err = utils.RevokeAllSessions(ctx, memberID, "")
+		if err != nil {
+			log.Printf("❌ Erro ao forçar logout da revenda %d: %v", memberID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao encerrar sessões"})
+			return
+		}
+	}
+
+	saveAuthEvent("force_logout", memberID, tokenInfo.MemberID, gin.H{"sessoes_encerradas": revoked, "session_id": c.Query("session_id")})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Logout forçado com sucesso",
+		"member_id":          memberID,
+		"sessoes_encerradas": revoked,
+	})
+}
+
+// saveSessionEvent registra no MongoDB (api_logs.auth_events) o encerramento de sessões
+func saveAuthEvent(event string, memberID, adminID int, details gin.H) {
+	entry := gin.H{
+		"event":     event,
+		"member_id": memberID,
+		"admin_id":  adminID,
+		"details":   details,
+		"timestamp": time.Now(),
+	}
+	if err := utils.SaveToMongo("auth_events", entry); err != nil {
+		log.Printf("❌ Erro ao salvar evento de autenticação (%s) no MongoDB: %v", event, err)
+	}
+}
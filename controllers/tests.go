@@ -37,6 +37,7 @@ type TestRequest struct {
 // @Description Gera um usuário e senha de teste para IPTV e retorna as credenciais.
 // @Tags Testes IPTV
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Accept  json
 // @Produce  json
 // @Param test body controllers.TestRequest true "Dados para criação do teste"
@@ -58,11 +59,14 @@ type TestRequest struct {
 //	  "franquia_member_id": 456
 //	}
 //
+// @Param Idempotency-Key header string false "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem cobrar de novo"
 // @Success 200 {object} map[string]interface{} "Teste criado com sucesso"
 // @Failure 400 {object} map[string]string "Erro na requisição ou usuário já existe (com credenciais fornecidas)"
 // @Failure 401 {object} map[string]string "Token inválido"
 // @Failure 429 {object} map[string]string "Muitas tentativas de geração aleatória falharam"
 // @Failure 500 {object} map[string]string "Erro interno do servidor"
+// @Failure 409 {object} map[string]string "Idempotency-Key em processamento"
+// @Failure 422 {object} map[string]string "Idempotency-Key reutilizada com corpo diferente"
 // @Router /api/create-test [post]
 func CreateTest(c *gin.Context) {
 	ip := c.ClientIP()
@@ -78,14 +82,8 @@ func CreateTest(c *gin.Context) {
 	}
 
 	// **1️⃣ Autenticação obrigatória**
-	tokenString := c.GetHeader("Authorization")
-	if tokenString == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token não fornecido"})
-		return
-	}
-
 	// 📌 Ajuste para capturar corretamente os três valores retornados por `ValidateToken`
-	claims, _, err := utils.ValidateToken(tokenString)
+	claims, _, err := utils.RequestClaims(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token inválido"})
 		return
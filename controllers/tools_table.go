@@ -6,7 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math" // Mantido por enquanto, AddScreen usa math.Ceil
+	"math"
 	"net/http"
 	"os"
 	"strconv"
@@ -94,8 +94,7 @@ func EditUser(c *gin.Context) {
 	}
 
 	// Extrair member_id do token para validação de permissões
-	tokenString := c.GetHeader("Authorization")
-	claims, _, err := utils.ValidateToken(tokenString)
+	claims, _, err := utils.RequestClaims(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
 		return
@@ -103,7 +102,7 @@ func EditUser(c *gin.Context) {
 	memberID := int(claims["member_id"].(float64))
 
 	// Validar se o usuário tem permissão para editar este usuário
-	temPermissao, _, err := utils.VerificaPermissaoUsuario(userID, memberID)
+	temPermissao, _, err := utils.VerificaPermissaoUsuario(userID, memberID, utils.RoleFromClaims(claims))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
@@ -477,10 +476,14 @@ func saveAuditLogToMongo(c *gin.Context, targetUserID int, action string, oldDat
 // @Accept json
 // @Produce json
 // @Param body body models.ScreenRequest true "JSON contendo o ID do usuário"
+// @Param Idempotency-Key header string false "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem cobrar de novo"
 // @Success 200 {object} map[string]interface{} "Retorna o novo total de telas e o saldo de créditos atualizado"
-// @Failure 400 {object} map[string]string "Erro nos parâmetros ou créditos insuficientes"
+// @Failure 400 {object} map[string]string "Erro nos parâmetros"
 // @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 402 {object} map[string]interface{} "Créditos insuficientes (formato padrão: erro, codigo, creditos_disponiveis, creditos_necessarios)"
+// @Failure 409 {object} map[string]string "Telas alteradas em paralelo ou Idempotency-Key em processamento"
 // @Failure 500 {object} map[string]string "Erro interno ao adicionar tela"
+// @Failure 422 {object} map[string]string "Idempotency-Key reutilizada com corpo diferente"
 // @Router /api/tools-table/add-screen [post]
 func AddScreen(c *gin.Context) {
 	var req models.ScreenRequest
@@ -490,8 +493,7 @@ func AddScreen(c *gin.Context) {
 	}
 
 	// 📌 Extrair `member_id` do token
-	tokenString := c.GetHeader("Authorization")
-	claims, _, err := utils.ValidateToken(tokenString)
+	claims, _, err := utils.RequestClaims(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token inválido"})
 		return
@@ -508,7 +510,13 @@ func AddScreen(c *gin.Context) {
 	}
 
 	// 🔒 Garantir que o usuário pertence à revenda correta
-	if userMemberID != memberID {
+	permitido, err := utils.PodeGerenciarRevenda(memberID, userMemberID, utils.RoleFromClaims(claims))
+	if err != nil {
+		log.Printf("❌ Erro ao verificar hierarquia de revendas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar permissões"})
+		return
+	}
+	if !permitido {
 		log.Printf("🚨 ALERTA! Tentativa de alteração indevida! (Usuário: %d, Revenda Token: %d, Revenda Usuário: %d)", req.UserID, memberID, userMemberID)
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Usuário não pertence à sua revenda"})
 		return
@@ -523,7 +531,7 @@ func AddScreen(c *gin.Context) {
 		return
 	}
 
-	if totalTelas >= 3 {
+	if totalTelas >= maxTelasCliente {
 		c.JSON(http.StatusBadRequest, gin.H{"erro": "Limite máximo de telas atingido"})
 		return
 	}
@@ -537,41 +545,30 @@ func AddScreen(c *gin.Context) {
 		return
 	}
 
-	// 📌 Calcula o custo da nova tela com base nos dias restantes
+	// 📌 Calcula o custo da nova tela pelas regras de preço (dias restantes, telas, promoções)
 	diasRestantes := (expDate - time.Now().Unix()) / 86400
-	var valorCobrado float64
-
-	if diasRestantes <= 15 {
-		valorCobrado = 0.5 // Contas com menos de 15 dias pagam meio crédito
-	} else if diasRestantes > 30 {
-		valorCobrado = math.Ceil(float64(diasRestantes) / 30) // Divide total de dias por 30
-	} else {
-		valorCobrado = 1.0 // Contas normais (até 30 dias)
-	}
-
-	// 📌 Obtém créditos do **MEMBER_ID** na tabela `reg_users`
-	var creditosAtuais float64
-	err = config.DB.QueryRow("SELECT credits FROM reg_users WHERE id = ?", memberID).Scan(&creditosAtuais)
+	var isTrial int
+	if err := config.DB.QueryRow("SELECT is_trial FROM users WHERE id = ?", req.UserID).Scan(&isTrial); err != nil {
+		log.Printf("❌ ERRO ao buscar is_trial do usuário %d: %v", req.UserID, err)
+	}
+	orcamento, err := utils.QuotePrice(utils.PricingInput{
+		MemberID:       memberID,
+		Produto:        models.ProdutoTelaAdicional,
+		Telas:          totalTelas + 1,
+		DiasRestantes:  int(diasRestantes),
+		ConversaoTeste: isTrial == 1,
+	})
 	if err != nil {
-		log.Printf("❌ ERRO ao buscar créditos da revenda %d: %v", memberID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar créditos do revendedor"})
+		log.Printf("❌ ERRO ao calcular preço da tela adicional: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao calcular o custo da tela"})
 		return
 	}
+	valorCobrado := orcamento.Total
 
-	// 🔍 LOGS PARA DEBUG
-	log.Printf("🟢 DEBUG - Créditos da revenda %d obtidos com sucesso!", memberID)
-	log.Printf("🔹 Créditos antes da compra: %.2f", creditosAtuais)
 	log.Printf("🔹 Dias restantes para expiração: %d", diasRestantes)
 	log.Printf("🔹 Valor da tela a ser cobrado: %.2f", valorCobrado)
 
-	// 📌 Verifica se há créditos suficientes
-	if creditosAtuais < valorCobrado {
-		log.Println("❌ ERRO: Créditos insuficientes!")
-		c.JSON(http.StatusBadRequest, gin.H{"erro": "Créditos insuficientes"})
-		return
-	}
-
-	// 📌 Atualiza **os créditos da revenda** e aumenta telas do usuário
+	// 📌 Debita **os créditos da revenda** e aumenta telas do usuário na mesma transação
 	txCtx, err := config.DB.Begin()
 	if err != nil {
 		log.Printf("❌ ERRO ao iniciar transação: %v", err)
@@ -580,19 +577,33 @@ func AddScreen(c *gin.Context) {
 	}
 	defer txCtx.Rollback() // Defer Rollback after successful Begin
 
-	// 📌 Atualiza a quantidade de telas no usuário
-	_, err = txCtx.Exec("UPDATE users SET max_connections = max_connections + 1 WHERE id = ?", req.UserID)
+	// 📌 Debita os créditos (débito atômico: o saldo é verificado e descontado na mesma operação)
+	movimento, err := models.DebitCredits(txCtx, valorCobrado, models.CreditMovement{
+		MemberID:  memberID,
+		Operacao:  models.LedgerTelaAdicional,
+		UserID:    req.UserID,
+		ActorID:   memberID,
+		Descricao: fmt.Sprintf("Tela adicional (%d → %d telas, %d dias restantes)", totalTelas, totalTelas+1, diasRestantes),
+	})
 	if err != nil {
-		log.Printf("❌ ERRO ao atualizar telas do usuário %d: %v", req.UserID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao adicionar tela"})
+		if respondCreditosInsuficientes(c, err) {
+			log.Println("❌ ERRO: Créditos insuficientes!")
+			return
+		}
+		log.Printf("❌ ERRO ao atualizar créditos da revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao descontar créditos"})
 		return
 	}
 
-	// 📌 Atualiza os créditos na `reg_users`
-	_, err = txCtx.Exec("UPDATE reg_users SET credits = credits - ? WHERE id = ?", valorCobrado, memberID)
+	// 📌 Atualiza a quantidade de telas no usuário (condicional: requisições paralelas não passam do limite)
+	result, err := txCtx.Exec("UPDATE users SET max_connections = max_connections + 1 WHERE id = ? AND max_connections = ?", req.UserID, totalTelas)
 	if err != nil {
-		log.Printf("❌ ERRO ao atualizar créditos da revenda %d: %v", memberID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao descontar créditos"})
+		log.Printf("❌ ERRO ao atualizar telas do usuário %d: %v", req.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao adicionar tela"})
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusConflict, gin.H{"erro": "A quantidade de telas foi alterada por outra operação. Tente novamente."})
 		return
 	}
 
@@ -606,7 +617,8 @@ func AddScreen(c *gin.Context) {
 
 	// Recalcula valores finais para o log
 	totalTelasAtual := totalTelas + 1
-	creditosDepois := creditosAtuais - valorCobrado
+	creditosAtuais := movimento.SaldoAntes
+	creditosDepois := movimento.SaldoDepois
 
 	// Dados para o log de auditoria
 	newAuditData := map[string]interface{}{
@@ -621,23 +633,41 @@ func AddScreen(c *gin.Context) {
 		log.Printf("⚠️ AVISO: Falha ao salvar log de auditoria para add_screen do usuário %d: %v", req.UserID, err)
 	}
 
+	// 📌 Registro para desfazer a tela adicional com devolução dos créditos (/api/undo)
+	username, _ := claims["username"].(string)
+	operacaoID := recordUndo(c, models.UndoRecord{
+		Tipo:            models.UndoTelaAdicional,
+		UserID:          req.UserID,
+		MemberIDCobrado: memberID,
+		ActorID:         memberID,
+		ActorUsername:   username,
+		TelasAntes:      totalTelas,
+		TelasDepois:     totalTelasAtual,
+		Creditos:        valorCobrado,
+		Descricao:       fmt.Sprintf("Tela adicional (%d → %d telas)", totalTelas, totalTelasAtual),
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":         "Tela adicionada com sucesso!",
 		"total_telas":     totalTelasAtual,
 		"creditos_atuais": creditosDepois,
 		"valor_cobrado":   valorCobrado,
+		"detalhamento":    orcamento.Detalhamento,
+		"operacao_id":     operacaoID,
 	})
 }
 
 // @Summary Remove uma tela do usuário
-// @Description Diminui o número máximo de conexões do usuário, garantindo que tenha pelo menos uma tela ativa
+// @Description Diminui o número máximo de conexões do usuário, garantindo que tenha pelo menos uma tela ativa. Devolve créditos proporcionais aos dias restantes (mesmas regras de preço da tela adicional), limitados ao que a revenda pagou pela tela adicional mais recente ainda não devolvida (compras dos últimos TELA_REEMBOLSO_JANELA_DIAS dias; cada remoção consome uma compra); a devolução vai para a revenda que pagou e aparece no extrato como reembolso_tela.
 // @Tags ToolsTable
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param body body models.ScreenRequest true "JSON contendo o ID do usuário"
-// @Success 200 {object} map[string]interface{} "Retorna o novo total de telas"
+// @Param Idempotency-Key header string false "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem devolver de novo"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"sucesso\": \"Tela removida com sucesso\", \"total_telas\": 1, \"creditos_reembolsados\": 0.5, \"valor_proporcional\": 0.5, \"pago_pela_tela\": 1, \"janela_dias\": 30, \"creditos_atuais\": 10.5}"
 // @Failure 400 {object} map[string]string "Erro nos parâmetros ou limite mínimo atingido"
+// @Failure 409 {object} map[string]string "Telas alteradas por outra operação"
 // @Failure 401 {object} map[string]string "Token inválido"
 // @Failure 500 {object} map[string]string "Erro interno ao remover tela"
 // @Router /api/tools-table/remove-screen [post]
@@ -650,8 +680,7 @@ func RemoveScreen(c *gin.Context) {
 	}
 
 	// 📌 Extrair `member_id` do token para validação e log
-	tokenString := c.GetHeader("Authorization")
-	claims, _, err := utils.ValidateToken(tokenString)
+	claims, _, err := utils.RequestClaims(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Token inválido"})
 		return
@@ -671,7 +700,13 @@ func RemoveScreen(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar informações do usuário"})
 		return
 	}
-	if userMemberID != memberID {
+	permitido, err := utils.PodeGerenciarRevenda(memberID, userMemberID, utils.RoleFromClaims(claims))
+	if err != nil {
+		log.Printf("❌ Erro ao verificar hierarquia de revendas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar permissões"})
+		return
+	}
+	if !permitido {
 		log.Printf("🚨 ALERTA! Tentativa de remoção de tela indevida! (Usuário: %d, Revenda Token: %d, Revenda Usuário: %d)", req.UserID, memberID, userMemberID)
 		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Usuário não pertence à sua revenda"})
 		return
@@ -690,27 +725,112 @@ func RemoveScreen(c *gin.Context) {
 		return
 	}
 
-	// 📌 Atualiza banco de dados
-	_, err = config.DB.Exec("UPDATE users SET max_connections = max_connections - 1 WHERE id = ?", req.UserID)
+	// 📌 Valor proporcional da tela pelos dias restantes (mesmas regras de preço da tela adicional)
+	var expDate sql.NullInt64
+	var isTrial int
+	if err := config.DB.QueryRow("SELECT exp_date, is_trial FROM users WHERE id = ?", req.UserID).Scan(&expDate, &isTrial); err != nil {
+		log.Printf("Erro ao buscar vencimento do usuário %d: %v", req.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar informações do usuário"})
+		return
+	}
+	diasRestantes := (expDate.Int64 - time.Now().Unix()) / 86400
+	var proporcional float64
+	var detalhamento []models.PriceQuoteLine
+	if diasRestantes > 0 {
+		orcamento, err := utils.QuotePrice(utils.PricingInput{
+			MemberID:       memberID,
+			Produto:        models.ProdutoTelaAdicional,
+			Telas:          totalTelas,
+			DiasRestantes:  int(diasRestantes),
+			ConversaoTeste: isTrial == 1,
+		})
+		if err != nil {
+			log.Printf("❌ ERRO ao calcular valor proporcional da tela: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao calcular a devolução da tela"})
+			return
+		}
+		proporcional = orcamento.Total
+		detalhamento = orcamento.Detalhamento
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao remover tela"})
+		return
+	}
+	defer tx.Rollback()
+
+	// 📌 Atualiza banco de dados (condicional: a linha do cliente fica travada até o fim da transação)
+	result, err := tx.Exec("UPDATE users SET max_connections = max_connections - 1 WHERE id = ? AND max_connections = ?", req.UserID, totalTelas)
 	if err != nil {
 		log.Printf("Erro ao remover tela para usuário %d: %v", req.UserID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao remover tela"})
 		return
 	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusConflict, gin.H{"erro": "A quantidade de telas foi alterada por outra operação. Tente novamente."})
+		return
+	}
+
+	// 📌 Devolução: proporcional aos dias restantes, limitada ao que foi pago pela última tela ainda não devolvida
+	janelaDias := utils.GetTelaReembolsoJanelaDias()
+	revendaPagadora, pagoPelaTela, err := models.ScreenRefundCap(tx, req.UserID, time.Now().AddDate(0, 0, -janelaDias).Unix())
+	if err != nil {
+		log.Printf("❌ ERRO ao consultar compras de tela do usuário %d: %v", req.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao calcular a devolução da tela"})
+		return
+	}
+	reembolso := math.Min(proporcional, pagoPelaTela)
+	var creditosAtuais *float64
+	if reembolso > 0 {
+		movimento, err := models.ApplyCreditMovement(tx, models.CreditMovement{
+			MemberID:  revendaPagadora,
+			Operacao:  models.LedgerReembolsoTela,
+			UserID:    req.UserID,
+			Valor:     reembolso,
+			ActorID:   memberID,
+			Descricao: fmt.Sprintf("Devolução de tela removida (%d → %d telas, %d dias restantes)", totalTelas, totalTelas-1, diasRestantes),
+		})
+		if err != nil {
+			log.Printf("❌ ERRO ao devolver créditos da tela para a revenda %d: %v", revendaPagadora, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao devolver créditos"})
+			return
+		}
+		if revendaPagadora == memberID {
+			creditosAtuais = &movimento.SaldoDepois
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("❌ ERRO ao confirmar remoção de tela do usuário %d: %v", req.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao remover tela"})
+		return
+	}
 
 	// Log de auditoria para RemoveScreen
 	newAuditData := map[string]interface{}{
-		"total_telas_antes": totalTelas,
-		"total_telas_atual": totalTelas - 1,
+		"total_telas_antes":     totalTelas,
+		"total_telas_atual":     totalTelas - 1,
+		"creditos_reembolsados": reembolso,
+		"revenda_reembolsada":   revendaPagadora,
 	}
 	if auditErr := saveAuditLogToMongo(c, req.UserID, "remove_screen", nil, newAuditData); auditErr != nil {
 		log.Printf("Erro ao salvar log de auditoria para remove_screen (usuário %d): %v", req.UserID, auditErr)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"sucesso":     "Tela removida com sucesso",
-		"total_telas": totalTelas - 1,
-	})
+	resp := gin.H{
+		"sucesso":               "Tela removida com sucesso",
+		"total_telas":           totalTelas - 1,
+		"creditos_reembolsados": reembolso,
+		"valor_proporcional":    proporcional,
+		"pago_pela_tela":        pagoPelaTela,
+		"janela_dias":           janelaDias,
+		"detalhamento":          detalhamento,
+	}
+	if creditosAtuais != nil {
+		resp["creditos_atuais"] = *creditosAtuais
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 func getUserDataForAudit(userID int) (map[string]interface{}, error) {
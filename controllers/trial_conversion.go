@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"apiBackEnd/utils"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Conversão de teste em cliente pago
+//
+// A conversão cobra como uma renovação de teste (ConversaoTeste nas regras de preço) e define o
+// vencimento por uma política explícita:
+//
+//	agora  o período pago começa agora; as horas de teste restantes são descartadas
+//	somar  o período pago é somado ao vencimento do teste (as horas restantes são mantidas)
+//
+// CONVERSAO_TESTE_POLITICA define a política padrão (agora). Telas e bouquet podem ser alterados no
+// mesmo passo. Cada conversão é gravada na coleção `renew` do MongoDB com origem "conversao_teste"
+// e no extrato de créditos com a operação conversao_teste, para os relatórios de conversão.
+
+const (
+	ConversaoPoliticaAgora = "agora"
+	ConversaoPoliticaSomar = "somar"
+)
+
+// trialConversionOrigem identifica a conversão na coleção `renew` do MongoDB
+const trialConversionOrigem = "conversao_teste"
+
+// ConvertTrialRequest são os dados da conversão. Informe apenas um período, como na renovação.
+type ConvertTrialRequest struct {
+	QuantidadeMeses int    `json:"quantidade_renovacao_em_meses"`
+	QuantidadeDias  int    `json:"quantidade_dias"`
+	DataAlvo        string `json:"data_alvo"`
+	Politica        string `json:"politica,omitempty"` // agora | somar; padrão CONVERSAO_TESTE_POLITICA
+	Telas           int    `json:"telas,omitempty"`    // Novo total de telas (0 = mantém)
+	Bouquet         string `json:"bouquet,omitempty"`  // Novo bouquet, ex.: "[1, 5, 10]" (vazio = mantém)
+}
+
+// getConversaoPolitica retorna a política padrão de conversão (CONVERSAO_TESTE_POLITICA, padrão agora)
+func getConversaoPolitica() string {
+	if strings.ToLower(strings.TrimSpace(os.Getenv("CONVERSAO_TESTE_POLITICA"))) == ConversaoPoliticaSomar {
+		return ConversaoPoliticaSomar
+	}
+	return ConversaoPoliticaAgora
+}
+
+// ConvertTrialHandler converte um teste em cliente pago.
+//
+// @Summary Converter Teste em Cliente Pago
+// @Description Converte um teste em cliente pago, cobrando pelas regras de preço de conversão de teste. A política define o vencimento: "agora" (o período começa agora e as horas de teste restantes são descartadas) ou "somar" (o período é somado ao vencimento do teste); o padrão vem de CONVERSAO_TESTE_POLITICA. Telas e bouquet podem ser alterados na mesma operação. Cada conversão é registrada como evento próprio (origem conversao_teste no MongoDB e operação conversao_teste no extrato).
+// @Tags Renovação
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do cliente (teste)"
+// @Param body body controllers.ConvertTrialRequest true "Período, política, telas e bouquet"
+// @Param Idempotency-Key header string false "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem cobrar de novo"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"id_cliente\": 10, \"politica\": \"agora\", \"novo_exp_date\": 1719700000, \"horas_teste_descartadas\": 3, \"telas\": 2, \"creditos_gastos\": 2, \"creditos_restantes\": 8}"
+// @Failure 400 {object} map[string]string "Dados inválidos"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 402 {object} map[string]interface{} "Créditos insuficientes (formato padrão: erro, codigo, creditos_disponiveis, creditos_necessarios)"
+// @Failure 403 {object} map[string]string "Cliente não pertence à revenda"
+// @Failure 404 {object} map[string]string "Cliente não encontrado"
+// @Failure 409 {object} map[string]string "Cliente não é teste (ou já foi convertido)"
+// @Failure 422 {object} map[string]string "Idempotency-Key reutilizada com corpo diferente"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/clients/{id}/convert [post]
+func ConvertTrialHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+	memberID := tokenInfo.MemberID
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "ID de usuário inválido"})
+		return
+	}
+
+	var req ConvertTrialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos"})
+		return
+	}
+	periodo, err := parseRenewalPeriod(req.QuantidadeMeses, req.QuantidadeDias, req.DataAlvo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+	politica := strings.ToLower(strings.TrimSpace(req.Politica))
+	if politica == "" {
+		politica = getConversaoPolitica()
+	}
+	if politica != ConversaoPoliticaAgora && politica != ConversaoPoliticaSomar {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "politica deve ser 'agora' ou 'somar'"})
+		return
+	}
+	if req.Telas < 0 || req.Telas > maxTelasCliente {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": fmt.Sprintf("telas deve estar entre 1 e %d", maxTelasCliente)})
+		return
+	}
+	var bouquet string
+	if strings.TrimSpace(req.Bouquet) != "" {
+		if bouquet, err = normalizeBouquet(req.Bouquet); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+			return
+		}
+	}
+
+	permitido, _, err := utils.VerificaPermissaoUsuario(userID, memberID, tokenInfo.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"erro": "Usuário não encontrado"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar permissões"})
+		return
+	}
+	if !permitido {
+		c.JSON(http.StatusForbidden, gin.H{"erro": "Usuário não pertence à sua revenda"})
+		return
+	}
+
+	var expDate sql.NullInt64
+	var telasAntes, isTrial int
+	err = config.DB.QueryRow("SELECT exp_date, max_connections, is_trial FROM streamcreed_db.users WHERE id = ?", userID).Scan(&expDate, &telasAntes, &isTrial)
+	if err != nil {
+		log.Printf("❌ Erro ao buscar teste %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar cliente"})
+		return
+	}
+	if isTrial != 1 {
+		c.JSON(http.StatusConflict, gin.H{"erro": "Cliente não é um teste. Use /api/renew para renovar."})
+		return
+	}
+	telas := telasAntes
+	if req.Telas > 0 {
+		telas = req.Telas
+	}
+
+	// 🔹 Base do período pela política: agora ou o vencimento do teste (se ainda não venceu)
+	agora := time.Now()
+	var segundosRestantes int64
+	if expDate.Valid && expDate.Int64 > agora.Unix() {
+		segundosRestantes = expDate.Int64 - agora.Unix()
+	}
+	base := agora
+	if politica == ConversaoPoliticaSomar && segundosRestantes > 0 {
+		base = time.Unix(expDate.Int64, 0)
+	}
+	novoExp, dias, err := periodo.resolve(base)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	orcamento, err := utils.QuotePrice(utils.PricingInput{
+		MemberID:       memberID,
+		Produto:        models.ProdutoRenovacao,
+		Meses:          periodo.Meses,
+		Dias:           dias,
+		Telas:          telas,
+		ConversaoTeste: true,
+	})
+	if err != nil {
+		log.Printf("❌ Erro ao calcular preço da conversão do teste %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao calcular o custo da conversão"})
+		return
+	}
+	descricao := fmt.Sprintf("Conversão de teste: %d mês(es) x %d tela(s)", periodo.Meses, telas)
+	if dias > 0 {
+		descricao = fmt.Sprintf("Conversão de teste: %d dia(s) x %d tela(s)", dias, telas)
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao iniciar transação"})
+		return
+	}
+	defer tx.Rollback()
+
+	movimento, err := models.DebitCredits(tx, orcamento.Total, models.CreditMovement{
+		MemberID:  memberID,
+		Operacao:  models.LedgerConversaoTeste,
+		UserID:    userID,
+		ActorID:   memberID,
+		Descricao: descricao,
+	})
+	if err != nil {
+		if respondCreditosInsuficientes(c, err) {
+			return
+		}
+		log.Printf("❌ Erro ao debitar créditos da conversão do teste %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Não foi possível debitar os créditos"})
+		return
+	}
+
+	// Condicional: duas conversões simultâneas do mesmo teste não cobram duas vezes
+	query := "UPDATE streamcreed_db.users SET exp_date = ?, max_connections = ?, is_trial = '0'"
+	args := []interface{}{novoExp.Unix(), telas}
+	if bouquet != "" {
+		query += ", bouquet = ?"
+		args = append(args, bouquet)
+	}
+	query += " WHERE id = ? AND is_trial = '1'"
+	args = append(args, userID)
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		log.Printf("❌ Erro ao converter teste %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao converter teste"})
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusConflict, gin.H{"erro": "Cliente não é um teste. Use /api/renew para renovar."})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("❌ Erro ao finalizar conversão do teste %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao finalizar transação"})
+		return
+	}
+
+	horasRestantes := math.Round(float64(segundosRestantes)/3600*100) / 100
+	horasDescartadas := 0.0
+	if politica == ConversaoPoliticaAgora {
+		horasDescartadas = horasRestantes
+	}
+	log.Printf("✅ Teste %d convertido pela revenda %d (política %s, %d tela(s), %.2f créditos)", userID, memberID, politica, telas, orcamento.Total)
+
+	// 🔹 Evento próprio de conversão (mesma coleção das renovações, com origem conversao_teste)
+	extra := bson.M{
+		"origem":                  trialConversionOrigem,
+		"politica":                politica,
+		"horas_teste_restantes":   horasRestantes,
+		"horas_teste_descartadas": horasDescartadas,
+		"telas_antes":             telasAntes,
+		"telas":                   telas,
+		"executado_por":           tokenInfo.Username,
+	}
+	if bouquet != "" {
+		extra["bouquet"] = bouquet
+	}
+	saveRenewLog(memberID, userID, expDate.Int64, novoExp.Unix(), orcamento.Total, extra)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":                  "Teste convertido com sucesso",
+		"id_cliente":              userID,
+		"politica":                politica,
+		"novo_exp_date":           novoExp.Unix(),
+		"horas_teste_descartadas": horasDescartadas,
+		"telas":                   telas,
+		"creditos_gastos":         orcamento.Total,
+		"detalhamento_preco":      orcamento.Detalhamento,
+		"creditos_restantes":      movimento.SaldoDepois,
+	})
+}
@@ -0,0 +1,398 @@
+package controllers
+
+import (
+	"apiBackEnd/models"
+	"apiBackEnd/utils"
+	"database/sql"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// verifySecondFactor confere um código TOTP ou, se permitido, um código de recuperação.
+// Retorna o método aceito ("totp" ou "recovery") ou "" quando o código é inválido.
+// Códigos aceitos são consumidos: o mesmo TOTP ou código de recuperação não vale duas vezes.
+func verifySecondFactor(tf *models.TwoFactor, code string, allowRecovery bool) (string, error) {
+	secret, err := utils.DecryptTOTPSecret(tf.SecretEnc)
+	if err != nil {
+		return "", err
+	}
+
+	if valid, step := utils.ValidateTOTP(secret, code, tf.LastUsedStep); valid {
+		consumed, err := models.ConsumeTwoFactorStep(tf.MemberID, step)
+		if err != nil || !consumed {
+			return "", err
+		}
+		return "totp", nil
+	}
+
+	if !allowRecovery || len(tf.RecoveryCodes) == 0 {
+		return "", nil
+	}
+	hash := utils.HashRecoveryCode(code)
+	for i, stored := range tf.RecoveryCodes {
+		if stored != hash {
+			continue
+		}
+		remaining := make([]string, 0, len(tf.RecoveryCodes)-1)
+		remaining = append(remaining, tf.RecoveryCodes[:i]...)
+		remaining = append(remaining, tf.RecoveryCodes[i+1:]...)
+		replaced, err := models.ReplaceRecoveryCodes(tf.MemberID, tf.RecoveryCodes, remaining)
+		if err != nil || !replaced {
+			return "", err
+		}
+		return "recovery", nil
+	}
+	return "", nil
+}
+
+// LoginTwoFactor godoc
+// @Summary Login - Segunda Etapa (2FA)
+// @Description Conclui o login de uma revenda com 2FA ativo. Recebe o challenge_id devolvido por /login e um código TOTP de 6 dígitos ou um código de recuperação. Cada desafio aceita até 5 códigos errados.
+// @Tags Autenticação
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorLoginPayload true "Desafio e código"
+// @Success 200 {object} LoginResponse "Login realizado com sucesso"
+// @Failure 400 {object} map[string]string "Erro na requisição"
+// @Failure 401 {object} map[string]string "Desafio inválido/expirado ou código incorreto"
+// @Failure 429 {object} map[string]string "Muitas tentativas; aguarde o tempo indicado no header Retry-After"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /login/2fa [post]
+func LoginTwoFactor(c *gin.Context) {
+	var req models.TwoFactorLoginPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos"})
+		return
+	}
+	ctx := c.Request.Context()
+
+	memberID, err := utils.GetLoginChallenge(ctx, req.ChallengeID)
+	if err != nil {
+		if err != utils.ErrChallengeNotFound {
+			log.Printf("ERRO: Falha ao buscar desafio 2FA: %v", err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Desafio inválido ou expirado. Faça login novamente."})
+		return
+	}
+
+	user, err := models.GetUserByID(memberID)
+	if err != nil || user.Status != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Conta bloqueada ou inexistente. Entre em contato com o suporte."})
+		return
+	}
+	if rejectLockedLogin(c, user.Username) {
+		return
+	}
+
+	tf, err := models.GetTwoFactor(memberID)
+	if err != nil || !tf.Enabled {
+		if err != nil && err != sql.ErrNoRows {
+			log.Printf("ERRO: Falha ao carregar 2FA da revenda %d: %v", memberID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar autenticação em duas etapas"})
+			return
+		}
+		// 2FA desativado depois do desafio: exige novo login
+		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Desafio inválido ou expirado. Faça login novamente."})
+		return
+	}
+
+	method, err := verifySecondFactor(tf, req.Code, true)
+	if err != nil {
+		log.Printf("ERRO: Falha ao verificar código 2FA da revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar código"})
+		return
+	}
+	if method == "" {
+		remaining, err := utils.RegisterChallengeFailure(ctx, req.ChallengeID)
+		if err != nil {
+			log.Printf("ERRO: Falha ao registrar tentativa 2FA da revenda %d: %v", memberID, err)
+		}
+		saveAuthEvent("2fa_failed", memberID, memberID, gin.H{"ip": c.ClientIP(), "tentativas_restantes": remaining})
+		if remaining == 0 {
+			respondLoginFailure(c, user.Username, "Código incorreto. Limite de tentativas atingido; faça login novamente.")
+			return
+		}
+		respondLoginFailure(c, user.Username, "Código incorreto")
+		return
+	}
+
+	consumed, err := utils.ConsumeLoginChallenge(ctx, req.ChallengeID)
+	if err != nil || !consumed {
+		c.JSON(http.StatusUnauthorized, gin.H{"erro": "Desafio inválido ou expirado. Faça login novamente."})
+		return
+	}
+	if method == "recovery" {
+		log.Printf("AVISO: Revenda %d entrou com código de recuperação (%d restantes)", memberID, len(tf.RecoveryCodes)-1)
+		saveAuthEvent("2fa_recovery_used", memberID, memberID, gin.H{"ip": c.ClientIP(), "codigos_restantes": len(tf.RecoveryCodes) - 1})
+	}
+
+	issueLoginTokens(c, user)
+}
+
+// TwoFactorStatusHandler godoc
+// @Summary Status do 2FA
+// @Description Informa se a revenda tem 2FA ativo e quantos códigos de recuperação ainda restam.
+// @Tags Autenticação em Duas Etapas
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"enabled\": true, \"codigos_recuperacao_restantes\": 8}"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/2fa [get]
+func TwoFactorStatusHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	tf, err := models.GetTwoFactor(tokenInfo.MemberID)
+	if err == sql.ErrNoRows || (err == nil && !tf.Enabled) {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Erro ao consultar 2FA da revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao consultar 2FA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                       true,
+		"ativado_em":                    tf.EnabledAt,
+		"codigos_recuperacao_restantes": len(tf.RecoveryCodes),
+	})
+}
+
+// TwoFactorSetupHandler godoc
+// @Summary Iniciar Cadastro do 2FA
+// @Description Gera um novo segredo TOTP e a URI otpauth:// para o QR code. O 2FA só passa a valer após a confirmação em /api/2fa/enable.
+// @Tags Autenticação em Duas Etapas
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"secret\": \"JBSWY3DPEHPK3PXP...\", \"otpauth_uri\": \"otpauth://totp/SmartOffice:revenda?secret=...\"}"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 409 {object} map[string]string "2FA já está ativo"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/2fa/setup [post]
+func TwoFactorSetupHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	enabled, err := models.IsTwoFactorEnabled(tokenInfo.MemberID)
+	if err != nil {
+		log.Printf("❌ Erro ao consultar 2FA da revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao consultar 2FA"})
+		return
+	}
+	if enabled {
+		c.JSON(http.StatusConflict, gin.H{"erro": "2FA já está ativo. Desative-o antes de cadastrar um novo dispositivo."})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar segredo"})
+		return
+	}
+	secretEnc, err := utils.EncryptTOTPSecret(secret)
+	if err != nil {
+		log.Printf("❌ Erro ao cifrar segredo TOTP: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar segredo"})
+		return
+	}
+	if err := models.SavePendingTwoFactor(tokenInfo.MemberID, secretEnc); err != nil {
+		log.Printf("❌ Erro ao salvar segredo TOTP da revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao salvar segredo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": utils.TOTPURI(tokenInfo.Username, secret),
+		"message":     "Escaneie o QR code no app autenticador e confirme com um código em /api/2fa/enable",
+	})
+}
+
+// TwoFactorEnableHandler godoc
+// @Summary Ativar 2FA
+// @Description Confirma o cadastro com um código TOTP válido e ativa o 2FA. Retorna os códigos de recuperação, exibidos apenas uma vez.
+// @Tags Autenticação em Duas Etapas
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorCodePayload true "Código TOTP atual"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"message\": \"2FA ativado com sucesso\", \"codigos_recuperacao\": [\"abcd-efgh\"]}"
+// @Failure 400 {object} map[string]string "Código inválido ou cadastro não iniciado"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 409 {object} map[string]string "2FA já está ativo"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/2fa/enable [post]
+func TwoFactorEnableHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+	var req models.TwoFactorCodePayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos"})
+		return
+	}
+
+	tf, err := models.GetTwoFactor(tokenInfo.MemberID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Cadastro do 2FA não iniciado. Use /api/2fa/setup."})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Erro ao consultar 2FA da revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao consultar 2FA"})
+		return
+	}
+	if tf.Enabled {
+		c.JSON(http.StatusConflict, gin.H{"erro": "2FA já está ativo"})
+		return
+	}
+
+	secret, err := utils.DecryptTOTPSecret(tf.SecretEnc)
+	if err != nil {
+		log.Printf("❌ Erro ao decifrar segredo TOTP da revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar código"})
+		return
+	}
+	valid, step := utils.ValidateTOTP(secret, req.Code, tf.LastUsedStep)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Código inválido. Confira o horário do dispositivo e tente novamente."})
+		return
+	}
+
+	codes, hashes, err := utils.GenerateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar códigos de recuperação"})
+		return
+	}
+	if err := models.EnableTwoFactor(tokenInfo.MemberID, hashes, step); err != nil {
+		log.Printf("❌ Erro ao ativar 2FA da revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao ativar 2FA"})
+		return
+	}
+
+	saveAuthEvent("2fa_enabled", tokenInfo.MemberID, tokenInfo.MemberID, gin.H{"ip": c.ClientIP()})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":             "2FA ativado com sucesso. Guarde os códigos de recuperação em local seguro: eles não serão exibidos novamente.",
+		"codigos_recuperacao": codes,
+	})
+}
+
+// TwoFactorDisableHandler godoc
+// @Summary Desativar 2FA
+// @Description Desativa o 2FA mediante um código TOTP ou de recuperação válido.
+// @Tags Autenticação em Duas Etapas
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorCodePayload true "Código TOTP ou de recuperação"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"message\": \"2FA desativado com sucesso\"}"
+// @Failure 400 {object} map[string]string "Código inválido ou 2FA inativo"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/2fa/disable [post]
+func TwoFactorDisableHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+	tf, ok := loadEnabledTwoFactor(c, tokenInfo.MemberID)
+	if !ok {
+		return
+	}
+
+	if err := models.DeleteTwoFactor(tokenInfo.MemberID); err != nil {
+		log.Printf("❌ Erro ao desativar 2FA da revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao desativar 2FA"})
+		return
+	}
+
+	saveAuthEvent("2fa_disabled", tf.MemberID, tokenInfo.MemberID, gin.H{"ip": c.ClientIP()})
+
+	c.JSON(http.StatusOK, gin.H{"message": "2FA desativado com sucesso"})
+}
+
+// TwoFactorRecoveryCodesHandler godoc
+// @Summary Gerar Novos Códigos de Recuperação
+// @Description Invalida os códigos de recuperação atuais e gera uma nova lista, mediante um código TOTP ou de recuperação válido.
+// @Tags Autenticação em Duas Etapas
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorCodePayload true "Código TOTP ou de recuperação"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"codigos_recuperacao\": [\"abcd-efgh\"]}"
+// @Failure 400 {object} map[string]string "Código inválido ou 2FA inativo"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/2fa/recovery-codes [post]
+func TwoFactorRecoveryCodesHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+	if _, ok := loadEnabledTwoFactor(c, tokenInfo.MemberID); !ok {
+		return
+	}
+
+	codes, hashes, err := utils.GenerateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar códigos de recuperação"})
+		return
+	}
+	if _, err := models.ReplaceRecoveryCodes(tokenInfo.MemberID, nil, hashes); err != nil {
+		log.Printf("❌ Erro ao salvar códigos de recuperação da revenda %d: %v", tokenInfo.MemberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao salvar códigos de recuperação"})
+		return
+	}
+
+	saveAuthEvent("2fa_recovery_regenerated", tokenInfo.MemberID, tokenInfo.MemberID, gin.H{"ip": c.ClientIP()})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":             "Novos códigos gerados. Os anteriores deixaram de valer.",
+		"codigos_recuperacao": codes,
+	})
+}
+
+// loadEnabledTwoFactor lê o corpo (código), carrega o 2FA ativo da revenda e confere o código.
+// Em caso de falha já responde à requisição e retorna ok=false.
+func loadEnabledTwoFactor(c *gin.Context, memberID int) (*models.TwoFactor, bool) {
+	var req models.TwoFactorCodePayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos"})
+		return nil, false
+	}
+
+	tf, err := models.GetTwoFactor(memberID)
+	if err == sql.ErrNoRows || (err == nil && !tf.Enabled) {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "2FA não está ativo"})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("❌ Erro ao consultar 2FA da revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao consultar 2FA"})
+		return nil, false
+	}
+
+	method, err := verifySecondFactor(tf, strings.TrimSpace(req.Code), true)
+	if err != nil {
+		log.Printf("❌ Erro ao verificar código 2FA da revenda %d: %v", memberID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar código"})
+		return nil, false
+	}
+	if method == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Código inválido"})
+		return nil, false
+	}
+	return tf, true
+}
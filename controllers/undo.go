@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"apiBackEnd/utils"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordUndo grava o registro para desfazer a operação e retorna o ID ("" se não foi gravado)
+func recordUndo(ctx context.Context, rec models.UndoRecord) string {
+	id, err := utils.SaveUndoRecord(ctx, &rec)
+	if err != nil {
+		log.Printf("❌ Erro ao salvar registro para desfazer %s do cliente %d: %v", rec.Tipo, rec.UserID, err)
+		return ""
+	}
+	return id
+}
+
+// ListUndoHandler godoc
+// @Summary Operações que Podem Ser Desfeitas
+// @Description Lista as operações do cliente (renovação, tela adicional, alteração de vencimento, bônus de confiança) que ainda estão dentro do prazo de ROLLBACK_PERMITIDO_DIAS, da mais recente para a mais antiga.
+// @Tags Ações
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param user_id query int true "ID do cliente"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"user_id\": 123, \"reversao_bloqueada\": false, \"operacoes\": [{\"id\": \"9f2c...\", \"tipo\": \"tela_adicional\", \"user_id\": 123, \"telas_antes\": 1, \"telas_depois\": 2, \"creditos\": 1, \"criado_em\": \"2024-06-01T10:00:00-03:00\", \"expira_em\": \"2024-06-03T10:00:00-03:00\"}]}"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 403 {object} map[string]string "Cliente não pertence à revenda"
+// @Failure 404 {object} map[string]string "Cliente não encontrado"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/undo [get]
+func ListUndoHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Query("user_id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "user_id inválido"})
+		return
+	}
+	permitido, _, err := utils.VerificaPermissaoUsuario(userID, tokenInfo.MemberID, tokenInfo.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"erro": "Usuário não encontrado"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar permissões"})
+		return
+	}
+	if !permitido {
+		c.JSON(http.StatusForbidden, gin.H{"erro": "Usuário não pertence à sua revenda"})
+		return
+	}
+
+	operacoes, err := utils.ListUndoRecords(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("❌ Erro ao listar operações para desfazer do cliente %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao listar operações"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":            userID,
+		"reversao_bloqueada": utils.RollbackLocked(c.Request.Context(), userID),
+		"operacoes":          operacoes,
+	})
+}
+
+// UndoOperationHandler godoc
+// @Summary Desfazer Operação
+// @Description Desfaz uma renovação, tela adicional, alteração de vencimento ou bônus de confiança: restaura o vencimento ou as telas anteriores e devolve os créditos cobrados à revenda que pagou. Vale dentro de ROLLBACK_PERMITIDO_DIAS após a operação e uma vez a cada ROLLBACK_PERMITIDO_FREQUENCIA dias por cliente (limite compartilhado com /api/renew-rollback). Se o cliente foi alterado por outra operação depois desta, desfaça a mais recente primeiro.
+// @Tags Ações
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param operacao_id path string true "ID da operação (operacao_id retornado pela operação ou listado em /api/undo)"
+// @Param body body models.UndoRequest false "Motivo (opcional)"
+// @Success 200 {object} map[string]interface{} "Exemplo: {\"sucesso\": true, \"operacao_id\": \"9f2c...\", \"tipo\": \"tela_adicional\", \"creditos_devolvidos\": 1, \"telas_restauradas\": 1}"
+// @Failure 400 {object} map[string]string "Reversão bloqueada pela frequência"
+// @Failure 401 {object} map[string]string "Token inválido"
+// @Failure 403 {object} map[string]string "Cliente não pertence à revenda"
+// @Failure 404 {object} map[string]string "Operação não encontrada ou prazo expirado"
+// @Failure 409 {object} map[string]string "O cliente foi alterado depois da operação"
+// @Failure 500 {object} map[string]string "Erro interno"
+// @Router /api/undo/{operacao_id} [post]
+func UndoOperationHandler(c *gin.Context) {
+	tokenInfo, ok := utils.ValidateAndExtractToken(c)
+	if !ok {
+		return
+	}
+	var req models.UndoRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos"})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	operacaoID := c.Param("operacao_id")
+	rec, err := utils.GetUndoRecord(ctx, operacaoID)
+	if err != nil {
+		if err == utils.ErrUndoNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"erro": "Operação não encontrada ou o prazo para desfazê-la expirou."})
+			return
+		}
+		log.Printf("❌ Erro ao ler operação %s para desfazer: %v", operacaoID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar operação"})
+		return
+	}
+
+	permitido, _, err := utils.VerificaPermissaoUsuario(rec.UserID, tokenInfo.MemberID, tokenInfo.Role)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar permissões"})
+		return
+	}
+	if !permitido {
+		// Mesmo 404 de operação inexistente: não revela operações de outras revendas
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Operação não encontrada ou o prazo para desfazê-la expirou."})
+		return
+	}
+
+	if utils.RollbackLocked(ctx, rec.UserID) {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Reversão já realizada recentemente para esta conta. Só é permitida uma reversão a cada " + strconv.Itoa(utils.GetRollbackPermitidoFrequencia()) + " dias."})
+		return
+	}
+
+	// Consome o registro antes de aplicar: duas requisições simultâneas não desfazem duas vezes
+	rec, err = utils.ClaimUndoRecord(ctx, operacaoID)
+	if err != nil {
+		if err == utils.ErrUndoNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"erro": "Operação não encontrada ou o prazo para desfazê-la expirou."})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar operação"})
+		return
+	}
+
+	status, body := applyUndo(tokenInfo, rec)
+	if status != http.StatusOK {
+		if err := utils.RestoreUndoRecord(context.Background(), rec); err != nil {
+			log.Printf("❌ Erro ao devolver registro %s após falha ao desfazer: %v", rec.ID, err)
+		}
+		c.JSON(status, body)
+		return
+	}
+
+	if err := utils.LockRollback(ctx, rec.UserID); err != nil {
+		log.Printf("❌ Erro ao bloquear nova reversão do cliente %d: %v", rec.UserID, err)
+	}
+	utils.DeleteUndoRecord(ctx, rec.UserID, rec.ID)
+	cleanupAfterUndo(ctx, rec)
+
+	_ = utils.SaveActionLog(rec.UserID, "undo_operation", gin.H{
+		"operacao": rec,
+		"motivo":   req.Motivo,
+	}, tokenInfo.Username)
+
+	c.JSON(http.StatusOK, body)
+}
+
+// applyUndo aplica a compensação e a devolução de créditos em uma transação
+func applyUndo(tokenInfo *utils.TokenInfo, rec *models.UndoRecord) (int, gin.H) {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return http.StatusInternalServerError, gin.H{"erro": "Erro ao iniciar transação"}
+	}
+	defer tx.Rollback()
+
+	body := gin.H{"sucesso": true, "operacao_id": rec.ID, "tipo": rec.Tipo, "creditos_devolvidos": rec.Creditos}
+	var result sql.Result
+	ledgerOp := models.LedgerReversaoRenovacao
+	switch rec.Tipo {
+	case models.UndoTelaAdicional:
+		// Só desfaz se as telas ainda estiverem como a operação deixou
+		result, err = tx.Exec("UPDATE streamcreed_db.users SET max_connections = ? WHERE id = ? AND max_connections = ?", rec.TelasAntes, rec.UserID, rec.TelasDepois)
+		ledgerOp = models.LedgerReversaoTelaAdicional
+		body["telas_restauradas"] = rec.TelasAntes
+	case models.UndoRenovacao, models.UndoAlteracaoVencimento, models.UndoBonusConfianca:
+		// Só desfaz se o vencimento ainda for o que a operação gravou
+		result, err = tx.Exec("UPDATE streamcreed_db.users SET exp_date = ? WHERE id = ? AND exp_date = ?", rec.ExpDateAntes, rec.UserID, rec.ExpDateDepois)
+		body["exp_date_restaurado"] = rec.ExpDateAntes
+	default:
+		return http.StatusBadRequest, gin.H{"erro": "Tipo de operação não pode ser desfeito: " + rec.Tipo}
+	}
+	if err != nil {
+		log.Printf("❌ Erro ao desfazer %s do cliente %d: %v", rec.Tipo, rec.UserID, err)
+		return http.StatusInternalServerError, gin.H{"erro": "Erro ao desfazer operação"}
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return http.StatusConflict, gin.H{"erro": "O cliente foi alterado por outra operação depois desta. Desfaça primeiro a operação mais recente."}
+	}
+
+	if rec.Creditos > 0 && rec.MemberIDCobrado > 0 {
+		motivo := "Reversão: " + rec.Descricao
+		_, err = models.ApplyCreditMovement(tx, models.CreditMovement{
+			MemberID:  rec.MemberIDCobrado,
+			Operacao:  ledgerOp,
+			UserID:    rec.UserID,
+			Valor:     rec.Creditos,
+			ActorID:   tokenInfo.MemberID,
+			Descricao: motivo,
+		})
+		if err != nil {
+			log.Printf("❌ Erro ao devolver créditos da operação %s: %v", rec.ID, err)
+			return http.StatusInternalServerError, gin.H{"erro": "Erro ao devolver créditos, tente novamente mais tarde."}
+		}
+		// Mesmo log de créditos do painel gravado pela reversão de renovação
+		_, err = tx.Exec("INSERT INTO streamcreed_db.credits_log (target_id, admin_id, amount, `date`, reason) VALUES (?, -1, ?, ?, ?)",
+			rec.MemberIDCobrado, rec.Creditos, time.Now().Unix(), motivo)
+		if err != nil {
+			log.Printf("❌ Erro ao inserir log de créditos: %v", err)
+			return http.StatusInternalServerError, gin.H{"erro": "Erro ao registrar log de créditos"}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return http.StatusInternalServerError, gin.H{"erro": "Erro ao finalizar transação"}
+	}
+	return http.StatusOK, body
+}
+
+// cleanupAfterUndo remove os controles que a operação desfeita deixou no Redis
+func cleanupAfterUndo(ctx context.Context, rec *models.UndoRecord) {
+	switch rec.Tipo {
+	case models.UndoRenovacao:
+		// O backup de /api/renew-rollback da mesma renovação não pode mais ser usado
+		backupKey := "renew_backup:" + strconv.Itoa(rec.UserID)
+		if raw, err := config.RedisClient.Get(ctx, backupKey).Result(); err == nil {
+			var backup models.RenewBackup
+			if json.Unmarshal([]byte(raw), &backup) == nil && backup.UndoID == rec.ID {
+				config.RedisClient.Del(ctx, backupKey)
+			}
+		}
+	case models.UndoAlteracaoVencimento:
+		// A alteração foi desfeita: libera uma nova alteração de vencimento
+		config.RedisClient.Del(ctx, "change_due_date:"+strconv.Itoa(rec.UserID))
+	}
+}
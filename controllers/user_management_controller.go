@@ -9,6 +9,7 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -45,7 +46,7 @@ func UpdateUserStatusHandler(c *gin.Context) {
 	}
 
 	// Verificar permissão para modificar este usuário
-	hasPermission, _, err := utils.VerificaPermissaoUsuario(userID, adminID)
+	hasPermission, _, err := utils.VerificaPermissaoUsuario(userID, adminID, tokenInfo.Role)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
@@ -183,9 +184,13 @@ func ForceUserRegionHandler(c *gin.Context) {
 	// log.Printf("[DEBUG] ForceUserRegionHandler: Usuário %d encontrado, responsibleMemberID: %d, adminID: %d, enabled: %v",
 	//	userID, responsibleMemberID, adminID, enabled)
 
-	// Se não for admin e não for o membro responsável, não permite a alteração
-	isAdmin := adminID == 1 // Exemplo: assumindo que adminID 1 é super-admin
-	if !isAdmin && adminID != responsibleMemberID {
+	// Se não for admin, o membro responsável ou uma master acima dele, não permite a alteração
+	permitido, err := utils.PodeGerenciarRevenda(adminID, responsibleMemberID, tokenInfo.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar permissões"})
+		return
+	}
+	if !permitido {
 		// log.Printf("[WARN] ForceUserRegionHandler: Usuário %d (memberID: %d) não tem permissão para alterar o usuário %d (memberID: %d)",
 		//	adminID, adminID, userID, responsibleMemberID)
 		c.JSON(http.StatusForbidden, gin.H{"error": "Você não tem permissão para alterar este usuário"})
@@ -301,7 +306,7 @@ func KickUserSessionHandler(c *gin.Context) {
 	}
 
 	// Verificar permissão para expulsar este usuário
-	hasPermission, _, err := utils.VerificaPermissaoUsuario(userID, adminID)
+	hasPermission, _, err := utils.VerificaPermissaoUsuario(userID, adminID, tokenInfo.Role)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
@@ -378,7 +383,7 @@ func SoftDeleteUserHandler(c *gin.Context) {
 	}
 
 	// Verificar permissão para excluir este usuário
-	hasPermission, _, err := utils.VerificaPermissaoUsuario(userID, adminID)
+	hasPermission, _, err := utils.VerificaPermissaoUsuario(userID, adminID, tokenInfo.Role)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
@@ -434,8 +439,8 @@ func ListDeletedUsersHandler(c *gin.Context) {
 	}
 	adminID := tokenInfo.MemberID
 
-	// Verificar se é superadmin
-	isAdmin := adminID == 1 // Super admin pode ver todos usuários excluídos
+	// Papéis com acesso a todos os clientes veem os excluídos de todas as revendas
+	isAdmin := utils.HasPermission(tokenInfo.Role, utils.PermClientsAll)
 
 	// Query atualizada para incluir member_id e todos campos necessários
 	baseQuery := `
@@ -444,12 +449,20 @@ func ListDeletedUsersHandler(c *gin.Context) {
 		WHERE deleted = 1 
 		AND date_deleted IS NOT NULL`
 
-	// Se não for admin, filtra apenas usuários da revenda
+	// Se não for admin, filtra apenas usuários da revenda e das sub-revendas dela
 	query := baseQuery
 	var args []interface{}
 	if !isAdmin {
-		query += " AND member_id = ?"
-		args = append(args, adminID)
+		revendas, err := utils.RevendasGerenciadas(adminID)
+		if err != nil {
+			log.Printf("ERRO ao buscar sub-revendas de %d: %v", adminID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar usuários excluídos"})
+			return
+		}
+		query += " AND member_id IN (" + strings.TrimSuffix(strings.Repeat("?,", len(revendas)), ",") + ")"
+		for _, id := range revendas {
+			args = append(args, id)
+		}
 	}
 
 	rows, err := config.DB.Query(query, args...)
@@ -614,8 +627,12 @@ func RestoreUserHandler(c *gin.Context) {
 	}
 
 	// Verificar permissão para restaurar este usuário
-	isAdmin := adminID == 1 // Super admin
-	if !isAdmin && adminID != responsibleMemberID {
+	permitido, err := utils.PodeGerenciarRevenda(adminID, responsibleMemberID, tokenInfo.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar permissões"})
+		return
+	}
+	if !permitido {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Você não tem permissão para restaurar este usuário"})
 		return
 	}
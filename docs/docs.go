@@ -18,54 +18,31 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/change-due-date": {
-            "post": {
+        "/api/2fa": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Altera o dia do vencimento da conta para o mês atual. Só pode ser feito uma vez a cada ALTERACAO_VENCIMENTO_FREQUENCIA_DIAS dias e o dia deve ser válido para o mês.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Informa se a revenda tem 2FA ativo e quantos códigos de recuperação ainda restam.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Ações"
-                ],
-                "summary": "Alteração da data mensal de vencimento",
-                "parameters": [
-                    {
-                        "description": "Exemplo: {\\",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.ChangeDueDateRequest"
-                        }
-                    }
+                    "Autenticação em Duas Etapas"
                 ],
+                "summary": "Status do 2FA",
                 "responses": {
                     "200": {
-                        "description": "Exemplo de resposta: {\\\"sucesso\\\": true, \\\"novo_exp_date\\\": 1716403200}",
+                        "description": "Exemplo: {\\\"enabled\\\": true, \\\"codigos_recuperacao_restantes\\\": 8}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Erro de validação ou regra de negócio",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
                     "401": {
-                        "description": "Token inválido ou não fornecido",
+                        "description": "Token inválido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -85,14 +62,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/clients": {
-            "get": {
+        "/api/2fa/disable": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retorna todos os clientes associados ao usuário autenticado. Permite filtrar por query string, login ou userID.",
+                "description": "Desativa o 2FA mediante um código TOTP ou de recuperação válido.",
                 "consumes": [
                     "application/json"
                 ],
@@ -100,19 +77,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Clientes"
+                    "Autenticação em Duas Etapas"
+                ],
+                "summary": "Desativar 2FA",
+                "parameters": [
+                    {
+                        "description": "Código TOTP ou de recuperação",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorCodePayload"
+                        }
+                    }
                 ],
-                "summary": "Lista clientes",
                 "responses": {
                     "200": {
-                        "description": "Lista de clientes, total_registros e token_expira_em",
+                        "description": "Exemplo: {\\\"message\\\": \\\"2FA desativado com sucesso\\\"}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "Código inválido ou 2FA inativo",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "401": {
-                        "description": "Token inválido ou não fornecido",
+                        "description": "Token inválido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -121,7 +118,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Erro interno ao buscar clientes",
+                        "description": "Erro interno",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -132,14 +129,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/clients-table": {
-            "get": {
+        "/api/2fa/enable": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retorna uma lista de clientes paginada e filtrada para uso em DataTables, associados ao member_id do token. Inclui filtro de status online e expiração.",
+                "description": "Confirma o cadastro com um código TOTP válido e ativa o 2FA. Retorna os códigos de recuperação, exibidos apenas uma vez.",
                 "consumes": [
                     "application/json"
                 ],
@@ -147,63 +144,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ClientsTable"
+                    "Autenticação em Duas Etapas"
                 ],
-                "summary": "Retorna clientes paginados e filtrados",
+                "summary": "Ativar 2FA",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Número da página (padrão: 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limite de registros por página (padrão: 10)",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Termo de pesquisa para filtrar por username ou reseller_notes",
-                        "name": "search",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Filtrar usuários online (true para listar apenas online, false para todos)",
-                        "name": "online",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Filtrar clientes por vencimento (7, 15, 30, custom até 90 ou \\'0\\' para vencidos)",
-                        "name": "expiration_filter",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Filtrar por ID do membro da franquia",
-                        "name": "franquia_member_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filtrar por status de trial (0 para não trial, 1 para trial)",
-                        "name": "is_trial",
-                        "in": "query"
+                        "description": "Código TOTP atual",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorCodePayload"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Retorna a lista de clientes paginada e informações de paginação",
+                        "description": "Exemplo: {\\\"message\\\": \\\"2FA ativado com sucesso\\\", \\\"codigos_recuperacao\\\": [\\\"abcd-efgh\\\"]}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "Código inválido ou cadastro não iniciado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "401": {
-                        "description": "Token inválido ou não fornecido",
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "2FA já está ativo",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -212,7 +194,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Erro interno ao buscar ou processar os dados",
+                        "description": "Erro interno",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -223,14 +205,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/clients/login/{login}": {
-            "get": {
+        "/api/2fa/recovery-codes": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retorna todos os clientes associados ao usuário autenticado. Permite filtrar por query string, login ou userID.",
+                "description": "Invalida os códigos de recuperação atuais e gera uma nova lista, mediante um código TOTP ou de recuperação válido.",
                 "consumes": [
                     "application/json"
                 ],
@@ -238,27 +220,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Clientes"
+                    "Autenticação em Duas Etapas"
                 ],
-                "summary": "Lista clientes",
+                "summary": "Gerar Novos Códigos de Recuperação",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Login do cliente (username)",
-                        "name": "login",
-                        "in": "path"
+                        "description": "Código TOTP ou de recuperação",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorCodePayload"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Lista de clientes, total_registros e token_expira_em",
+                        "description": "Exemplo: {\\\"codigos_recuperacao\\\": [\\\"abcd-efgh\\\"]}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "Código inválido ou 2FA inativo",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "401": {
-                        "description": "Token inválido ou não fornecido",
+                        "description": "Token inválido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -267,7 +261,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Erro interno ao buscar clientes",
+                        "description": "Erro interno",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -278,42 +272,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/clients/userid/{userid}": {
-            "get": {
+        "/api/2fa/setup": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retorna todos os clientes associados ao usuário autenticado. Permite filtrar por query string, login ou userID.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Gera um novo segredo TOTP e a URI otpauth:// para o QR code. O 2FA só passa a valer após a confirmação em /api/2fa/enable.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Clientes"
-                ],
-                "summary": "Lista clientes",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "ID do cliente (userID)",
-                        "name": "userid",
-                        "in": "path"
-                    }
+                    "Autenticação em Duas Etapas"
                 ],
+                "summary": "Iniciar Cadastro do 2FA",
                 "responses": {
                     "200": {
-                        "description": "Lista de clientes, total_registros e token_expira_em",
+                        "description": "Exemplo: {\\\"secret\\\": \\\"JBSWY3DPEHPK3PXP...\\\", \\\"otpauth_uri\\\": \\\"otpauth://totp/SmartOffice:revenda?secret=...\\\"}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "401": {
-                        "description": "Token inválido ou não fornecido",
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "2FA já está ativo",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -322,7 +314,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Erro interno ao buscar clientes",
+                        "description": "Erro interno",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -333,45 +325,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/create-test": {
+        "/api/admin/resellers/{member_id}/impersonate": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Gera um usuário e senha de teste para IPTV e retorna as credenciais.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Emite um token de acesso em nome da revenda para o suporte ver exatamente o que ela vê (ex.: /api/clients-table, /api/dashboard). O token carrega também o operador real (impersonated_by), não tem refresh, expira em IMPERSONACAO_MINUTOS e só permite leitura. Todo acesso feito com ele é auditado. Restrito a administradores.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Testes IPTV"
+                    "Sessões"
                 ],
-                "summary": "Criar Teste IPTV",
+                "summary": "Personificar Revenda",
                 "parameters": [
                     {
-                        "description": "Dados para criação do teste",
-                        "name": "test",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.TestRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID da revenda",
+                        "name": "member_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Teste criado com sucesso",
+                    "201": {
+                        "description": "Sessão de personificação aberta",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.ImpersonationResponse"
                         }
                     },
                     "400": {
-                        "description": "Erro na requisição ou usuário já existe (com credenciais fornecidas)",
+                        "description": "ID inválido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -388,8 +374,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "429": {
-                        "description": "Muitas tentativas de geração aleatória falharam",
+                    "403": {
+                        "description": "Sem permissão ou revenda administradora",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Revenda não encontrada",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -398,7 +393,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Erro interno do servidor",
+                        "description": "Erro interno",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -409,31 +404,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/credits": {
-            "get": {
+        "/api/admin/resellers/{member_id}/password-reset": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retorna o total de créditos do usuário autenticado e o tempo restante do token em segundos.",
+                "description": "Gera um token de uso único para a revenda definir uma nova senha em /password-reset. Emitir um novo token invalida o anterior. Restrito a administradores e não pode ser usado contra outro administrador.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Créditos"
+                    "Conta"
+                ],
+                "summary": "Emitir Token de Redefinição de Senha",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da revenda",
+                        "name": "member_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Obtém créditos atualizados e tempo restante do token",
                 "responses": {
-                    "200": {
-                        "description": "Dados de créditos e tempo restante",
+                    "201": {
+                        "description": "Exemplo: {\\\"token\\\": \\\"Vx3k...\\\", \\\"expires_in\\\": 1800}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "401": {
-                        "description": "Token inválido ou expirado",
+                        "description": "Token inválido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -441,38 +454,75 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Erro ao buscar créditos",
+                    "403": {
+                        "description": "Sem permissão ou revenda alvo é administrador",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/api/dashboard": {
-            "get": {
-                "security": [
+                    },
+                    "404": {
+                        "description": "Revenda não encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/resellers/{member_id}/sessions": {
+            "get": {
+                "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retorna os totais de clientes e testes ativos",
+                "description": "Lista as sessões ativas de qualquer revenda. Exige a permissão sessions:admin.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Dashboard"
+                    "Sessões"
+                ],
+                "summary": "Listar Sessões de uma Revenda",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da revenda",
+                        "name": "member_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Obtém os dados do dashboard",
                 "responses": {
                     "200": {
-                        "description": "Dados do dashboard",
+                        "description": "Lista de sessões da revenda",
                         "schema": {
-                            "$ref": "#/definitions/controllers.DashboardResponse"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "401": {
@@ -484,6 +534,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "Sem permissão",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Erro interno",
                         "schema": {
@@ -494,50 +553,39 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/details-error/{id_usuario}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retorna os erros registrados na conta de um usuário, incluindo IP, dispositivo e motivo do erro.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Encerra todas as sessões de qualquer revenda, ou apenas uma quando session_id é informado. Exige a permissão sessions:admin.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Erros"
+                    "Sessões"
                 ],
-                "summary": "Detalhes dos erros do usuário com paginação",
+                "summary": "Forçar Logout de uma Revenda",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID do usuário",
-                        "name": "id_usuario",
+                        "description": "ID da revenda",
+                        "name": "member_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Número de registros por página (padrão: 10)",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Número da página (padrão: 1)",
-                        "name": "page",
+                        "type": "string",
+                        "description": "Encerrar somente esta sessão",
+                        "name": "session_id",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Lista paginada de erros do usuário",
+                        "description": "Exemplo: {\\\"message\\\": \\\"Logout forçado com sucesso\\\", \\\"sessoes_encerradas\\\": 2}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -552,8 +600,26 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Sem permissão",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "404": {
-                        "description": "Nenhum erro encontrado",
+                        "description": "Sessão não encontrada",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -573,31 +639,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/regions/allowed": {
+        "/api/api-keys": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retorna as regiões permitidas configuradas na tabela settings como array de siglas.",
+                "description": "Lista as chaves de API da revenda (inclusive revogadas). O valor da chave nunca é retornado, apenas o prefixo.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Gerenciamento de Regiões"
+                    "Chaves de API"
                 ],
-                "summary": "Obter Regiões Permitidas",
+                "summary": "Listar Chaves de API",
                 "responses": {
                     "200": {
-                        "description": "Exemplo: {\\\"allowed_countries\\\": [\\\"AR\\\", \\\"AU\\\", \\\"BR\\\", \\\"CA\\\", \\\"EU\\\", \\\"FR\\\", \\\"DE\\\", \\\"JP\\\", \\\"PT\\\", \\\"RU\\\", \\\"SA\\\", \\\"ZA\\\", \\\"UA\\\", \\\"US\\\"]}",
+                        "description": "Exemplo: {\\\"total\\\": 1, \\\"chaves\\\": [{\\\"id\\\": 3, \\\"nome\\\": \\\"Bot WhatsApp\\\", \\\"prefixo\\\": \\\"sok_Ab12Cd34\\\", \\\"scopes\\\": [\\\"create-test\\\"], \\\"criado_em\\\": 1716210000}], \\\"scopes_disponiveis\\\": [\\\"create-test\\\", \\\"read-clients\\\", \\\"read-credits\\\", \\\"renew\\\"]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "array",
-                                "items": {
-                                    "type": "string"
-                                }
+                                "type": "string"
                             }
                         }
                     },
@@ -611,16 +681,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/renew": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Atualiza a data de expiração da conta com base no tempo selecionado.",
+                "description": "Cria uma chave de API para bots e integrações, enviada no header X-API-Key. A chave só pode usar as permissões dos scopes escolhidos. O valor completo é exibido apenas nesta resposta.",
                 "consumes": [
                     "application/json"
                 ],
@@ -628,30 +696,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Renovação"
+                    "Chaves de API"
                 ],
-                "summary": "Renovar Conta",
+                "summary": "Criar Chave de API",
                 "parameters": [
                     {
-                        "description": "Dados para renovação",
-                        "name": "renew",
+                        "description": "Nome, scopes e validade opcional",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.RenewRequest"
+                            "$ref": "#/definitions/models.CreateAPIKeyPayload"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Conta renovada com sucesso",
+                    "201": {
+                        "description": "Exemplo: {\\\"message\\\": \\\"Chave criada com sucesso\\\", \\\"chave\\\": \\\"sok_...\\\", \\\"dados\\\": {\\\"id\\\": 3, \\\"nome\\\": \\\"Bot WhatsApp\\\"}}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Erro na requisição",
+                        "description": "Dados ou scopes inválidos, ou limite de chaves atingido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -660,7 +728,7 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Token inválido ou conta bloqueada",
+                        "description": "Token inválido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -668,8 +736,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "402": {
-                        "description": "Créditos insuficientes",
+                    "500": {
+                        "description": "Erro interno",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -680,45 +748,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/renew-rollback": {
-            "post": {
+        "/api/api-keys/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Desfaz a última renovação: restaura exp_date e créditos a partir do backup da última renovação. Só pode ser feito uma vez a cada ROLLBACK_PERMITIDO_FREQUENCIA dias e dentro do período de ROLLBACK_PERMITIDO_DIAS após a renovação.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Revoga uma chave de API da revenda. A chave deixa de funcionar imediatamente.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Ações"
+                    "Chaves de API"
                 ],
-                "summary": "Rollback de renovação",
+                "summary": "Revogar Chave de API",
                 "parameters": [
                     {
-                        "description": "Exemplo: {\\",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.RenewRollbackRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID da chave",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Exemplo de resposta: {\\\"sucesso\\\": true, \\\"exp_date_anterior\\\": 1716403200, \\\"exp_date_restaurado\\\": 1716403200, \\\"creditos_devolvidos\\\": 3}",
+                        "description": "Exemplo: {\\\"message\\\": \\\"Chave revogada com sucesso\\\"}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Erro de validação ou regra de negócio",
+                        "description": "ID inválido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -727,7 +790,16 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Token inválido ou não fornecido",
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Chave não encontrada ou já revogada",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -747,14 +819,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tools-table/add-screen": {
+        "/api/change-due-date": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Aumenta o número máximo de conexões do usuário e desconta créditos se aplicável",
+                "description": "Altera o dia do vencimento da conta para o mês atual. Só pode ser feito uma vez a cada ALTERACAO_VENCIMENTO_FREQUENCIA_DIAS dias e o dia deve ser válido para o mês.",
                 "consumes": [
                     "application/json"
                 ],
@@ -762,30 +834,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ToolsTable"
+                    "Ações"
                 ],
-                "summary": "Adiciona uma nova tela ao usuário",
+                "summary": "Alteração da data mensal de vencimento",
                 "parameters": [
                     {
-                        "description": "JSON contendo o ID do usuário",
+                        "description": "Exemplo: {\\",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ScreenRequest"
+                            "$ref": "#/definitions/controllers.ChangeDueDateRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Retorna o novo total de telas e o saldo de créditos atualizado",
+                        "description": "Exemplo de resposta: {\\\"sucesso\\\": true, \\\"novo_exp_date\\\": 1716403200}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Erro nos parâmetros ou créditos insuficientes",
+                        "description": "Erro de validação ou regra de negócio",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -794,7 +866,7 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Token inválido",
+                        "description": "Token inválido ou não fornecido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -803,7 +875,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Erro interno ao adicionar tela",
+                        "description": "Erro interno",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -814,14 +886,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tools-table/edit/{id}": {
-            "put": {
+        "/api/clients": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Edita um usuário com base no ID fornecido. Permite a atualização de vários campos, incluindo nome de usuário, senha, notas do revendedor, número do WhatsApp, nome para aviso, envio de notificação, bouquet, aplicativos e preferências de notificação (Notificacao_conta, Notificacao_vods, Notificacao_jogos).",
+                "description": "Retorna todos os clientes associados ao usuário autenticado. Permite filtrar por query string, login ou userID.",
                 "consumes": [
                     "application/json"
                 ],
@@ -829,46 +904,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tools Table"
-                ],
-                "summary": "Edita um usuário existente",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID do Usuário",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Dados do Usuário para Editar. Campos como 'Notificacao_conta', 'Notificacao_vods', 'Notificacao_jogos' esperam true/false e são armazenados como 1/0.",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.EditUserRequest"
-                        }
-                    }
+                    "Clientes"
                 ],
+                "summary": "Lista clientes",
                 "responses": {
                     "200": {
-                        "description": "Usuário editado com sucesso",
+                        "description": "Lista de clientes, total_registros e token_expira_em",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Erro: Requisição inválida ou dados ausentes",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "404": {
-                        "description": "Erro: Usuário não encontrado",
+                    "401": {
+                        "description": "Token inválido ou não fornecido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -877,7 +925,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Erro: Erro interno do servidor",
+                        "description": "Erro interno ao buscar clientes",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -886,16 +934,17 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/tools-table/remove-screen": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Diminui o número máximo de conexões do usuário, garantindo que tenha pelo menos uma tela ativa",
+                "description": "Cria um cliente completo (não teste) no painel e cobra os créditos da revenda só se o painel aceitar: se o painel recusar a criação, nada é cobrado; se os créditos acabarem durante a criação, o cliente é excluído e nada é cobrado. O custo segue as regras de preço da renovação (meses x telas) e o vencimento segue a renovação: agora + 30 dias por mês, na hora RENOVACAO_HORA_VENCIMENTO. Sem username/password, as credenciais são geradas automaticamente; informados, seguem as regras da importação por CSV (usuário com 4 a TOTAL_CARACTERES_USER caracteres entre letras, números, ponto, hífen e sublinhado; senha com 4 a TOTAL_CARACTERES_SENHA e diferente do login). numero_whats é normalizado para só dígitos (10 a 15).",
                 "consumes": [
                     "application/json"
                 ],
@@ -903,30 +952,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ToolsTable"
+                    "Clientes"
                 ],
-                "summary": "Remove uma tela do usuário",
+                "summary": "Criar Cliente Pago",
                 "parameters": [
                     {
-                        "description": "JSON contendo o ID do usuário",
+                        "description": "Dados do cliente",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ScreenRequest"
+                            "$ref": "#/definitions/controllers.CreateClientRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem cobrar de novo",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Retorna o novo total de telas",
+                    "201": {
+                        "description": "Exemplo: {\\\"id_cliente\\\": 10, \\\"username\\\": \\\"usr123\\\", \\\"password\\\": \\\"abc456\\\", \\\"exp_date\\\": 1719700000, \\\"telas\\\": 1, \\\"creditos_gastos\\\": 1, \\\"creditos_restantes\\\": 9}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Erro nos parâmetros ou limite mínimo atingido",
+                        "description": "Dados inválidos (usuário, senha ou WhatsApp fora das regras) ou nome de usuário em uso",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -943,26 +998,63 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Erro interno ao remover tela",
+                    "402": {
+                        "description": "Créditos insuficientes (formato padrão: erro, codigo, creditos_disponiveis, creditos_necessarios)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key em processamento",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/api/trust-bonus": {
-            "post": {
-                "security": [
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reutilizada com corpo diferente",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Painel IPTV recusou a criação",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/clients-table": {
+            "get": {
+                "security": [
                     {
                         "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Adiciona dias extras à conta do usuário, conforme regras parametrizadas. Só é permitido para contas vencidas, quantidade de dias entre CONFIANCA_DIAS_MIN e CONFIANCA_DIAS_MAX, e apenas uma vez a cada CONFIANCA_FREQUENCIA_DIAS dias.",
+                "description": "Retorna uma lista de clientes paginada e filtrada para uso em DataTables, associados ao member_id do token. Filtros, ordenação e paginação são feitos no banco. Além de page/limit, aceita paginação por cursor (keyset): use o proximo_cursor da resposta em ?cursor= mantendo a mesma ordenação.",
                 "consumes": [
                     "application/json"
                 ],
@@ -970,30 +1062,99 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ações"
+                    "ClientsTable"
                 ],
-                "summary": "Liberação por confiança (dias extras)",
+                "summary": "Retorna clientes paginados e filtrados",
                 "parameters": [
                     {
-                        "description": "Exemplo: {\\",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.TrustBonusRequest"
-                        }
+                        "type": "integer",
+                        "description": "Número da página (padrão: 1; ignorado com cursor)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limite de registros por página (padrão: 20, máximo: 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor da próxima página (proximo_cursor da resposta anterior)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Termo de pesquisa para filtrar por username ou reseller_notes",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filtrar usuários online (true para listar apenas online, false para todos)",
+                        "name": "online",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filtrar clientes por vencimento (vencendo nos próximos N dias ou -1 para vencidos; 0 = sem filtro)",
+                        "name": "expiration_filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vencimento a partir de (timestamp UNIX)",
+                        "name": "vencimento_de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vencimento até (timestamp UNIX)",
+                        "name": "vencimento_ate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filtrar por ID do membro da franquia",
+                        "name": "franquia_member_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por status de trial (0 para não trial, 1 para trial)",
+                        "name": "is_trial",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ordenação: vencimento, criacao (padrão) ou username",
+                        "name": "ordenar_por",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc ou desc (padrão: desc)",
+                        "name": "ordem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de uma sub-revenda da árvore (padrão: a própria revenda)",
+                        "name": "revenda_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Exemplo de resposta: {\\\"sucesso\\\": true, \\\"novo_exp_date\\\": 1716403200}",
+                        "description": "Retorna a lista de clientes paginada e informações de paginação (total_paginas, pagina_atual, total_registros, clientes, proximo_cursor)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Erro de validação ou regra de negócio",
+                        "description": "Parâmetros inválidos",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1011,7 +1172,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Erro interno",
+                        "description": "Erro interno ao buscar ou processar os dados",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1022,33 +1183,127 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/deleted": {
+        "/api/clients-table/export": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retorna a lista de usuários que foram excluídos logicamente nos últimos 30 dias.",
+                "description": "Exporta os clientes da revenda com os mesmos filtros e ordenação de /api/clients-table (sem paginação). O arquivo é gerado enquanto as linhas são lidas do banco. Textos iniciados por =, +, - ou @ saem com um ' na frente (proteção contra fórmulas). Se a leitura falhar no meio de um CSV, a última linha do arquivo é \"#ERRO: exportação interrompida; o arquivo está incompleto\". Colunas disponíveis: id, username, password, exp_date, max_connections, enabled, admin_enabled, is_trial, created_at, reseller_notes, numero_whats, nome_para_aviso, aplicativo, bouquet, franquia_member_id. A senha (password) só é exportada quando pedida explicitamente em colunas.",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
                 ],
                 "tags": [
-                    "Gerenciamento de Usuários"
+                    "ClientsTable"
+                ],
+                "summary": "Exporta clientes em CSV ou XLSX",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv ou xlsx",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Colunas separadas por vírgula, na ordem desejada (padrão: todas exceto password)",
+                        "name": "colunas",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Termo de pesquisa para filtrar por username ou reseller_notes",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apenas clientes online",
+                        "name": "online",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vencendo nos próximos N dias ou -1 para vencidos (0 = sem filtro)",
+                        "name": "expiration_filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vencimento a partir de (timestamp UNIX)",
+                        "name": "vencimento_de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vencimento até (timestamp UNIX)",
+                        "name": "vencimento_ate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filtrar por ID do membro da franquia",
+                        "name": "franquia_member_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "0 para não trial, 1 para trial",
+                        "name": "is_trial",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "vencimento, criacao (padrão) ou username",
+                        "name": "ordenar_por",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc ou desc (padrão: desc)",
+                        "name": "ordem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de uma sub-revenda da árvore (padrão: a própria revenda)",
+                        "name": "revenda_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Listar Contas Excluídas",
                 "responses": {
                     "200": {
-                        "description": "Lista de usuários excluídos",
+                        "description": "Arquivo com os clientes",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.DeletedUser"
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Formato, coluna ou filtro inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido ou não fornecido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
                     "500": {
-                        "description": "Erro interno",
+                        "description": "Erro ao buscar clientes",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1059,48 +1314,74 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/{user_id}": {
-            "delete": {
+        "/api/clients/import": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Marca o usuário como desativado e registra a exclusão lógica.",
+                "description": "Valida cada linha do CSV (usuário com 4 a TOTAL_CARACTERES_USER caracteres, senha com 4 a TOTAL_CARACTERES_SENHA, usuários repetidos no arquivo ou existentes no painel, formato do WhatsApp) e calcula o custo pelas regras da criação paga. Com dry_run=true devolve apenas o relatório. Sem dry_run, cria os clientes válidos em segundo plano e devolve o job_id para acompanhar em GET /api/clients/import/{job_id} (os logins e senhas dos clientes criados são lidos uma única vez em POST /api/clients/import/{job_id}/credenciais); linhas inválidas bloqueiam a importação, a menos que ignorar_invalidas=true. Colunas: username, password, meses (obrigatória), telas, numero_whats, nome_para_aviso, bouquet, franquia_member_id.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Gerenciamento de Usuários"
+                    "Clientes"
                 ],
-                "summary": "Exclusão Lógica de Conta",
+                "summary": "Importar clientes por CSV",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID do Usuário",
-                        "name": "user_id",
-                        "in": "path",
+                        "type": "file",
+                        "description": "Arquivo CSV (cabeçalho obrigatório, separador vírgula ou ponto e vírgula)",
+                        "name": "arquivo",
+                        "in": "formData",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apenas validar e orçar, sem criar clientes",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Importar as linhas válidas mesmo havendo linhas inválidas",
+                        "name": "ignorar_invalidas",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave única da operação: repetições com a mesma chave e o mesmo arquivo retornam a resposta original sem importar de novo",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Exemplo: {\\\"message\\\": \\\"Usuário excluído logicamente com sucesso\\\"}",
+                        "description": "Relatório do dry-run (total, validas, invalidas, custo_total, creditos_disponiveis, creditos_suficientes, linhas)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "202": {
+                        "description": "Importação iniciada: {\\\"job_id\\\": \\\"...\\\", \\\"status\\\": \\\"pendente\\\", \\\"total\\\": 120}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "ID inválido",
+                        "description": "Arquivo inválido ou linhas inválidas (com o relatório)",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
                     "401": {
@@ -1112,8 +1393,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Usuário não encontrado",
+                    "409": {
+                        "description": "Já existe uma importação em andamento para a revenda",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1133,58 +1414,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/{user_id}/region": {
-            "patch": {
+        "/api/clients/import/{job_id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Altera o campo \"forced_country\" do usuário.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Retorna o estado da importação (pendente, processando, concluida, interrompida), os contadores e o resultado de cada linha (criada, falhou, invalida, nao_processada) com os erros. Disponível por IMPORTACAO_RETENCAO_HORAS horas (padrão 24).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Gerenciamento de Usuários"
+                    "Clientes"
                 ],
-                "summary": "Forçar Região para Usuário",
+                "summary": "Andamento da importação de clientes",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID do Usuário",
-                        "name": "user_id",
+                        "type": "string",
+                        "description": "ID da importação",
+                        "name": "job_id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Payload com o país forçado (ex: US)",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UserRegionPayload"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Exemplo: {\\\"message\\\": \\\"Região do usuário atualizada com sucesso\\\"}",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "ID ou payload inválido",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ClientImportJob"
+                        }
                     },
                     "401": {
                         "description": "Token inválido",
@@ -1195,17 +1457,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "Usuário não tem permissão para alterar este usuário",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
                     "404": {
-                        "description": "Usuário não encontrado",
+                        "description": "Importação não encontrada ou expirada",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1225,37 +1478,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/{user_id}/restore": {
-            "patch": {
+        "/api/clients/import/{job_id}/credenciais": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Restaura a conta do usuário removendo os campos de exclusão lógica.",
+                "description": "Devolve o usuário e a senha (informada no CSV ou gerada) de cada cliente criado pela importação. Disponível só depois que a importação termina, apenas para a revenda que a iniciou, por IMPORTACAO_CREDENCIAIS_MINUTOS minutos (padrão 60), e apagado na primeira leitura. É POST porque consome as credenciais: bloqueado durante personificação.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Gerenciamento de Usuários"
+                    "Clientes"
                 ],
-                "summary": "Restaurar Conta",
+                "summary": "Credenciais dos clientes importados",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID do Usuário",
-                        "name": "user_id",
+                        "type": "string",
+                        "description": "ID da importação",
+                        "name": "job_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Exemplo: {\\\"message\\\": \\\"Usuário restaurado com sucesso\\\"}",
+                        "description": "Exemplo: {\\\"job_id\\\": \\\"...\\\", \\\"credenciais\\\": [{\\\"linha\\\": 2, \\\"id_cliente\\\": 10, \\\"username\\\": \\\"joao123\\\", \\\"password\\\": \\\"x8k2m4\\\"}]}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "ID inválido",
+                    "401": {
+                        "description": "Token inválido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1263,8 +1522,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Token inválido",
+                    "403": {
+                        "description": "Bloqueado durante personificação",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1273,7 +1532,16 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Usuário não encontrado ou não está excluído",
+                        "description": "Importação não encontrada ou credenciais já lidas/expiradas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Importação ainda em andamento",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1293,37 +1561,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/{user_id}/session": {
-            "delete": {
+        "/api/clients/login/{login}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Remove a sessão ativa do usuário da tabela \"user_activity_now\".",
+                "description": "Retorna todos os clientes associados ao usuário autenticado. Permite filtrar por query string, login ou userID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Gerenciamento de Usuários"
+                    "Clientes"
                 ],
-                "summary": "Expulsar Usuário (Kick)",
+                "summary": "Lista clientes",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID do Usuário",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Login do cliente (username)",
+                        "name": "login",
+                        "in": "path"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Exemplo: {\\\"message\\\": \\\"Sessão do usuário removida com sucesso\\\"}",
+                        "description": "Lista de clientes, total_registros e token_expira_em",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "ID inválido",
+                    "401": {
+                        "description": "Token inválido ou não fornecido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1331,26 +1607,57 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Token inválido",
+                    "500": {
+                        "description": "Erro interno ao buscar clientes",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
+                    }
+                }
+            }
+        },
+        "/api/clients/userid/{userid}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
                     },
-                    "403": {
-                        "description": "Usuário não tem permissão para expulsar este usuário",
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna todos os clientes associados ao usuário autenticado. Permite filtrar por query string, login ou userID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Clientes"
+                ],
+                "summary": "Lista clientes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do cliente (userID)",
+                        "name": "userid",
+                        "in": "path"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lista de clientes, total_registros e token_expira_em",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Usuário não encontrado",
+                    "401": {
+                        "description": "Token inválido ou não fornecido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1359,7 +1666,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Erro interno",
+                        "description": "Erro interno ao buscar clientes",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1370,14 +1677,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/{user_id}/status": {
-            "patch": {
+        "/api/clients/{id}/convert": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Altera o campo \"enabled\" do usuário.",
+                "description": "Converte um teste em cliente pago, cobrando pelas regras de preço de conversão de teste. A política define o vencimento: \"agora\" (o período começa agora e as horas de teste restantes são descartadas) ou \"somar\" (o período é somado ao vencimento do teste); o padrão vem de CONVERSAO_TESTE_POLITICA. Telas e bouquet podem ser alterados na mesma operação. Cada conversão é registrada como evento próprio (origem conversao_teste no MongoDB e operação conversao_teste no extrato).",
                 "consumes": [
                     "application/json"
                 ],
@@ -1385,37 +1695,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Gerenciamento de Usuários"
+                    "Renovação"
                 ],
-                "summary": "Ativar/Desativar Conta",
+                "summary": "Converter Teste em Cliente Pago",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID do Usuário",
-                        "name": "user_id",
+                        "description": "ID do cliente (teste)",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Payload contendo o novo status do usuário",
+                        "description": "Período, política, telas e bouquet",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UserStatusPayload"
+                            "$ref": "#/definitions/controllers.ConvertTrialRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem cobrar de novo",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Exemplo: {\\\"message\\\": \\\"Status do usuário atualizado com sucesso\\\"}",
+                        "description": "Exemplo: {\\\"id_cliente\\\": 10, \\\"politica\\\": \\\"agora\\\", \\\"novo_exp_date\\\": 1719700000, \\\"horas_teste_descartadas\\\": 3, \\\"telas\\\": 2, \\\"creditos_gastos\\\": 2, \\\"creditos_restantes\\\": 8}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "ID ou payload inválido",
+                        "description": "Dados inválidos",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1432,17 +1748,15 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "Usuário não tem permissão para alterar este usuário",
+                    "402": {
+                        "description": "Créditos insuficientes (formato padrão: erro, codigo, creditos_disponiveis, creditos_necessarios)",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Usuário não encontrado",
+                    "403": {
+                        "description": "Cliente não pertence à revenda",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1450,57 +1764,26 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Erro interno",
+                    "404": {
+                        "description": "Cliente não encontrado",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/api/version": {
-            "get": {
-                "description": "Retorna a versão atual da API definida no arquivo .env",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Versão"
-                ],
-                "summary": "Obter versão da API",
-                "responses": {
-                    "200": {
-                        "description": "Versão da API",
+                    },
+                    "409": {
+                        "description": "Cliente não é teste (ou já foi convertido)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/health": {
-            "get": {
-                "description": "Check the health of the service, including database and Redis connections",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "health"
-                ],
-                "summary": "Health Check",
-                "responses": {
-                    "200": {
-                        "description": "Exemplo: {\\\"status\\\": \\\"OK\\\", \\\"database\\\": \\\"OK\\\", \\\"redis\\\": \\\"OK\\\"}",
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reutilizada com corpo diferente",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1508,8 +1791,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "503": {
-                        "description": "Exemplo: {\\\"status\\\": \\\"Error\\\", \\\"database\\\": \\\"Error: connection failed\\\", \\\"redis\\\": \\\"OK\\\"}",
+                    "500": {
+                        "description": "Erro interno",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1520,39 +1803,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/login": {
-            "post": {
-                "description": "Autentica um usuário e retorna um token JWT se as credenciais forem válidas.",
-                "consumes": [
-                    "application/json"
+        "/api/clients/{id}/renewals": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Lista, em ordem cronológica, a criação paga, a conversão de teste, as renovações (manuais e automáticas), reversões, bônus de confiança, alterações de vencimento e operações desfeitas do cliente. Datas em timestamp UNIX. Traz no máximo os 1000 eventos mais recentes de cada origem; quando há mais, a resposta vem com truncado=true e começa no evento mais antigo que coube. Apenas clientes da revenda (ou de suas sub-revendas) podem ser consultados.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Autenticação"
+                    "Renovação"
                 ],
-                "summary": "Autenticação de Usuário",
+                "summary": "Histórico de Renovações do Cliente",
                 "parameters": [
                     {
-                        "description": "Credenciais de login",
-                        "name": "login",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.LoginRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login realizado com sucesso",
+                        "description": "Exemplo: {\\\"id_cliente\\\": 10, \\\"total\\\": 2, \\\"truncado\\\": false, \\\"eventos\\\": [{\\\"tipo\\\": \\\"renovacao\\\", \\\"ocorrido_em\\\": 1717000000, \\\"exp_date_anterior\\\": 1717100000, \\\"novo_exp_date\\\": 1719700000, \\\"creditos\\\": 2, \\\"member_id\\\": 5}]}",
                         "schema": {
-                            "$ref": "#/definitions/controllers.LoginResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Erro na requisição",
+                        "description": "ID inválido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1561,7 +1848,34 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Credenciais inválidas",
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Cliente não pertence à revenda",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Cliente não encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1572,14 +1886,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/logout": {
+        "/api/create-test": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Remove o token ativo do usuário, invalidando sua sessão.",
+                "description": "Gera um usuário e senha de teste para IPTV e retorna as credenciais.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1587,12 +1904,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Logout"
+                    "Testes IPTV"
+                ],
+                "summary": "Criar Teste IPTV",
+                "parameters": [
+                    {
+                        "description": "Dados para criação do teste",
+                        "name": "test",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TestRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem cobrar de novo",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
                 ],
-                "summary": "Logout do Usuário",
                 "responses": {
                     "200": {
-                        "description": "Logout realizado com sucesso",
+                        "description": "Teste criado com sucesso",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Erro na requisição ou usuário já existe (com credenciais fornecidas)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1601,7 +1942,43 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Token inválido ou não autorizado",
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key em processamento",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reutilizada com corpo diferente",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Muitas tentativas de geração aleatória falharam",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1611,163 +1988,3094 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.ChangeDueDateRequest": {
+        },
+        "/api/credits": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna o total de créditos do usuário autenticado e o tempo restante do token em segundos.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Créditos"
+                ],
+                "summary": "Obtém créditos atualizados e tempo restante do token",
+                "responses": {
+                    "200": {
+                        "description": "Dados de créditos e tempo restante",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido ou expirado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao buscar créditos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/credits/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lista as movimentações de créditos da revenda (renovações, telas adicionais, reversões e transferências), com saldo antes/depois de cada operação, para conferência do saldo.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Créditos"
+                ],
+                "summary": "Extrato de créditos",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Número da página (padrão: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Registros por página (padrão: 20, máximo: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por operação (renovacao, reversao_renovacao, tela_adicional, transferencia_enviada, transferencia_recebida)",
+                        "name": "operacao",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filtrar por cliente relacionado",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data inicial (AAAA-MM-DD)",
+                        "name": "data_inicio",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data final, inclusiva (AAAA-MM-DD)",
+                        "name": "data_fim",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de uma sub-revenda da árvore (padrão: a própria revenda)",
+                        "name": "revenda_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"total_paginas\\\": 1, \\\"pagina_atual\\\": 1, \\\"total_registros\\\": 1, \\\"movimentacoes\\\": [{\\\"id\\\": 10, \\\"operacao\\\": \\\"renovacao\\\", \\\"user_id\\\": 123, \\\"valor\\\": -2, \\\"saldo_antes\\\": 50, \\\"saldo_depois\\\": 48, \\\"actor_id\\\": 7, \\\"descricao\\\": \\\"Renovação de 1 mês(es) x 2 tela(s)\\\", \\\"criado_em\\\": 1716200000}]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetros inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido ou expirado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Revenda fora da árvore",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao buscar extrato",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/dashboard": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retorna os totais de clientes e testes ativos",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Obtém os dados do dashboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de uma sub-revenda da árvore (padrão: a própria revenda)",
+                        "name": "revenda_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dados do dashboard",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.DashboardResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/details-error/{id_usuario}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna os erros registrados na conta de um usuário, incluindo IP, dispositivo e motivo do erro.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Erros"
+                ],
+                "summary": "Detalhes dos erros do usuário com paginação",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do usuário",
+                        "name": "id_usuario",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Número de registros por página (padrão: 10)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Número da página (padrão: 1)",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lista paginada de erros do usuário",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Nenhum erro encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Troca a senha da revenda autenticada. Exige a senha atual. As demais sessões da revenda são encerradas (a sessão usada na requisição continua válida) e todas as chaves de API são revogadas.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Conta"
+                ],
+                "summary": "Alterar Própria Senha",
+                "parameters": [
+                    {
+                        "description": "Senha atual e nova senha",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangePasswordPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"message\\\": \\\"Senha alterada com sucesso\\\", \\\"sessoes_encerradas\\\": 2, \\\"chaves_api_revogadas\\\": 1}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Dados inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido ou senha atual incorreta",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/pricing/quote": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Calcula o custo exato de uma renovação ou de uma tela adicional pelas regras de preço da revenda, com o detalhamento de cada regra aplicada. Nada é cobrado. Com user_id, telas, dias restantes e conversão de teste são lidos do cliente. Renovações podem ser orçadas por meses, por dias ou até uma data alvo (proporcional, com o arredondamento configurado); com user_id, a data alvo conta a partir do vencimento do cliente.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Preços"
+                ],
+                "summary": "Orçamento de Renovação ou Tela Adicional",
+                "parameters": [
+                    {
+                        "description": "Operação a orçar",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceQuoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Orçamento calculado",
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceQuote"
+                        }
+                    },
+                    "400": {
+                        "description": "Dados inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido ou cliente de outra revenda",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Cliente não encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/regions/allowed": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retorna as regiões permitidas configuradas na tabela settings como array de siglas.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Gerenciamento de Regiões"
+                ],
+                "summary": "Obter Regiões Permitidas",
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"allowed_countries\\\": [\\\"AR\\\", \\\"AU\\\", \\\"BR\\\", \\\"CA\\\", \\\"EU\\\", \\\"FR\\\", \\\"DE\\\", \\\"JP\\\", \\\"PT\\\", \\\"RU\\\", \\\"SA\\\", \\\"ZA\\\", \\\"UA\\\", \\\"US\\\"]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/renew": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Atualiza a data de expiração da conta com base no tempo selecionado: meses (30 dias cada), quantidade_dias ou data_alvo (ex.: alinhar ao dia de pagamento do cliente). Dias e data alvo são cobrados proporcionalmente ao preço mensal, com o arredondamento de RENOVACAO_ARREDONDAMENTO; o custo e o detalhamento voltam na resposta. Testes não são renovados aqui: use /api/clients/{id}/convert.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Renovação"
+                ],
+                "summary": "Renovar Conta",
+                "parameters": [
+                    {
+                        "description": "Dados para renovação",
+                        "name": "renew",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RenewRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem cobrar de novo",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Conta renovada com sucesso",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Erro na requisição",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido ou conta bloqueada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "402": {
+                        "description": "Créditos insuficientes (formato padrão: erro, codigo, creditos_disponiveis, creditos_necessarios)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key em processamento, cliente é teste (use /api/clients/{id}/convert) ou cliente alterado por outra operação durante a renovação",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reutilizada com corpo diferente",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/renew-rollback": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Desfaz a última renovação: restaura exp_date e créditos a partir do backup da última renovação. Só pode ser feito uma vez a cada ROLLBACK_PERMITIDO_FREQUENCIA dias e dentro do período de ROLLBACK_PERMITIDO_DIAS após a renovação.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ações"
+                ],
+                "summary": "Rollback de renovação",
+                "parameters": [
+                    {
+                        "description": "Exemplo: {\\",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RenewRollbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo de resposta: {\\\"sucesso\\\": true, \\\"exp_date_anterior\\\": 1716403200, \\\"exp_date_restaurado\\\": 1716403200, \\\"creditos_devolvidos\\\": 3}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Erro de validação ou regra de negócio",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido ou não fornecido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/renew/bulk": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Renova vários clientes de uma vez. No modo \"tudo_ou_nada\", todos são validados e orçados antes e qualquer falha desfaz o lote inteiro (nenhum crédito é cobrado). No modo \"melhor_esforco\", cada cliente é renovado em sua própria transação e as falhas não afetam os demais. Cada renovação gera o mesmo backup (renew_backup:) usado pela reversão.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Renovação"
+                ],
+                "summary": "Renovação em Lote",
+                "parameters": [
+                    {
+                        "description": "Modo e lista de clientes",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.BulkRenewRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem cobrar de novo",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"modo\\\": \\\"melhor_esforco\\\", \\\"renovados\\\": 1, \\\"falhas\\\": 1, \\\"creditos_gastos\\\": 2, \\\"creditos_restantes\\\": 48, \\\"resultados\\\": [{\\\"id_cliente\\\": 10, \\\"status\\\": \\\"renovado\\\", \\\"novo_exp_date\\\": 1719000000, \\\"creditos_gastos\\\": 2}, {\\\"id_cliente\\\": 11, \\\"status\\\": \\\"falhou\\\", \\\"creditos_gastos\\\": 0, \\\"erro\\\": {\\\"erro\\\": \\\"Cliente não pertence a este MemberID\\\"}}]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Dados inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "402": {
+                        "description": "Tudo ou nada: créditos insuficientes para o lote (resultados por cliente incluídos)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key em processamento ou, no tudo ou nada, cliente alterado por outra operação (ou repetido no lote) durante a renovação",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Tudo ou nada: algum cliente falhou na validação (resultados por cliente incluídos)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/resellers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista todas as sub-revendas abaixo da revenda autenticada (em todos os níveis), com créditos e status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sub-revendas"
+                ],
+                "summary": "Listar Sub-revendas",
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"total\\\": 1, \\\"sub_revendas\\\": [{\\\"id\\\": 42, \\\"username\\\": \\\"sub1\\\", \\\"owner_id\\\": 7, \\\"member_group_id\\\": 2, \\\"credits\\\": 50, \\\"status\\\": 1, \\\"nivel\\\": 1}]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cria uma sub-revenda abaixo da revenda autenticada. Créditos iniciais (opcionais) são transferidos da master na mesma transação.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sub-revendas"
+                ],
+                "summary": "Criar Sub-revenda",
+                "parameters": [
+                    {
+                        "description": "Dados da sub-revenda",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSubResellerPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Exemplo: {\\\"message\\\": \\\"Sub-revenda criada com sucesso\\\", \\\"id\\\": 42, \\\"creditos_transferidos\\\": 10}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Dados inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "402": {
+                        "description": "Créditos insuficientes (formato padrão: erro, codigo, creditos_disponiveis, creditos_necessarios)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Username já existe",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/resellers/{member_id}/credits": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move créditos da revenda autenticada para uma sub-revenda da sua árvore. Débito e crédito acontecem na mesma transação.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sub-revendas"
+                ],
+                "summary": "Transferir Créditos para Sub-revenda",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da sub-revenda",
+                        "name": "member_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Quantidade de créditos",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreditTransferPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"message\\\": \\\"Créditos transferidos com sucesso\\\", \\\"creditos_restantes\\\": 90}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Dados inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "402": {
+                        "description": "Créditos insuficientes (formato padrão: erro, codigo, creditos_disponiveis, creditos_necessarios)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Revenda fora da árvore",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as sessões (dispositivos) em que a revenda está logada, com IP, user agent, data de criação e último uso. A sessão do token usado na requisição vem marcada com \"atual\".",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessões"
+                ],
+                "summary": "Listar Sessões Ativas",
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"total\\\": 2, \\\"sessoes\\\": [{\\\"session_id\\\": \\\"9f1c...\\\", \\\"ip\\\": \\\"200.1.2.3\\\", \\\"user_agent\\\": \\\"Mozilla/5.0\\\", \\\"criado_em\\\": \\\"2025-05-20T10:00:00-03:00\\\", \\\"ultimo_uso\\\": \\\"2025-05-20T10:15:00-03:00\\\", \\\"expira_em\\\": \\\"2025-06-19T10:00:00-03:00\\\", \\\"atual\\\": true}]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Encerra todas as sessões da própria revenda. Com exceto_atual=true a sessão do token usado na requisição é mantida.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessões"
+                ],
+                "summary": "Encerrar Todas as Sessões",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Manter a sessão atual (padrão: false)",
+                        "name": "exceto_atual",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"message\\\": \\\"Sessões encerradas com sucesso\\\", \\\"sessoes_encerradas\\\": 3}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions/{session_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Encerra uma sessão específica da própria revenda (ex.: dispositivo perdido ou roubado).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessões"
+                ],
+                "summary": "Encerrar Sessão",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da sessão",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"message\\\": \\\"Sessão encerrada com sucesso\\\"}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Sessão não encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tools-table/add-screen": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Aumenta o número máximo de conexões do usuário e desconta créditos se aplicável",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ToolsTable"
+                ],
+                "summary": "Adiciona uma nova tela ao usuário",
+                "parameters": [
+                    {
+                        "description": "JSON contendo o ID do usuário",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ScreenRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem cobrar de novo",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Retorna o novo total de telas e o saldo de créditos atualizado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Erro nos parâmetros",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "402": {
+                        "description": "Créditos insuficientes (formato padrão: erro, codigo, creditos_disponiveis, creditos_necessarios)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Telas alteradas em paralelo ou Idempotency-Key em processamento",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reutilizada com corpo diferente",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno ao adicionar tela",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tools-table/edit/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Edita um usuário com base no ID fornecido. Permite a atualização de vários campos, incluindo nome de usuário, senha, notas do revendedor, número do WhatsApp, nome para aviso, envio de notificação, bouquet, aplicativos, preferências de notificação (Notificacao_conta, Notificacao_vods, Notificacao_jogos) e valor do plano.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tools Table"
+                ],
+                "summary": "Edita um usuário existente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do Usuário",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dados do Usuário para Editar. Campos como 'Notificacao_conta', 'Notificacao_vods', 'Notificacao_jogos' esperam true/false e são armazenados como 1/0. 'Valor_plano' espera um valor decimal.",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EditUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Usuário editado com sucesso. Inclui todos os campos atualizados, como 'Valor_plano'.",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Erro: Requisição inválida ou dados ausentes",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Erro: Usuário não encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro: Erro interno do servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tools-table/remove-screen": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Diminui o número máximo de conexões do usuário, garantindo que tenha pelo menos uma tela ativa. Devolve créditos proporcionais aos dias restantes (mesmas regras de preço da tela adicional), limitados ao que a revenda pagou pela tela adicional mais recente ainda não devolvida (compras dos últimos TELA_REEMBOLSO_JANELA_DIAS dias; cada remoção consome uma compra); a devolução vai para a revenda que pagou e aparece no extrato como reembolso_tela.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ToolsTable"
+                ],
+                "summary": "Remove uma tela do usuário",
+                "parameters": [
+                    {
+                        "description": "JSON contendo o ID do usuário",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ScreenRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave única da operação: repetições com a mesma chave e o mesmo corpo retornam a resposta original sem devolver de novo",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"sucesso\\\": \\\"Tela removida com sucesso\\\", \\\"total_telas\\\": 1, \\\"creditos_reembolsados\\\": 0.5, \\\"valor_proporcional\\\": 0.5, \\\"pago_pela_tela\\\": 1, \\\"janela_dias\\\": 30, \\\"creditos_atuais\\\": 10.5}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Erro nos parâmetros ou limite mínimo atingido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Telas alteradas por outra operação",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno ao remover tela",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/trust-bonus": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adiciona dias extras à conta do usuário, conforme regras parametrizadas. Só é permitido para contas vencidas, quantidade de dias entre CONFIANCA_DIAS_MIN e CONFIANCA_DIAS_MAX, e apenas uma vez a cada CONFIANCA_FREQUENCIA_DIAS dias.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ações"
+                ],
+                "summary": "Liberação por confiança (dias extras)",
+                "parameters": [
+                    {
+                        "description": "Exemplo: {\\",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TrustBonusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo de resposta: {\\\"sucesso\\\": true, \\\"novo_exp_date\\\": 1716403200}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Erro de validação ou regra de negócio",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido ou não fornecido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/undo": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lista as operações do cliente (renovação, tela adicional, alteração de vencimento, bônus de confiança) que ainda estão dentro do prazo de ROLLBACK_PERMITIDO_DIAS, da mais recente para a mais antiga.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ações"
+                ],
+                "summary": "Operações que Podem Ser Desfeitas",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"user_id\\\": 123, \\\"reversao_bloqueada\\\": false, \\\"operacoes\\\": [{\\\"id\\\": \\\"9f2c...\\\", \\\"tipo\\\": \\\"tela_adicional\\\", \\\"user_id\\\": 123, \\\"telas_antes\\\": 1, \\\"telas_depois\\\": 2, \\\"creditos\\\": 1, \\\"criado_em\\\": \\\"2024-06-01T10:00:00-03:00\\\", \\\"expira_em\\\": \\\"2024-06-03T10:00:00-03:00\\\"}]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Cliente não pertence à revenda",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Cliente não encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/undo/{operacao_id}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Desfaz uma renovação, tela adicional, alteração de vencimento ou bônus de confiança: restaura o vencimento ou as telas anteriores e devolve os créditos cobrados à revenda que pagou. Vale dentro de ROLLBACK_PERMITIDO_DIAS após a operação e uma vez a cada ROLLBACK_PERMITIDO_FREQUENCIA dias por cliente (limite compartilhado com /api/renew-rollback). Se o cliente foi alterado por outra operação depois desta, desfaça a mais recente primeiro.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ações"
+                ],
+                "summary": "Desfazer Operação",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da operação (operacao_id retornado pela operação ou listado em /api/undo)",
+                        "name": "operacao_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Motivo (opcional)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.UndoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"sucesso\\\": true, \\\"operacao_id\\\": \\\"9f2c...\\\", \\\"tipo\\\": \\\"tela_adicional\\\", \\\"creditos_devolvidos\\\": 1, \\\"telas_restauradas\\\": 1}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Reversão bloqueada pela frequência",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Cliente não pertence à revenda",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Operação não encontrada ou prazo expirado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "O cliente foi alterado depois da operação",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/deleted": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retorna a lista de usuários que foram excluídos logicamente nos últimos 30 dias.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Gerenciamento de Usuários"
+                ],
+                "summary": "Listar Contas Excluídas",
+                "responses": {
+                    "200": {
+                        "description": "Lista de usuários excluídos",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DeletedUser"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{user_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marca o usuário como desativado e registra a exclusão lógica.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Gerenciamento de Usuários"
+                ],
+                "summary": "Exclusão Lógica de Conta",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do Usuário",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"message\\\": \\\"Usuário excluído logicamente com sucesso\\\"}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{user_id}/auto-renew": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna se o cliente está marcado para renovação automática, quantos meses são renovados a cada vencimento e o resultado da última tentativa.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Renovação"
+                ],
+                "summary": "Consultar Renovação Automática",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AutoRenewConfig"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Cliente não pertence à revenda",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Cliente não encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Liga ou desliga a renovação automática do cliente. Com ela ligada, o cliente é renovado pouco antes do vencimento, com o mesmo custo de /api/renew, usando os créditos da revenda dona do cliente.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Renovação"
+                ],
+                "summary": "Configurar Renovação Automática",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ativo e meses por renovação (padrão 1)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AutoRenewPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AutoRenewConfig"
+                        }
+                    },
+                    "400": {
+                        "description": "Dados inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Cliente não pertence à revenda",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Cliente não encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{user_id}/region": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Altera o campo \"forced_country\" do usuário.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Gerenciamento de Usuários"
+                ],
+                "summary": "Forçar Região para Usuário",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do Usuário",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payload com o país forçado (ex: US)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserRegionPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"message\\\": \\\"Região do usuário atualizada com sucesso\\\"}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID ou payload inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Usuário não tem permissão para alterar este usuário",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{user_id}/restore": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restaura a conta do usuário removendo os campos de exclusão lógica.",
+                "tags": [
+                    "Gerenciamento de Usuários"
+                ],
+                "summary": "Restaurar Conta",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do Usuário",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"message\\\": \\\"Usuário restaurado com sucesso\\\"}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado ou não está excluído",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{user_id}/session": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a sessão ativa do usuário da tabela \"user_activity_now\".",
+                "tags": [
+                    "Gerenciamento de Usuários"
+                ],
+                "summary": "Expulsar Usuário (Kick)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do Usuário",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"message\\\": \\\"Sessão do usuário removida com sucesso\\\"}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Usuário não tem permissão para expulsar este usuário",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{user_id}/status": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Altera o campo \"enabled\" do usuário.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Gerenciamento de Usuários"
+                ],
+                "summary": "Ativar/Desativar Conta",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do Usuário",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payload contendo o novo status do usuário",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserStatusPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"message\\\": \\\"Status do usuário atualizado com sucesso\\\"}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID ou payload inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Usuário não tem permissão para alterar este usuário",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/version": {
+            "get": {
+                "description": "Retorna a versão atual da API definida no arquivo .env",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Versão"
+                ],
+                "summary": "Obter versão da API",
+                "responses": {
+                    "200": {
+                        "description": "Versão da API",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Troca um refresh token válido por um novo par access token + refresh token (rotação). O refresh token usado deixa de valer; se um refresh token já rotacionado for reapresentado, a sessão inteira é revogada.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Autenticação"
+                ],
+                "summary": "Renovar Access Token",
+                "parameters": [
+                    {
+                        "description": "Refresh token da sessão",
+                        "name": "refresh",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tokens renovados com sucesso",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Erro na requisição",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Refresh token inválido, expirado ou reutilizado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Check the health of the service, including database and Redis connections",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health Check",
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"status\\\": \\\"OK\\\", \\\"database\\\": \\\"OK\\\", \\\"redis\\\": \\\"OK\\\"}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Exemplo: {\\\"status\\\": \\\"Error\\\", \\\"database\\\": \\\"Error: connection failed\\\", \\\"redis\\\": \\\"OK\\\"}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Autentica um usuário e abre uma nova sessão (um dispositivo), retornando um access token JWT de curta duração e um refresh token. Se a revenda tiver 2FA ativo, a resposta é um desafio (TwoFactorChallengeResponse) a ser concluído em /login/2fa.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Autenticação"
+                ],
+                "summary": "Autenticação de Usuário",
+                "parameters": [
+                    {
+                        "description": "Credenciais de login",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login realizado com sucesso",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Erro na requisição",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Credenciais inválidas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Muitas tentativas; aguarde o tempo indicado no header Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/login/2fa": {
+            "post": {
+                "description": "Conclui o login de uma revenda com 2FA ativo. Recebe o challenge_id devolvido por /login e um código TOTP de 6 dígitos ou um código de recuperação. Cada desafio aceita até 5 códigos errados.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Autenticação"
+                ],
+                "summary": "Login - Segunda Etapa (2FA)",
+                "parameters": [
+                    {
+                        "description": "Desafio e código",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorLoginPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login realizado com sucesso",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Erro na requisição",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Desafio inválido/expirado ou código incorreto",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Muitas tentativas; aguarde o tempo indicado no header Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Encerra apenas a sessão (dispositivo) do token informado; as demais sessões da revenda continuam ativas.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Logout"
+                ],
+                "summary": "Logout do Usuário",
+                "responses": {
+                    "200": {
+                        "description": "Logout realizado com sucesso",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido ou não autorizado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/password-reset": {
+            "post": {
+                "description": "Troca um token de redefinição emitido pelo admin por uma nova senha. O token é consumido no primeiro uso, todas as sessões da revenda são encerradas e todas as chaves de API são revogadas.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Conta"
+                ],
+                "summary": "Redefinir Senha com Token",
+                "parameters": [
+                    {
+                        "description": "Token de redefinição e nova senha",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PasswordResetPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exemplo: {\\\"message\\\": \\\"Senha redefinida com sucesso\\\"}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Dados inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token de redefinição inválido ou expirado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.BulkRenewItem": {
+            "type": "object",
+            "required": [
+                "id_cliente"
+            ],
+            "properties": {
+                "data_alvo": {
+                    "type": "string"
+                },
+                "id_cliente": {
+                    "type": "integer"
+                },
+                "quantidade_dias": {
+                    "type": "integer"
+                },
+                "quantidade_renovacao_em_meses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.BulkRenewRequest": {
+            "type": "object",
+            "required": [
+                "clientes",
+                "modo"
+            ],
+            "properties": {
+                "clientes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/controllers.BulkRenewItem"
+                    }
+                },
+                "modo": {
+                    "description": "tudo_ou_nada: qualquer falha desfaz todas as renovações; melhor_esforco: renova o que for possível",
+                    "type": "string",
+                    "enum": [
+                        "tudo_ou_nada",
+                        "melhor_esforco"
+                    ]
+                }
+            }
+        },
+        "controllers.ChangeDueDateRequest": {
+            "type": "object",
+            "properties": {
+                "motivo": {
+                    "type": "string"
+                },
+                "nova_data_vencimento": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.ConvertTrialRequest": {
+            "type": "object",
+            "properties": {
+                "bouquet": {
+                    "description": "Novo bouquet, ex.: \"[1, 5, 10]\" (vazio = mantém)",
+                    "type": "string"
+                },
+                "data_alvo": {
+                    "type": "string"
+                },
+                "politica": {
+                    "description": "agora | somar; padrão CONVERSAO_TESTE_POLITICA",
+                    "type": "string"
+                },
+                "quantidade_dias": {
+                    "type": "integer"
+                },
+                "quantidade_renovacao_em_meses": {
+                    "type": "integer"
+                },
+                "telas": {
+                    "description": "Novo total de telas (0 = mantém)",
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.CreateClientRequest": {
+            "type": "object",
+            "required": [
+                "quantidade_meses"
+            ],
+            "properties": {
+                "bouquet": {
+                    "description": "Ex.: \"[1, 5, 10]\"; padrão BOUQUET do .env",
+                    "type": "string"
+                },
+                "franquia_member_id": {
+                    "type": "integer"
+                },
+                "nome_para_aviso": {
+                    "type": "string"
+                },
+                "numero_whats": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "quantidade_meses": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "telas": {
+                    "description": "Padrão 1, máximo 3",
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.DashboardResponse": {
+            "type": "object",
+            "properties": {
+                "canaisOff": {
+                    "type": "integer"
+                },
+                "lastUpdated": {
+                    "description": "Ou sql.NullString se puder ser nulo",
+                    "type": "string"
+                },
+                "totalCanais": {
+                    "type": "integer"
+                },
+                "totalClientes": {
+                    "type": "integer"
+                },
+                "totalClientesRevenda": {
+                    "type": "integer"
+                },
+                "totalEpisodiosSeries": {
+                    "type": "integer"
+                },
+                "totalFilmes": {
+                    "type": "integer"
+                },
+                "totalSeries": {
+                    "type": "integer"
+                },
+                "totalTestesAtivos": {
+                    "type": "integer"
+                },
+                "totalVencido": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.ImpersonationResponse": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "Segundos até a sessão expirar (não há refresh token)",
+                    "type": "integer"
+                },
+                "member_group_id": {
+                    "type": "integer"
+                },
+                "member_id": {
+                    "type": "integer"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "somente_leitura": {
+                    "type": "boolean"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "credits": {
+                    "type": "number"
+                },
+                "expires_in": {
+                    "description": "Segundos até o access token expirar",
+                    "type": "integer"
+                },
+                "member_group_id": {
+                    "type": "integer"
+                },
+                "member_id": {
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.RefreshRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.RenewRequest": {
+            "type": "object",
+            "properties": {
+                "data_alvo": {
+                    "description": "Novo vencimento: AAAA-MM-DD (na hora padrão) ou AAAA-MM-DD HH:MM",
+                    "type": "string"
+                },
+                "id_cliente": {
+                    "type": "integer"
+                },
+                "quantidade_dias": {
+                    "description": "Renovação proporcional por dias",
+                    "type": "integer"
+                },
+                "quantidade_renovacao_em_meses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.RenewRollbackRequest": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.TestRequest": {
+            "type": "object",
+            "properties": {
+                "franquia_member_id": {
+                    "description": "Novo campo opcional",
+                    "type": "integer"
+                },
+                "nome_para_aviso": {
+                    "type": "string"
+                },
+                "numero_whats": {
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Sempre será gerado",
+                    "type": "string"
+                },
+                "username": {
+                    "description": "Pode ser opcional",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.TrustBonusRequest": {
             "type": "object",
             "properties": {
+                "dias_adicionados": {
+                    "type": "integer"
+                },
                 "motivo": {
                     "type": "string"
                 },
-                "nova_data_vencimento": {
-                    "type": "integer"
-                },
                 "user_id": {
                     "type": "integer"
                 }
             }
         },
-        "controllers.DashboardResponse": {
+        "models.AplicativoInfo": {
             "type": "object",
             "properties": {
-                "canaisOff": {
-                    "type": "integer"
+                "device_id": {
+                    "type": "string"
                 },
-                "lastUpdated": {
-                    "description": "Ou sql.NullString se puder ser nulo",
+                "mac": {
                     "type": "string"
                 },
-                "totalCanais": {
-                    "type": "integer"
+                "nome_do_aplicativo": {
+                    "type": "string"
                 },
-                "totalClientes": {
-                    "type": "integer"
+                "vencimento_aplicativo": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AutoRenewConfig": {
+            "type": "object",
+            "properties": {
+                "ativo": {
+                    "type": "boolean"
                 },
-                "totalClientesRevenda": {
+                "member_id": {
                     "type": "integer"
                 },
-                "totalEpisodiosSeries": {
+                "meses": {
                     "type": "integer"
                 },
-                "totalFilmes": {
+                "ultima_tentativa": {
+                    "description": "timestamp UNIX",
                     "type": "integer"
                 },
-                "totalSeries": {
-                    "type": "integer"
+                "ultimo_erro": {
+                    "type": "string"
                 },
-                "totalTestesAtivos": {
+                "user_id": {
                     "type": "integer"
+                }
+            }
+        },
+        "models.AutoRenewPayload": {
+            "type": "object",
+            "properties": {
+                "ativo": {
+                    "type": "boolean"
                 },
-                "totalVencido": {
-                    "type": "integer"
+                "meses": {
+                    "description": "Padrão: 1",
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 1
                 }
             }
         },
-        "controllers.LoginRequest": {
+        "models.ChangePasswordPayload": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "nova_senha",
+                "senha_atual"
             ],
             "properties": {
-                "password": {
-                    "type": "string"
+                "nova_senha": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 6
                 },
-                "username": {
+                "senha_atual": {
                     "type": "string"
                 }
             }
         },
-        "controllers.LoginResponse": {
+        "models.ClientImportJob": {
             "type": "object",
             "properties": {
-                "credits": {
+                "atualizado_em": {
+                    "type": "string"
+                },
+                "credenciais_disponiveis": {
+                    "description": "Há credenciais dos clientes criados aguardando a leitura única em /credenciais",
+                    "type": "boolean"
+                },
+                "creditos_gastos": {
                     "type": "number"
                 },
-                "member_group_id": {
+                "criadas": {
+                    "type": "integer"
+                },
+                "criado_em": {
+                    "type": "string"
+                },
+                "erro": {
+                    "type": "string"
+                },
+                "falhas": {
                     "type": "integer"
                 },
+                "id": {
+                    "type": "string"
+                },
+                "linhas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ClientImportRow"
+                    }
+                },
                 "member_id": {
                     "type": "integer"
                 },
-                "status": {
+                "processadas": {
                     "type": "integer"
                 },
-                "token": {
+                "status": {
                     "type": "string"
-                }
-            }
-        },
-        "controllers.RenewRequest": {
-            "type": "object",
-            "properties": {
-                "id_cliente": {
-                    "type": "integer"
                 },
-                "quantidade_renovacao_em_meses": {
+                "total": {
                     "type": "integer"
                 }
             }
         },
-        "controllers.RenewRollbackRequest": {
+        "models.ClientImportRow": {
             "type": "object",
             "properties": {
-                "user_id": {
+                "custo": {
+                    "type": "number"
+                },
+                "erros": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id_cliente": {
                     "type": "integer"
-                }
-            }
-        },
-        "controllers.TestRequest": {
-            "type": "object",
-            "properties": {
-                "franquia_member_id": {
-                    "description": "Novo campo opcional",
+                },
+                "linha": {
+                    "description": "Linha no arquivo (o cabeçalho é a linha 1)",
                     "type": "integer"
                 },
-                "nome_para_aviso": {
-                    "type": "string"
+                "meses": {
+                    "type": "integer"
                 },
                 "numero_whats": {
                     "type": "string"
                 },
-                "password": {
-                    "description": "Sempre será gerado",
+                "status": {
                     "type": "string"
                 },
+                "telas": {
+                    "type": "integer"
+                },
                 "username": {
-                    "description": "Pode ser opcional",
                     "type": "string"
                 }
             }
         },
-        "controllers.TrustBonusRequest": {
+        "models.CreateAPIKeyPayload": {
             "type": "object",
+            "required": [
+                "nome",
+                "scopes"
+            ],
             "properties": {
-                "dias_adicionados": {
+                "expira_dias": {
+                    "description": "Opcional: 0 = não expira",
                     "type": "integer"
                 },
-                "motivo": {
-                    "type": "string"
+                "nome": {
+                    "type": "string",
+                    "maxLength": 100
                 },
-                "user_id": {
-                    "type": "integer"
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "models.AplicativoInfo": {
+        "models.CreateSubResellerPayload": {
             "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
             "properties": {
-                "device_id": {
-                    "type": "string"
+                "credits": {
+                    "description": "Créditos transferidos da master na criação (opcional)",
+                    "type": "number"
                 },
-                "mac": {
+                "email": {
                     "type": "string"
                 },
-                "nome_do_aplicativo": {
+                "notes": {
                     "type": "string"
                 },
-                "vencimento_aplicativo": {
+                "password": {
+                    "type": "string",
+                    "minLength": 6
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 3
+                }
+            }
+        },
+        "models.CreditTransferPayload": {
+            "type": "object",
+            "required": [
+                "credits"
+            ],
+            "properties": {
+                "credits": {
+                    "type": "number"
+                },
+                "motivo": {
                     "type": "string"
                 }
             }
@@ -1854,17 +5162,127 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "password": {
-                    "type": "string",
-                    "maxLength": 15,
-                    "minLength": 4
+                    "type": "string"
                 },
                 "reseller_notes": {
                     "type": "string"
                 },
                 "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PasswordResetPayload": {
+            "type": "object",
+            "required": [
+                "nova_senha",
+                "token"
+            ],
+            "properties": {
+                "nova_senha": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 6
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PriceQuote": {
+            "type": "object",
+            "properties": {
+                "conversao_teste": {
+                    "type": "boolean"
+                },
+                "desconto": {
+                    "type": "number"
+                },
+                "detalhamento": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PriceQuoteLine"
+                    }
+                },
+                "dias": {
+                    "description": "Renovação proporcional por dias",
+                    "type": "integer"
+                },
+                "dias_restantes": {
+                    "type": "integer"
+                },
+                "meses": {
+                    "type": "integer"
+                },
+                "produto": {
+                    "type": "string"
+                },
+                "subtotal": {
+                    "type": "number"
+                },
+                "telas": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.PriceQuoteLine": {
+            "type": "object",
+            "properties": {
+                "descricao": {
+                    "type": "string"
+                },
+                "regra_id": {
+                    "description": "0 = preço padrão interno (sem regra cadastrada)",
+                    "type": "integer"
+                },
+                "valor": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.PriceQuoteRequest": {
+            "type": "object",
+            "required": [
+                "produto"
+            ],
+            "properties": {
+                "conversao_teste": {
+                    "description": "Sem user_id",
+                    "type": "boolean"
+                },
+                "data_alvo": {
+                    "description": "Renovação até a data (AAAA-MM-DD ou AAAA-MM-DD HH:MM); com user_id, conta a partir do vencimento do cliente",
+                    "type": "string"
+                },
+                "dias": {
+                    "description": "Renovação proporcional por dias (em vez de meses)",
+                    "type": "integer"
+                },
+                "dias_restantes": {
+                    "description": "Tela adicional sem user_id",
+                    "type": "integer"
+                },
+                "meses": {
+                    "description": "Renovação",
+                    "type": "integer"
+                },
+                "produto": {
                     "type": "string",
-                    "maxLength": 15,
-                    "minLength": 4
+                    "enum": [
+                        "renovacao",
+                        "tela_adicional"
+                    ]
+                },
+                "telas": {
+                    "description": "Sem user_id",
+                    "type": "integer"
+                },
+                "user_id": {
+                    "description": "Cliente: telas, dias restantes e teste são lidos dele",
+                    "type": "integer"
                 }
             }
         },
@@ -1879,6 +5297,41 @@ const docTemplate = `{
                 }
             }
         },
+        "models.TwoFactorCodePayload": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TwoFactorLoginPayload": {
+            "type": "object",
+            "required": [
+                "challenge_id",
+                "code"
+            ],
+            "properties": {
+                "challenge_id": {
+                    "type": "string"
+                },
+                "code": {
+                    "description": "Código TOTP (6 dígitos) ou código de recuperação",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UndoRequest": {
+            "type": "object",
+            "properties": {
+                "motivo": {
+                    "type": "string"
+                }
+            }
+        },
         "models.UserRegionPayload": {
             "type": "object",
             "required": [
@@ -1901,6 +5354,11 @@ const docTemplate = `{
         }
     },
     "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BearerAuth": {
             "type": "apiKey",
             "name": "Authorization",
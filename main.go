@@ -2,8 +2,10 @@ package main
 
 import (
 	"apiBackEnd/config"
+	"apiBackEnd/controllers"
 	"apiBackEnd/middleware"
 	"apiBackEnd/routes"
+	"context"
 	"log"
 	"os"
 
@@ -24,6 +26,10 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 // SetupServer inicializa e retorna o router do Gin (para uso nos testes)
 func SetupServer() *gin.Engine {
 	// Carregar variáveis de ambiente
@@ -51,6 +57,10 @@ func SetupServer() *gin.Engine {
 func main() {
 	// Inicializar o servidor real
 	r := SetupServer()
+
+	// Jobs em segundo plano (não rodam nos testes, que usam apenas SetupServer)
+	controllers.StartAutoRenewScheduler(context.Background())
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080" // Porta padrão
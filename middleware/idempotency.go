@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"apiBackEnd/config"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// Idempotência de operações faturáveis
+//
+// Com o header Idempotency-Key, a primeira resposta da rota é guardada no Redis
+// (`idempotency:<member_id>:<rota>:<chave>`) por IDEMPOTENCY_JANELA_HORAS (padrão 24).
+// Uma nova tentativa com a mesma chave e o mesmo corpo recebe a resposta guardada, sem
+// executar a operação de novo; com corpo diferente, é recusada (422). Enquanto a primeira
+// requisição ainda está em andamento (a marca é renovada enquanto ela executa), as repetições
+// recebem 409.
+// Respostas 5xx e 429 não são guardadas, para que o cliente possa tentar de novo.
+
+const (
+	IdempotencyHeader       = "Idempotency-Key"
+	idempotencyReplayHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+	// Validade da marca "processando". É renovada enquanto a requisição está em andamento, então
+	// só expira sozinha se o processo cair no meio da operação.
+	idempotencyLockTTL = 2 * time.Minute
+	// Tempo máximo para gravar o resultado no Redis depois do handler
+	idempotencyStoreTimeout = 5 * time.Second
+)
+
+type idempotencyRecord struct {
+	Estado      string `json:"estado"` // "processando" ou "concluida"
+	BodyHash    string `json:"body_hash"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// bodyCaptureWriter copia a resposta enquanto ela é enviada ao cliente
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func getIdempotencyWindow() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("IDEMPOTENCY_JANELA_HORAS"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// Idempotency aplica o Idempotency-Key na rota. Deve ser usada depois do AuthMiddleware,
+// pois as chaves são separadas por revenda.
+func Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"erro": "Idempotency-Key muito longa (máximo de 255 caracteres)"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"erro": "Erro ao ler o corpo da requisição"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		bodyHash := hex.EncodeToString(sum[:])
+
+		ctx := c.Request.Context()
+		redisKey := "idempotency:" + strconv.Itoa(c.GetInt("member_id")) + ":" + c.Request.Method + " " + c.FullPath() + ":" + key
+
+		pending, _ := json.Marshal(idempotencyRecord{Estado: "processando", BodyHash: bodyHash})
+		acquired, err := config.RedisClient.SetNX(ctx, redisKey, pending, idempotencyLockTTL).Result()
+		if err != nil {
+			log.Printf("❌ Erro ao registrar Idempotency-Key no Redis: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao processar Idempotency-Key"})
+			return
+		}
+
+		if !acquired {
+			replayIdempotentResponse(c, redisKey, bodyHash)
+			return
+		}
+
+		stopRefresh := refreshIdempotencyLock(redisKey)
+		// Se o handler entrar em pânico, o Recovery do gin responde acima deste middleware: a chave
+		// é liberada como em um 5xx, para que o cliente possa tentar de novo
+		defer func() {
+			stopRefresh()
+			if r := recover(); r != nil {
+				releaseIdempotencyKey(redisKey)
+				panic(r)
+			}
+		}()
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+		stopRefresh()
+
+		// O contexto da requisição pode já ter sido cancelado (cliente desconectou), mas o resultado
+		// da operação precisa ser gravado mesmo assim
+		storeCtx, cancel := context.WithTimeout(context.Background(), idempotencyStoreTimeout)
+		defer cancel()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			releaseIdempotencyKey(redisKey)
+			return
+		}
+		record, _ := json.Marshal(idempotencyRecord{
+			Estado:      "concluida",
+			BodyHash:    bodyHash,
+			Status:      status,
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		})
+		if err := config.RedisClient.Set(storeCtx, redisKey, record, getIdempotencyWindow()).Err(); err != nil {
+			log.Printf("⚠️ Erro ao guardar resposta idempotente (%s): %v", redisKey, err)
+		}
+	}
+}
+
+// releaseIdempotencyKey apaga a chave para que a operação possa ser repetida
+func releaseIdempotencyKey(redisKey string) {
+	ctx, cancel := context.WithTimeout(context.Background(), idempotencyStoreTimeout)
+	defer cancel()
+	if err := config.RedisClient.Del(ctx, redisKey).Err(); err != nil {
+		log.Printf("⚠️ Erro ao liberar Idempotency-Key (%s): %v", redisKey, err)
+	}
+}
+
+// refreshIdempotencyLock renova a marca "processando" a cada metade do TTL até que a função
+// retornada seja chamada, para que operações longas não liberem a chave antes de terminar.
+// A função retornada pode ser chamada mais de uma vez.
+func refreshIdempotencyLock(redisKey string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(idempotencyLockTTL / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), idempotencyStoreTimeout)
+				if err := config.RedisClient.Expire(ctx, redisKey, idempotencyLockTTL).Err(); err != nil {
+					log.Printf("⚠️ Erro ao renovar Idempotency-Key em processamento (%s): %v", redisKey, err)
+				}
+				cancel()
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-stopped
+	}
+}
+
+// replayIdempotentResponse responde a uma repetição de Idempotency-Key já registrada
+func replayIdempotentResponse(c *gin.Context, redisKey string, bodyHash string) {
+	raw, err := config.RedisClient.Get(c.Request.Context(), redisKey).Bytes()
+	if err == redis.Nil {
+		// A primeira requisição falhou e liberou a chave entre o SETNX e o GET
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"erro": "Requisição com esta Idempotency-Key ainda em processamento. Tente novamente."})
+		return
+	}
+	var record idempotencyRecord
+	if err != nil || json.Unmarshal(raw, &record) != nil {
+		log.Printf("❌ Erro ao ler Idempotency-Key %s do Redis: %v", redisKey, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao processar Idempotency-Key"})
+		return
+	}
+
+	if record.BodyHash != bodyHash {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"erro": "Idempotency-Key já utilizada com um corpo de requisição diferente"})
+		return
+	}
+	if record.Estado != "concluida" {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"erro": "Requisição com esta Idempotency-Key ainda em processamento. Tente novamente."})
+		return
+	}
+
+	contentType := record.ContentType
+	if contentType == "" {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Header(idempotencyReplayHeader, "true")
+	c.Data(record.Status, contentType, record.Body)
+	c.Abort()
+}
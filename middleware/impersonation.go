@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"apiBackEnd/utils"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonationGuard trata as requisições feitas com token de personificação: operações de
+// escrita são bloqueadas e cada acesso é registrado (api_logs.impersonation_events) com o
+// member_id do operador real. Deve ser usada depois do AuthMiddleware.
+func ImpersonationGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adminID := c.GetInt("impersonated_by")
+		if adminID == 0 {
+			c.Next()
+			return
+		}
+
+		readOnly := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions
+		entry := gin.H{
+			"admin_id":  adminID,
+			"member_id": c.GetInt("member_id"),
+			"username":  c.GetString("username"),
+			"method":    c.Request.Method,
+			"path":      c.Request.URL.Path,
+			"query":     c.Request.URL.RawQuery,
+			"ip":        c.ClientIP(),
+			"bloqueado": !readOnly,
+			"timestamp": time.Now(),
+		}
+		if err := utils.SaveToMongo("impersonation_events", entry); err != nil {
+			log.Printf("❌ Erro ao registrar acesso com personificação no MongoDB: %v", err)
+		}
+
+		if !readOnly {
+			log.Printf("🚫 Escrita bloqueada durante personificação: admin %d como member_id %d em %s %s",
+				adminID, c.GetInt("member_id"), c.Request.Method, c.FullPath())
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"erro": "Operações de escrita não são permitidas durante a personificação"})
+			return
+		}
+
+		c.Next()
+	}
+}
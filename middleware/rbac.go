@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"apiBackEnd/utils"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission bloqueia a rota quando o papel de quem está autenticado não tem a permissão.
+// Deve ser usada depois do AuthMiddleware, que grava "role" (e, para chaves de API, as
+// permissões dos scopes) no contexto.
+func RequirePermission(perm utils.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := utils.RoleNenhum
+		if value, exists := c.Get("role"); exists {
+			role, _ = value.(utils.Role)
+		}
+
+		allowed := utils.HasPermission(role, perm)
+		// Chave de API: além do papel, a permissão precisa estar nos scopes da chave
+		if value, exists := c.Get("api_key_permissions"); exists && allowed {
+			scoped, _ := value.([]utils.Permission)
+			allowed = false
+			for _, p := range scoped {
+				if p == perm {
+					allowed = true
+					break
+				}
+			}
+		}
+
+		if !allowed {
+			log.Printf("🚫 Acesso negado: member_id %v (papel '%s') sem permissão '%s' em %s %s",
+				c.GetInt("member_id"), role, perm, c.Request.Method, c.FullPath())
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"erro": "Você não tem permissão para executar esta ação"})
+			return
+		}
+
+		c.Next()
+	}
+}
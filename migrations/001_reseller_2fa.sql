@@ -0,0 +1,13 @@
+-- Segundo fator (TOTP) das revendas
+-- secret_enc: segredo TOTP cifrado com AES-256-GCM (TOTP_ENCRYPTION_KEY)
+-- recovery_codes: JSON com os SHA-256 dos códigos de recuperação ainda não usados
+-- last_used_step: último passo de 30s aceito (impede reuso do mesmo código)
+CREATE TABLE IF NOT EXISTS streamcreed_db.reseller_2fa (
+    member_id      INT          NOT NULL PRIMARY KEY,
+    secret_enc     VARCHAR(255) NOT NULL,
+    enabled        TINYINT(1)   NOT NULL DEFAULT 0,
+    recovery_codes TEXT         NULL,
+    last_used_step BIGINT       NOT NULL DEFAULT 0,
+    created_at     INT          NOT NULL, -- timestamp UNIX, como nas tabelas do painel
+    enabled_at     INT          NULL
+);
@@ -0,0 +1,18 @@
+-- Chaves de API das revendas (bots e integrações), enviadas no header X-API-Key
+-- key_hash: SHA-256 da chave completa (o valor puro só é exibido na criação)
+-- prefix: início da chave, para a revenda identificar qual é qual
+-- scopes: lista separada por vírgula (ex.: create-test,read-clients,renew)
+CREATE TABLE IF NOT EXISTS streamcreed_db.reseller_api_keys (
+    id           INT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    member_id    INT          NOT NULL,
+    name         VARCHAR(100) NOT NULL,
+    prefix       VARCHAR(16)  NOT NULL,
+    key_hash     CHAR(64)     NOT NULL,
+    scopes       VARCHAR(255) NOT NULL,
+    created_at   INT          NOT NULL, -- timestamp UNIX
+    expires_at   INT          NULL,
+    last_used_at INT          NULL,
+    revoked_at   INT          NULL,
+    UNIQUE KEY uq_reseller_api_keys_hash (key_hash),
+    KEY idx_reseller_api_keys_member (member_id)
+);
@@ -0,0 +1,19 @@
+-- Extrato de créditos das revendas: uma linha por movimentação, gravada na mesma
+-- transação que altera reg_users.credits
+-- amount: positivo = entrada, negativo = saída
+-- user_id: cliente relacionado (users.id), quando houver
+-- actor_id: revenda/admin que executou a operação (0 = sistema)
+CREATE TABLE IF NOT EXISTS streamcreed_db.reseller_credit_ledger (
+    id             BIGINT        NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    member_id      INT           NOT NULL,
+    operation      VARCHAR(40)   NOT NULL,
+    user_id        INT           NULL,
+    amount         DECIMAL(12,2) NOT NULL,
+    balance_before DECIMAL(12,2) NOT NULL,
+    balance_after  DECIMAL(12,2) NOT NULL,
+    actor_id       INT           NOT NULL,
+    description    VARCHAR(255)  NOT NULL DEFAULT '',
+    created_at     INT           NOT NULL, -- timestamp UNIX
+    KEY idx_credit_ledger_member_date (member_id, created_at),
+    KEY idx_credit_ledger_user (user_id)
+);
@@ -0,0 +1,37 @@
+-- Regras de preço de renovação e de tela adicional
+-- member_id NULL = regra padrão; com member_id, vale só para a revenda e tem prioridade sobre as padrão
+-- product: renovacao | tela_adicional
+-- mode:
+--   por_mes_tela      value × meses × telas (renovação)
+--   por_periodo_30d   value × ceil(dias restantes / 30) (tela adicional)
+--   fixo              value
+--   desconto          discount_percent sobre o subtotal (promoções, conversão de teste)
+-- Filtros opcionais (NULL = qualquer): meses, telas, dias restantes, conversão de teste e vigência
+CREATE TABLE IF NOT EXISTS streamcreed_db.pricing_rules (
+    id               INT           NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    member_id        INT           NULL,
+    product          VARCHAR(20)   NOT NULL,
+    mode             VARCHAR(20)   NOT NULL,
+    value            DECIMAL(10,2) NOT NULL DEFAULT 0,
+    discount_percent DECIMAL(5,2)  NOT NULL DEFAULT 0,
+    months_min       INT           NULL,
+    months_max       INT           NULL,
+    screens_min      INT           NULL,
+    screens_max      INT           NULL,
+    days_left_min    INT           NULL,
+    days_left_max    INT           NULL,
+    trial_conversion TINYINT(1)    NULL,
+    valid_from       INT           NULL, -- timestamp UNIX
+    valid_until      INT           NULL, -- timestamp UNIX
+    priority         INT           NOT NULL DEFAULT 0,
+    description      VARCHAR(255)  NOT NULL DEFAULT '',
+    active           TINYINT(1)    NOT NULL DEFAULT 1,
+    KEY idx_pricing_rules_lookup (product, active, member_id)
+);
+
+-- Regras padrão equivalentes aos preços fixos usados até aqui
+INSERT INTO streamcreed_db.pricing_rules (member_id, product, mode, value, days_left_min, days_left_max, description) VALUES
+    (NULL, 'renovacao',      'por_mes_tela',    1.00, NULL, NULL, '1 crédito por mês por tela'),
+    (NULL, 'tela_adicional', 'fixo',            0.50, NULL, 15,   'Tela adicional com até 15 dias restantes'),
+    (NULL, 'tela_adicional', 'fixo',            1.00, 16,   30,   'Tela adicional com 16 a 30 dias restantes'),
+    (NULL, 'tela_adicional', 'por_periodo_30d', 1.00, 31,   NULL, 'Tela adicional: 1 crédito a cada 30 dias restantes');
@@ -0,0 +1,15 @@
+-- Renovação automática de clientes: a revenda marca o cliente e um job em segundo plano o
+-- renova pouco antes do vencimento, cobrando os créditos da revenda (mesmo custo de /api/renew)
+-- months: meses renovados a cada vencimento
+-- last_attempt_at / last_error: última tentativa do job (sucesso limpa o erro)
+CREATE TABLE IF NOT EXISTS streamcreed_db.client_auto_renew (
+    user_id         INT          NOT NULL PRIMARY KEY,
+    member_id       INT          NOT NULL,
+    months          INT          NOT NULL DEFAULT 1,
+    active          TINYINT(1)   NOT NULL DEFAULT 1,
+    last_attempt_at INT          NULL,     -- timestamp UNIX
+    last_error      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at      INT          NOT NULL, -- timestamp UNIX
+    updated_at      INT          NOT NULL, -- timestamp UNIX
+    KEY idx_client_auto_renew_active (active, member_id)
+);
@@ -0,0 +1,8 @@
+-- Índices para /api/clients-table, que filtra, ordena e pagina no banco:
+-- vencimento e criação por revenda (ordenação e faixas de vencimento, com id para o cursor)
+-- e user_activity_now.user_id para o filtro online
+-- Tabelas do painel: aplicar uma única vez (CREATE INDEX não aceita IF NOT EXISTS no MySQL)
+CREATE INDEX idx_users_member_exp_date ON streamcreed_db.users (member_id, exp_date, id);
+CREATE INDEX idx_users_member_created_at ON streamcreed_db.users (member_id, created_at, id);
+CREATE INDEX idx_users_member_username ON streamcreed_db.users (member_id, username);
+CREATE INDEX idx_user_activity_now_user ON streamcreed_db.user_activity_now (user_id);
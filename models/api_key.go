@@ -0,0 +1,147 @@
+package models
+
+import (
+	"apiBackEnd/config"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// APIKey representa uma chave de API de revenda (tabela streamcreed_db.reseller_api_keys)
+type APIKey struct {
+	ID         int      `json:"id"`
+	MemberID   int      `json:"member_id"`
+	Name       string   `json:"nome"`
+	Prefix     string   `json:"prefixo"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  int64    `json:"criado_em"`             // Timestamp UNIX
+	ExpiresAt  *int64   `json:"expira_em,omitempty"`   // Timestamp UNIX
+	LastUsedAt *int64   `json:"ultimo_uso,omitempty"`  // Timestamp UNIX
+	RevokedAt  *int64   `json:"revogada_em,omitempty"` // Timestamp UNIX
+}
+
+// CreateAPIKeyPayload é usado para criar uma chave de API
+type CreateAPIKeyPayload struct {
+	Name       string   `json:"nome" binding:"required,max=100"`
+	Scopes     []string `json:"scopes" binding:"required,min=1"`
+	ExpiraDias int      `json:"expira_dias"` // Opcional: 0 = não expira
+}
+
+const apiKeyColumns = "id, member_id, name, prefix, scopes, created_at, expires_at, last_used_at, revoked_at"
+
+func scanAPIKey(scanner interface{ Scan(...interface{}) error }) (*APIKey, error) {
+	var (
+		key                              APIKey
+		scopes                           string
+		expiresAt, lastUsedAt, revokedAt sql.NullInt64
+	)
+	if err := scanner.Scan(&key.ID, &key.MemberID, &key.Name, &key.Prefix, &scopes,
+		&key.CreatedAt, &expiresAt, &lastUsedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	key.Scopes = strings.Split(scopes, ",")
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Int64
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Int64
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Int64
+	}
+	return &key, nil
+}
+
+// GetActiveAPIKeyByHash busca uma chave não revogada e não expirada pelo hash
+func GetActiveAPIKeyByHash(keyHash string) (*APIKey, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("conexão com banco de dados não inicializada")
+	}
+	row := config.DB.QueryRow(`
+		SELECT `+apiKeyColumns+`
+		FROM streamcreed_db.reseller_api_keys
+		WHERE key_hash = ? AND revoked_at IS NULL
+		AND (expires_at IS NULL OR expires_at > UNIX_TIMESTAMP())`, keyHash)
+	return scanAPIKey(row)
+}
+
+// ListAPIKeys lista as chaves da revenda (inclusive revogadas), mais recentes primeiro
+func ListAPIKeys(memberID int) ([]APIKey, error) {
+	rows, err := config.DB.Query(`
+		SELECT `+apiKeyColumns+`
+		FROM streamcreed_db.reseller_api_keys
+		WHERE member_id = ?
+		ORDER BY id DESC`, memberID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+// CountActiveAPIKeys conta as chaves válidas da revenda
+func CountActiveAPIKeys(memberID int) (int, error) {
+	var total int
+	err := config.DB.QueryRow(`
+		SELECT COUNT(*) FROM streamcreed_db.reseller_api_keys
+		WHERE member_id = ? AND revoked_at IS NULL
+		AND (expires_at IS NULL OR expires_at > UNIX_TIMESTAMP())`, memberID).Scan(&total)
+	return total, err
+}
+
+// InsertAPIKey grava uma nova chave e retorna o ID
+func InsertAPIKey(memberID int, name, prefix, keyHash string, scopes []string, expiresAt *int64) (int, error) {
+	result, err := config.DB.Exec(`
+		INSERT INTO streamcreed_db.reseller_api_keys (member_id, name, prefix, key_hash, scopes, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, UNIX_TIMESTAMP(), ?)`,
+		memberID, name, prefix, keyHash, strings.Join(scopes, ","), expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// RevokeAPIKey revoga uma chave da revenda. Retorna false se não existir ou já estiver revogada.
+func RevokeAPIKey(memberID, keyID int) (bool, error) {
+	result, err := config.DB.Exec(`
+		UPDATE streamcreed_db.reseller_api_keys
+		SET revoked_at = UNIX_TIMESTAMP()
+		WHERE id = ? AND member_id = ? AND revoked_at IS NULL`, keyID, memberID)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows == 1, nil
+}
+
+// RevokeAllAPIKeys revoga todas as chaves ativas da revenda e retorna quantas foram revogadas
+func RevokeAllAPIKeys(memberID int) (int, error) {
+	result, err := config.DB.Exec(`
+		UPDATE streamcreed_db.reseller_api_keys
+		SET revoked_at = UNIX_TIMESTAMP()
+		WHERE member_id = ? AND revoked_at IS NULL`, memberID)
+	if err != nil {
+		return 0, err
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
+
+// TouchAPIKey registra o último uso da chave (no máximo uma gravação por minuto)
+func TouchAPIKey(keyID int) error {
+	_, err := config.DB.Exec(`
+		UPDATE streamcreed_db.reseller_api_keys
+		SET last_used_at = UNIX_TIMESTAMP()
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < UNIX_TIMESTAMP() - 60)`, keyID)
+	return err
+}
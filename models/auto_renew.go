@@ -0,0 +1,110 @@
+package models
+
+import (
+	"apiBackEnd/config"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// AutoRenewConfig é a configuração de renovação automática de um cliente
+type AutoRenewConfig struct {
+	UserID          int    `json:"user_id"`
+	MemberID        int    `json:"member_id"`
+	Meses           int    `json:"meses"`
+	Ativo           bool   `json:"ativo"`
+	UltimaTentativa *int64 `json:"ultima_tentativa,omitempty"` // timestamp UNIX
+	UltimoErro      string `json:"ultimo_erro,omitempty"`
+}
+
+// AutoRenewPayload liga ou desliga a renovação automática de um cliente
+type AutoRenewPayload struct {
+	Ativo bool `json:"ativo"`
+	Meses int  `json:"meses" binding:"omitempty,min=1,max=12"` // Padrão: 1
+}
+
+// AutoRenewDue é um cliente com renovação automática próximo do vencimento
+type AutoRenewDue struct {
+	UserID   int
+	MemberID int
+	Meses    int
+	ExpDate  int64
+}
+
+// GetAutoRenew retorna a configuração do cliente (sql.ErrNoRows se nunca foi configurada)
+func GetAutoRenew(userID int) (*AutoRenewConfig, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("conexão com banco de dados não inicializada")
+	}
+	cfg := &AutoRenewConfig{}
+	var ultimaTentativa sql.NullInt64
+	err := config.DB.QueryRow(`
+		SELECT user_id, member_id, months, active, last_attempt_at, last_error
+		FROM streamcreed_db.client_auto_renew WHERE user_id = ?`, userID).
+		Scan(&cfg.UserID, &cfg.MemberID, &cfg.Meses, &cfg.Ativo, &ultimaTentativa, &cfg.UltimoErro)
+	if err != nil {
+		return nil, err
+	}
+	if ultimaTentativa.Valid {
+		cfg.UltimaTentativa = &ultimaTentativa.Int64
+	}
+	return cfg, nil
+}
+
+// SaveAutoRenew grava a configuração do cliente. member_id é o dono do cliente, cujos créditos serão cobrados.
+func SaveAutoRenew(userID, memberID, meses int, ativo bool) error {
+	if config.DB == nil {
+		return fmt.Errorf("conexão com banco de dados não inicializada")
+	}
+	now := time.Now().Unix()
+	_, err := config.DB.Exec(`
+		INSERT INTO streamcreed_db.client_auto_renew (user_id, member_id, months, active, last_error, created_at, updated_at)
+		VALUES (?, ?, ?, ?, '', ?, ?)
+		ON DUPLICATE KEY UPDATE member_id = VALUES(member_id), months = VALUES(months), active = VALUES(active),
+			last_error = '', updated_at = VALUES(updated_at)`,
+		userID, memberID, meses, ativo, now, now)
+	return err
+}
+
+// ListDueAutoRenewals lista os clientes ativos que vencem até `ate` (e venceram depois de `desde`),
+// ignorando os que tiveram uma tentativa depois de `tentativaAntesDe`
+func ListDueAutoRenewals(desde, ate, tentativaAntesDe int64, limite int) ([]AutoRenewDue, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("conexão com banco de dados não inicializada")
+	}
+	rows, err := config.DB.Query(`
+		SELECT ar.user_id, u.member_id, ar.months, u.exp_date
+		FROM streamcreed_db.client_auto_renew ar
+		JOIN streamcreed_db.users u ON u.id = ar.user_id
+		WHERE ar.active = 1
+		  AND u.deleted != '1'
+		  AND u.exp_date > ? AND u.exp_date <= ?
+		  AND (ar.last_attempt_at IS NULL OR ar.last_attempt_at < ?)
+		ORDER BY u.member_id, u.exp_date
+		LIMIT ?`, desde, ate, tentativaAntesDe, limite)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []AutoRenewDue
+	for rows.Next() {
+		var d AutoRenewDue
+		if err := rows.Scan(&d.UserID, &d.MemberID, &d.Meses, &d.ExpDate); err != nil {
+			return nil, err
+		}
+		due = append(due, d)
+	}
+	return due, rows.Err()
+}
+
+// RecordAutoRenewAttempt grava o resultado da última tentativa (erro vazio = sucesso)
+func RecordAutoRenewAttempt(userID int, erro string) error {
+	if len(erro) > 255 {
+		erro = erro[:255]
+	}
+	_, err := config.DB.Exec(`
+		UPDATE streamcreed_db.client_auto_renew SET last_attempt_at = ?, last_error = ? WHERE user_id = ?`,
+		time.Now().Unix(), erro, userID)
+	return err
+}
@@ -0,0 +1,31 @@
+package models
+
+// Tipos de evento do histórico de renovações de um cliente
+const (
+	HistoricoCriacaoCliente      = "criacao_cliente"
+	HistoricoConversaoTeste      = "conversao_teste"
+	HistoricoRenovacao           = "renovacao"
+	HistoricoRenovacaoAutomatica = "renovacao_automatica"
+	HistoricoReversaoRenovacao   = "reversao_renovacao"
+	HistoricoBonusConfianca      = "bonus_confianca"
+	HistoricoAlteracaoVencimento = "alteracao_vencimento"
+	HistoricoOperacaoDesfeita    = "operacao_desfeita"
+)
+
+// ClientHistoryEvent é um evento que alterou o vencimento de um cliente.
+// Datas são timestamps UNIX; campos sem informação no log de origem são omitidos.
+type ClientHistoryEvent struct {
+	Tipo            string   `json:"tipo"`
+	OcorridoEm      int64    `json:"ocorrido_em"`
+	ExpDateAnterior *int64   `json:"exp_date_anterior,omitempty"`
+	NovoExpDate     *int64   `json:"novo_exp_date,omitempty"`
+	Creditos        *float64 `json:"creditos,omitempty"` // Gastos na renovação ou devolvidos na reversão
+	Dias            *int     `json:"dias,omitempty"`     // Bônus de confiança
+	DiaVencimento   *int     `json:"dia_vencimento,omitempty"`
+	TipoDesfeito    string   `json:"tipo_desfeito,omitempty"` // Operação desfeita (/api/undo)
+	MemberID        int      `json:"member_id,omitempty"`     // Revenda cobrada na renovação
+	ExecutadoPor    string   `json:"executado_por,omitempty"`
+	Motivo          string   `json:"motivo,omitempty"`
+	Sucesso         *bool    `json:"sucesso,omitempty"` // Renovação automática
+	Erro            string   `json:"erro,omitempty"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Estados da importação de clientes
+const (
+	ImportacaoPendente     = "pendente"
+	ImportacaoProcessando  = "processando"
+	ImportacaoConcluida    = "concluida"
+	ImportacaoInterrompida = "interrompida" // Erro inesperado no meio da importação
+)
+
+// Estados de cada linha importada
+const (
+	LinhaValida        = "valida"
+	LinhaInvalida      = "invalida"
+	LinhaCriada        = "criada"
+	LinhaFalhou        = "falhou"
+	LinhaNaoProcessada = "nao_processada" // Importação interrompida (ex.: créditos esgotados)
+)
+
+// ClientImportRow é o resultado de uma linha do CSV (a senha nunca é guardada)
+type ClientImportRow struct {
+	Linha       int      `json:"linha"` // Linha no arquivo (o cabeçalho é a linha 1)
+	Username    string   `json:"username"`
+	Meses       int      `json:"meses"`
+	Telas       int      `json:"telas"`
+	NumeroWhats string   `json:"numero_whats,omitempty"`
+	Custo       float64  `json:"custo"`
+	Status      string   `json:"status"`
+	Erros       []string `json:"erros,omitempty"`
+	IDCliente   int      `json:"id_cliente,omitempty"`
+}
+
+// ClientImportJob é uma importação de clientes em segundo plano
+type ClientImportJob struct {
+	ID             string    `json:"id"`
+	MemberID       int       `json:"member_id"`
+	Status         string    `json:"status"`
+	Total          int       `json:"total"`
+	Processadas    int       `json:"processadas"`
+	Criadas        int       `json:"criadas"`
+	Falhas         int       `json:"falhas"`
+	CreditosGastos float64   `json:"creditos_gastos"`
+	CriadoEm       time.Time `json:"criado_em"`
+	AtualizadoEm   time.Time `json:"atualizado_em"`
+	Erro           string    `json:"erro,omitempty"`
+	// Há credenciais dos clientes criados aguardando a leitura única em /credenciais
+	CredenciaisDisponiveis bool              `json:"credenciais_disponiveis"`
+	Linhas                 []ClientImportRow `json:"linhas"`
+}
+
+// ClientImportCredential é o login de um cliente criado pela importação. Fica no Redis só até ser
+// lido uma vez pela revenda (ou expirar), nunca no andamento da importação.
+type ClientImportCredential struct {
+	Linha     int    `json:"linha"`
+	IDCliente int    `json:"id_cliente"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+}
@@ -0,0 +1,247 @@
+package models
+
+import (
+	"apiBackEnd/config"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Extrato de créditos (reseller_credit_ledger)
+//
+// Toda alteração de reg_users.credits passa por ApplyCreditMovement, que trava o saldo da
+// revenda (SELECT ... FOR UPDATE), aplica a movimentação e grava a linha do extrato com o
+// saldo antes/depois, tudo na transação de quem chama.
+
+// Operações registradas no extrato
+const (
+	LedgerRenovacao             = "renovacao"
+	LedgerCriacaoCliente        = "criacao_cliente"
+	LedgerConversaoTeste        = "conversao_teste"
+	LedgerReversaoRenovacao     = "reversao_renovacao"
+	LedgerTelaAdicional         = "tela_adicional"
+	LedgerReversaoTelaAdicional = "reversao_tela_adicional"
+	LedgerReembolsoTela         = "reembolso_tela"
+	LedgerTransferenciaEnviada  = "transferencia_enviada"
+	LedgerTransferenciaRecebida = "transferencia_recebida"
+)
+
+var ErrCreditosInsuficientes = errors.New("créditos insuficientes")
+
+// InsufficientCreditsError informa o saldo disponível e o valor necessário quando um débito é recusado.
+// errors.Is(err, ErrCreditosInsuficientes) continua valendo.
+type InsufficientCreditsError struct {
+	Disponivel float64
+	Necessario float64
+}
+
+func (e *InsufficientCreditsError) Error() string {
+	return fmt.Sprintf("créditos insuficientes: disponível %.2f, necessário %.2f", e.Disponivel, e.Necessario)
+}
+
+func (e *InsufficientCreditsError) Is(target error) bool {
+	return target == ErrCreditosInsuficientes
+}
+
+// CreditMovement descreve uma movimentação a ser aplicada no saldo da revenda
+type CreditMovement struct {
+	MemberID  int     // Revenda cujo saldo muda
+	Operacao  string  // Uma das constantes Ledger*
+	UserID    int     // Cliente relacionado (0 = nenhum)
+	Valor     float64 // Positivo = entrada, negativo = saída
+	ActorID   int     // Quem executou a operação
+	Descricao string
+}
+
+// CreditLedgerEntry é uma linha do extrato de créditos
+type CreditLedgerEntry struct {
+	ID          int64   `json:"id"`
+	MemberID    int     `json:"member_id"`
+	Operacao    string  `json:"operacao"`
+	UserID      *int    `json:"user_id,omitempty"`
+	Valor       float64 `json:"valor"`
+	SaldoAntes  float64 `json:"saldo_antes"`
+	SaldoDepois float64 `json:"saldo_depois"`
+	ActorID     int     `json:"actor_id"`
+	Descricao   string  `json:"descricao"`
+	CriadoEm    int64   `json:"criado_em"`
+}
+
+// CreditLedgerFilter filtra a listagem do extrato
+type CreditLedgerFilter struct {
+	MemberID   int
+	Operacao   string
+	UserID     int
+	DataInicio int64 // timestamp UNIX (0 = sem limite)
+	DataFim    int64 // timestamp UNIX (0 = sem limite)
+	Limit      int
+	Offset     int
+}
+
+// ApplyCreditMovement altera o saldo da revenda e grava a movimentação no extrato, dentro de tx.
+// Retorna ErrCreditosInsuficientes se uma saída deixaria o saldo negativo.
+func ApplyCreditMovement(tx *sql.Tx, m CreditMovement) (*CreditLedgerEntry, error) {
+	// Trava a linha da revenda: débitos concorrentes esperam este terminar
+	var saldoAntes float64
+	err := tx.QueryRow("SELECT credits FROM streamcreed_db.reg_users WHERE id = ? FOR UPDATE", m.MemberID).Scan(&saldoAntes)
+	if err != nil {
+		return nil, err
+	}
+	if m.Valor < 0 && saldoAntes+m.Valor < 0 {
+		return nil, &InsufficientCreditsError{Disponivel: saldoAntes, Necessario: -m.Valor}
+	}
+
+	// Débito condicional: mesmo sem o lock acima, o saldo nunca fica negativo
+	if m.Valor != 0 {
+		result, err := tx.Exec("UPDATE streamcreed_db.reg_users SET credits = credits + ? WHERE id = ? AND credits + ? >= 0", m.Valor, m.MemberID, m.Valor)
+		if err != nil {
+			return nil, err
+		}
+		if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+			return nil, &InsufficientCreditsError{Disponivel: saldoAntes, Necessario: -m.Valor}
+		}
+	}
+
+	entry := &CreditLedgerEntry{
+		MemberID:    m.MemberID,
+		Operacao:    m.Operacao,
+		Valor:       m.Valor,
+		SaldoAntes:  saldoAntes,
+		SaldoDepois: saldoAntes + m.Valor,
+		ActorID:     m.ActorID,
+		Descricao:   m.Descricao,
+		CriadoEm:    time.Now().Unix(),
+	}
+	var userID sql.NullInt64
+	if m.UserID > 0 {
+		userID = sql.NullInt64{Int64: int64(m.UserID), Valid: true}
+		entry.UserID = &m.UserID
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO streamcreed_db.reseller_credit_ledger
+			(member_id, operation, user_id, amount, balance_before, balance_after, actor_id, description, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.MemberID, entry.Operacao, userID, entry.Valor, entry.SaldoAntes, entry.SaldoDepois, entry.ActorID, entry.Descricao, entry.CriadoEm)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gravar extrato de créditos: %w", err)
+	}
+	entry.ID, _ = result.LastInsertId()
+	return entry, nil
+}
+
+// DebitCredits reserva e debita `custo` créditos da revenda dentro de tx (m.Valor é ignorado).
+// É o único caminho para cobrar operações faturáveis; em saldo insuficiente retorna
+// *InsufficientCreditsError e nada é alterado.
+func DebitCredits(tx *sql.Tx, custo float64, m CreditMovement) (*CreditLedgerEntry, error) {
+	if custo < 0 {
+		return nil, fmt.Errorf("custo inválido: %.2f", custo)
+	}
+	m.Valor = -custo
+	return ApplyCreditMovement(tx, m)
+}
+
+// ScreenRefundCap retorna, dentro de tx, a compra de tela adicional do cliente que ainda pode ser
+// devolvida: a mais recente desde `desde` que não foi consumida por uma devolução ou reversão. Cada
+// devolução ou reversão consome uma compra (a mais recente em aberto no momento), então remover
+// várias telas devolve no máximo o que foi pago por cada uma, e não o total da janela.
+// Retorna a revenda que pagou e o valor pago; memberID = 0 quando não há compra em aberto.
+func ScreenRefundCap(tx *sql.Tx, userID int, desde int64) (int, float64, error) {
+	rows, err := tx.Query(`
+		SELECT member_id, operation, amount FROM streamcreed_db.reseller_credit_ledger
+		WHERE user_id = ? AND operation IN (?, ?, ?) AND created_at >= ?
+		ORDER BY id`, userID, LedgerTelaAdicional, LedgerReversaoTelaAdicional, LedgerReembolsoTela, desde)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+
+	type compra struct {
+		memberID int
+		valor    float64
+	}
+	var emAberto []compra // Pilha: a compra mais recente fica no topo
+	for rows.Next() {
+		var memberID int
+		var operacao string
+		var valor float64
+		if err := rows.Scan(&memberID, &operacao, &valor); err != nil {
+			return 0, 0, err
+		}
+		if operacao == LedgerTelaAdicional {
+			emAberto = append(emAberto, compra{memberID, -valor}) // Débitos são negativos
+		} else if len(emAberto) > 0 {
+			emAberto = emAberto[:len(emAberto)-1]
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+	if len(emAberto) == 0 {
+		return 0, 0, nil
+	}
+	ultima := emAberto[len(emAberto)-1]
+	return ultima.memberID, math.Max(ultima.valor, 0), nil
+}
+
+// ListCreditLedger retorna as movimentações da revenda (mais recentes primeiro) e o total filtrado
+func ListCreditLedger(f CreditLedgerFilter) ([]CreditLedgerEntry, int, error) {
+	if config.DB == nil {
+		return nil, 0, fmt.Errorf("conexão com banco de dados não inicializada")
+	}
+
+	conditions := []string{"member_id = ?"}
+	args := []interface{}{f.MemberID}
+	if f.Operacao != "" {
+		conditions = append(conditions, "operation = ?")
+		args = append(args, f.Operacao)
+	}
+	if f.UserID > 0 {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, f.UserID)
+	}
+	if f.DataInicio > 0 {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, f.DataInicio)
+	}
+	if f.DataFim > 0 {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, f.DataFim)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := config.DB.QueryRow("SELECT COUNT(*) FROM streamcreed_db.reseller_credit_ledger WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := config.DB.Query(`
+		SELECT id, member_id, operation, user_id, amount, balance_before, balance_after, actor_id, description, created_at
+		FROM streamcreed_db.reseller_credit_ledger
+		WHERE `+where+`
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?`, append(args, f.Limit, f.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []CreditLedgerEntry{}
+	for rows.Next() {
+		var entry CreditLedgerEntry
+		var userID sql.NullInt64
+		if err := rows.Scan(&entry.ID, &entry.MemberID, &entry.Operacao, &userID, &entry.Valor,
+			&entry.SaldoAntes, &entry.SaldoDepois, &entry.ActorID, &entry.Descricao, &entry.CriadoEm); err != nil {
+			return nil, 0, err
+		}
+		if userID.Valid {
+			id := int(userID.Int64)
+			entry.UserID = &id
+		}
+		entries = append(entries, entry)
+	}
+	return entries, total, rows.Err()
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"apiBackEnd/config"
+	"database/sql"
+	"fmt"
+)
+
+// Produtos cobrados em créditos
+const (
+	ProdutoRenovacao     = "renovacao"
+	ProdutoTelaAdicional = "tela_adicional"
+)
+
+// Modos de cálculo das regras de preço (ver migrations/004_pricing_rules.sql)
+const (
+	PrecoPorMesTela    = "por_mes_tela"
+	PrecoPorPeriodo30d = "por_periodo_30d"
+	PrecoFixo          = "fixo"
+	PrecoModoDesconto  = "desconto"
+)
+
+// PricingRule é uma regra de preço (padrão, com MemberID nil, ou específica de uma revenda)
+type PricingRule struct {
+	ID                 int     `json:"id"`
+	MemberID           *int    `json:"member_id,omitempty"`
+	Produto            string  `json:"produto"`
+	Modo               string  `json:"modo"`
+	Valor              float64 `json:"valor"`
+	DescontoPercentual float64 `json:"desconto_percentual"`
+	MesesMin           *int    `json:"meses_min,omitempty"`
+	MesesMax           *int    `json:"meses_max,omitempty"`
+	TelasMin           *int    `json:"telas_min,omitempty"`
+	TelasMax           *int    `json:"telas_max,omitempty"`
+	DiasRestantesMin   *int    `json:"dias_restantes_min,omitempty"`
+	DiasRestantesMax   *int    `json:"dias_restantes_max,omitempty"`
+	ConversaoTeste     *bool   `json:"conversao_teste,omitempty"`
+	VigenciaInicio     *int64  `json:"vigencia_inicio,omitempty"`
+	VigenciaFim        *int64  `json:"vigencia_fim,omitempty"`
+	Prioridade         int     `json:"prioridade"`
+	Descricao          string  `json:"descricao"`
+}
+
+// PriceQuoteLine é uma linha do detalhamento do orçamento
+type PriceQuoteLine struct {
+	Descricao string  `json:"descricao"`
+	RegraID   int     `json:"regra_id,omitempty"` // 0 = preço padrão interno (sem regra cadastrada)
+	Valor     float64 `json:"valor"`
+}
+
+// PriceQuote é o custo calculado de uma operação, antes de qualquer cobrança
+type PriceQuote struct {
+	Produto        string           `json:"produto"`
+	Meses          int              `json:"meses,omitempty"`
+	Dias           int              `json:"dias,omitempty"` // Renovação proporcional por dias
+	Telas          int              `json:"telas"`
+	DiasRestantes  int              `json:"dias_restantes,omitempty"`
+	ConversaoTeste bool             `json:"conversao_teste"`
+	Subtotal       float64          `json:"subtotal"`
+	Desconto       float64          `json:"desconto"`
+	Total          float64          `json:"total"`
+	Detalhamento   []PriceQuoteLine `json:"detalhamento"`
+}
+
+// PriceQuoteRequest é o corpo de /api/pricing/quote
+type PriceQuoteRequest struct {
+	Produto        string `json:"produto" binding:"required,oneof=renovacao tela_adicional"`
+	UserID         int    `json:"user_id"`         // Cliente: telas, dias restantes e teste são lidos dele
+	Meses          int    `json:"meses"`           // Renovação
+	Dias           int    `json:"dias"`            // Renovação proporcional por dias (em vez de meses)
+	DataAlvo       string `json:"data_alvo"`       // Renovação até a data (AAAA-MM-DD ou AAAA-MM-DD HH:MM); com user_id, conta a partir do vencimento do cliente
+	Telas          int    `json:"telas"`           // Sem user_id
+	DiasRestantes  int    `json:"dias_restantes"`  // Tela adicional sem user_id
+	ConversaoTeste bool   `json:"conversao_teste"` // Sem user_id
+}
+
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	i := int(v.Int64)
+	return &i
+}
+
+func nullInt64Ptr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	i := v.Int64
+	return &i
+}
+
+// ListPricingRules retorna as regras ativas do produto que valem para a revenda
+// (padrão + específicas), das específicas para as padrão e por prioridade
+func ListPricingRules(memberID int, produto string) ([]PricingRule, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("conexão com banco de dados não inicializada")
+	}
+
+	rows, err := config.DB.Query(`
+		SELECT id, member_id, product, mode, value, discount_percent, months_min, months_max,
+			screens_min, screens_max, days_left_min, days_left_max, trial_conversion,
+			valid_from, valid_until, priority, description
+		FROM streamcreed_db.pricing_rules
+		WHERE product = ? AND active = 1 AND (member_id IS NULL OR member_id = ?)
+		ORDER BY member_id IS NULL, priority DESC, id`, produto, memberID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []PricingRule{}
+	for rows.Next() {
+		var rule PricingRule
+		var memberIDCol, mesesMin, mesesMax, telasMin, telasMax, diasMin, diasMax, conversao, inicio, fim sql.NullInt64
+		if err := rows.Scan(&rule.ID, &memberIDCol, &rule.Produto, &rule.Modo, &rule.Valor, &rule.DescontoPercentual,
+			&mesesMin, &mesesMax, &telasMin, &telasMax, &diasMin, &diasMax, &conversao,
+			&inicio, &fim, &rule.Prioridade, &rule.Descricao); err != nil {
+			return nil, err
+		}
+		rule.MemberID = nullIntPtr(memberIDCol)
+		rule.MesesMin, rule.MesesMax = nullIntPtr(mesesMin), nullIntPtr(mesesMax)
+		rule.TelasMin, rule.TelasMax = nullIntPtr(telasMin), nullIntPtr(telasMax)
+		rule.DiasRestantesMin, rule.DiasRestantesMax = nullIntPtr(diasMin), nullIntPtr(diasMax)
+		if conversao.Valid {
+			v := conversao.Int64 == 1
+			rule.ConversaoTeste = &v
+		}
+		rule.VigenciaInicio, rule.VigenciaFim = nullInt64Ptr(inicio), nullInt64Ptr(fim)
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"apiBackEnd/config"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Hierarquia de revendas
+//
+// A árvore usa reg_users.owner_id: uma sub-revenda aponta para a revenda master que a criou.
+// Uma revenda pode agir sobre as próprias sub-revendas e, recursivamente, sobre as sub-revendas delas.
+
+// maxResellerDepth limita a profundidade percorrida (proteção contra ciclos em owner_id)
+const maxResellerDepth = 10
+
+// SubReseller representa uma sub-revenda na árvore de uma revenda master
+type SubReseller struct {
+	ID            int     `json:"id"`
+	Username      string  `json:"username"`
+	OwnerID       int     `json:"owner_id"`
+	MemberGroupID int     `json:"member_group_id"`
+	Credits       float64 `json:"credits"`
+	Status        int     `json:"status"`
+	Nivel         int     `json:"nivel"` // 1 = sub-revenda direta
+}
+
+// CreateSubResellerPayload é usado para criar uma sub-revenda
+type CreateSubResellerPayload struct {
+	Username string  `json:"username" binding:"required,min=3,max=50"`
+	Password string  `json:"password" binding:"required,min=6"`
+	Email    string  `json:"email"`
+	Notes    string  `json:"notes"`
+	Credits  float64 `json:"credits"` // Créditos transferidos da master na criação (opcional)
+}
+
+// CreditTransferPayload é usado para transferir créditos para uma sub-revenda
+type CreditTransferPayload struct {
+	Credits float64 `json:"credits" binding:"required,gt=0"`
+	Motivo  string  `json:"motivo"`
+}
+
+// GetResellerOwnerID retorna o owner_id da revenda (0 se não tiver master)
+func GetResellerOwnerID(memberID int) (int, error) {
+	var ownerID sql.NullInt64
+	err := config.DB.QueryRow("SELECT owner_id FROM streamcreed_db.reg_users WHERE id = ?", memberID).Scan(&ownerID)
+	if err != nil {
+		return 0, err
+	}
+	return int(ownerID.Int64), nil
+}
+
+// IsSubReseller informa se memberID está abaixo de ancestorID na árvore (em qualquer nível)
+func IsSubReseller(ancestorID, memberID int) (bool, error) {
+	if config.DB == nil {
+		return false, fmt.Errorf("conexão com banco de dados não inicializada")
+	}
+	current := memberID
+	for depth := 0; depth < maxResellerDepth; depth++ {
+		ownerID, err := GetResellerOwnerID(current)
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if ownerID <= 0 || ownerID == current {
+			return false, nil
+		}
+		if ownerID == ancestorID {
+			return true, nil
+		}
+		current = ownerID
+	}
+	return false, nil
+}
+
+// ListSubResellers retorna todas as sub-revendas abaixo de memberID, nível a nível
+func ListSubResellers(memberID int) ([]SubReseller, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("conexão com banco de dados não inicializada")
+	}
+
+	result := []SubReseller{}
+	visited := map[int]bool{memberID: true}
+	parents := []int{memberID}
+
+	for level := 1; level <= maxResellerDepth && len(parents) > 0; level++ {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(parents)), ",")
+		args := make([]interface{}, len(parents))
+		for i, id := range parents {
+			args[i] = id
+		}
+
+		rows, err := config.DB.Query(`
+			SELECT id, username, owner_id, member_group_id, credits, status
+			FROM streamcreed_db.reg_users
+			WHERE owner_id IN (`+placeholders+`)
+			ORDER BY username`, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []int
+		for rows.Next() {
+			var sub SubReseller
+			if err := rows.Scan(&sub.ID, &sub.Username, &sub.OwnerID, &sub.MemberGroupID, &sub.Credits, &sub.Status); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if visited[sub.ID] {
+				continue
+			}
+			visited[sub.ID] = true
+			sub.Nivel = level
+			result = append(result, sub)
+			next = append(next, sub.ID)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		parents = next
+	}
+
+	return result, nil
+}
+
+// ResellerUsernameExists verifica se já existe revenda com o username
+func ResellerUsernameExists(username string) (bool, error) {
+	var total int
+	err := config.DB.QueryRow("SELECT COUNT(*) FROM streamcreed_db.reg_users WHERE username = ?", username).Scan(&total)
+	return total > 0, err
+}
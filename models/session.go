@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Session representa uma sessão de login (um dispositivo) armazenada no Redis em `token:<member_id>:<session_id>`
+type Session struct {
+	SessionID     string    `json:"session_id"`
+	MemberID      int       `json:"member_id"`
+	Username      string    `json:"username"`
+	MemberGroupID int       `json:"member_group_id"` // Grupo da revenda (define o papel no RBAC)
+	AccessJTI     string    `json:"access_jti"`      // jti do access token em vigor
+	RefreshHash   string    `json:"refresh_hash"`    // SHA-256 do refresh token em vigor
+	RefreshUsados []string  `json:"refresh_usados"`  // Hashes de refresh tokens já rotacionados (detecção de reuso)
+	IP            string    `json:"ip"`              // IP do último login/refresh
+	UserAgent     string    `json:"user_agent"`      // User-Agent do último login/refresh
+	CriadoEm      time.Time `json:"criado_em"`
+	ExpiraEm      time.Time `json:"expira_em"` // Expiração do refresh token
+
+	// Sessão de personificação: aberta por um super-admin em nome da revenda
+	ImpersonatedBy       int    `json:"impersonated_by,omitempty"`       // member_id do operador real
+	ImpersonatorUsername string `json:"impersonator_username,omitempty"` // username do operador real
+}
+
+// SessionInfo é a visão pública de uma sessão, retornada na listagem de sessões
+type SessionInfo struct {
+	SessionID string     `json:"session_id"`
+	IP        string     `json:"ip"`
+	UserAgent string     `json:"user_agent"`
+	CriadoEm  time.Time  `json:"criado_em"`
+	UltimoUso *time.Time `json:"ultimo_uso,omitempty"`
+	ExpiraEm  time.Time  `json:"expira_em"`
+	Atual     bool       `json:"atual"` // true para a sessão do token usado na requisição
+
+	PersonificadaPor int `json:"personificada_por,omitempty"` // member_id do admin, se for sessão de personificação
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"apiBackEnd/config"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TwoFactor representa a configuração de 2FA (TOTP) de uma revenda (tabela streamcreed_db.reseller_2fa)
+type TwoFactor struct {
+	MemberID      int
+	SecretEnc     string   // Segredo TOTP cifrado
+	Enabled       bool     // false enquanto a ativação não for confirmada com um código
+	RecoveryCodes []string // SHA-256 dos códigos de recuperação ainda não usados
+	LastUsedStep  int64
+	EnabledAt     *time.Time
+}
+
+// TwoFactorCodePayload é usado para confirmar ações de 2FA com um código TOTP ou de recuperação
+type TwoFactorCodePayload struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorLoginPayload é usado na segunda etapa do login
+type TwoFactorLoginPayload struct {
+	ChallengeID string `json:"challenge_id" binding:"required"`
+	Code        string `json:"code" binding:"required"` // Código TOTP (6 dígitos) ou código de recuperação
+}
+
+// GetTwoFactor busca a configuração de 2FA da revenda. Retorna sql.ErrNoRows se não houver.
+func GetTwoFactor(memberID int) (*TwoFactor, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("conexão com banco de dados não inicializada")
+	}
+
+	var (
+		tf        TwoFactor
+		codesJSON sql.NullString
+		enabledAt sql.NullInt64
+	)
+	err := config.DB.QueryRow(`
+		SELECT member_id, secret_enc, enabled, recovery_codes, last_used_step, enabled_at
+		FROM streamcreed_db.reseller_2fa
+		WHERE member_id = ?`, memberID).Scan(
+		&tf.MemberID, &tf.SecretEnc, &tf.Enabled, &codesJSON, &tf.LastUsedStep, &enabledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if codesJSON.Valid && codesJSON.String != "" {
+		if err := json.Unmarshal([]byte(codesJSON.String), &tf.RecoveryCodes); err != nil {
+			return nil, fmt.Errorf("códigos de recuperação corrompidos: %w", err)
+		}
+	}
+	if enabledAt.Valid {
+		t := time.Unix(enabledAt.Int64, 0)
+		tf.EnabledAt = &t
+	}
+	return &tf, nil
+}
+
+// IsTwoFactorEnabled informa se a revenda precisa do segundo fator no login
+func IsTwoFactorEnabled(memberID int) (bool, error) {
+	tf, err := GetTwoFactor(memberID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return tf.Enabled, nil
+}
+
+// SavePendingTwoFactor grava (ou substitui) um segredo ainda não confirmado.
+// Não altera uma configuração já ativa.
+func SavePendingTwoFactor(memberID int, secretEnc string) error {
+	_, err := config.DB.Exec(`
+		INSERT INTO streamcreed_db.reseller_2fa (member_id, secret_enc, enabled, recovery_codes, last_used_step, created_at)
+		VALUES (?, ?, 0, NULL, 0, UNIX_TIMESTAMP())
+		ON DUPLICATE KEY UPDATE
+			secret_enc = IF(enabled = 1, secret_enc, VALUES(secret_enc)),
+			last_used_step = IF(enabled = 1, last_used_step, 0)`,
+		memberID, secretEnc)
+	return err
+}
+
+// EnableTwoFactor ativa o 2FA com os hashes dos códigos de recuperação
+func EnableTwoFactor(memberID int, recoveryHashes []string, step int64) error {
+	codesJSON, err := json.Marshal(recoveryHashes)
+	if err != nil {
+		return err
+	}
+	_, err = config.DB.Exec(`
+		UPDATE streamcreed_db.reseller_2fa
+		SET enabled = 1, recovery_codes = ?, last_used_step = ?, enabled_at = UNIX_TIMESTAMP()
+		WHERE member_id = ? AND enabled = 0`,
+		string(codesJSON), step, memberID)
+	return err
+}
+
+// ConsumeTwoFactorStep registra o passo TOTP usado. Retorna false se um passo igual ou
+// mais recente já tiver sido aceito (código reaproveitado por outra requisição).
+func ConsumeTwoFactorStep(memberID int, step int64) (bool, error) {
+	result, err := config.DB.Exec(`
+		UPDATE streamcreed_db.reseller_2fa
+		SET last_used_step = ?
+		WHERE member_id = ? AND last_used_step < ?`,
+		step, memberID, step)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows == 1, nil
+}
+
+// ReplaceRecoveryCodes troca a lista de códigos de recuperação. Quando previous não é nil,
+// a troca só acontece se a lista gravada ainda for a mesma (uso concorrente do mesmo código).
+func ReplaceRecoveryCodes(memberID int, previous, recoveryHashes []string) (bool, error) {
+	codesJSON, err := json.Marshal(recoveryHashes)
+	if err != nil {
+		return false, err
+	}
+
+	query := "UPDATE streamcreed_db.reseller_2fa SET recovery_codes = ? WHERE member_id = ?"
+	args := []interface{}{string(codesJSON), memberID}
+	if previous != nil {
+		previousJSON, err := json.Marshal(previous)
+		if err != nil {
+			return false, err
+		}
+		query += " AND recovery_codes = ?"
+		args = append(args, string(previousJSON))
+	}
+
+	result, err := config.DB.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows == 1, nil
+}
+
+// DeleteTwoFactor remove o 2FA da revenda
+func DeleteTwoFactor(memberID int) error {
+	_, err := config.DB.Exec("DELETE FROM streamcreed_db.reseller_2fa WHERE member_id = ?", memberID)
+	return err
+}
@@ -70,6 +70,8 @@ type TrustBonus struct {
 	DataLiberacao   time.Time `json:"data_liberacao"`
 	AdminID         string    `json:"admin_id"`
 	Motivo          string    `json:"motivo"`
+	ExpDateAnterior int64     `json:"exp_date_anterior,omitempty"`
+	NovoExpDate     int64     `json:"novo_exp_date,omitempty"`
 }
 
 type RenewBackup struct {
@@ -77,6 +79,8 @@ type RenewBackup struct {
 	CreditosGastos  float64   `json:"creditos_gastos"`
 	DataRenovacao   time.Time `json:"data_renovacao"`
 	AdminRenovou    string    `json:"admin_renovou"`
+	MemberIDRenovou int       `json:"member_id_renovou,omitempty"` // Revenda que pagou a renovação
+	UndoID          string    `json:"undo_id,omitempty"`           // Registro de desfazer da mesma renovação
 }
 
 type ChangeDueDate struct {
@@ -84,4 +88,6 @@ type ChangeDueDate struct {
 	DataAlteracao      time.Time `json:"data_alteracao"`
 	AdminID            string    `json:"admin_id"`
 	Motivo             string    `json:"motivo"`
+	ExpDateAnterior    int64     `json:"exp_date_anterior,omitempty"`
+	NovoExpDate        int64     `json:"novo_exp_date,omitempty"`
 }
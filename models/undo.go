@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Operações que podem ser desfeitas por /api/undo
+const (
+	UndoRenovacao           = "renovacao"
+	UndoTelaAdicional       = "tela_adicional"
+	UndoAlteracaoVencimento = "alteracao_vencimento"
+	UndoBonusConfianca      = "bonus_confianca"
+)
+
+// UndoRecord guarda o estado anterior a uma operação faturável ou que alterou o vencimento,
+// com o necessário para desfazê-la (compensação e devolução de créditos)
+type UndoRecord struct {
+	ID              string    `json:"id"`
+	Tipo            string    `json:"tipo"`
+	UserID          int       `json:"user_id"`
+	MemberIDCobrado int       `json:"member_id_cobrado,omitempty"` // Revenda que pagou e recebe a devolução
+	ActorID         int       `json:"actor_id"`                    // 0 = sistema (ex.: renovação automática)
+	ActorUsername   string    `json:"actor_username"`
+	CriadoEm        time.Time `json:"criado_em"`
+	ExpiraEm        time.Time `json:"expira_em"`
+	ExpDateAntes    int64     `json:"exp_date_antes,omitempty"`
+	ExpDateDepois   int64     `json:"exp_date_depois,omitempty"`
+	TelasAntes      int       `json:"telas_antes,omitempty"`
+	TelasDepois     int       `json:"telas_depois,omitempty"`
+	Creditos        float64   `json:"creditos"` // Devolvidos ao desfazer
+	Descricao       string    `json:"descricao"`
+}
+
+// UndoRequest é o corpo opcional de POST /api/undo/:operacao_id
+type UndoRequest struct {
+	Motivo string `json:"motivo"`
+}
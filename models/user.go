@@ -79,4 +79,77 @@ func GetUserByUsername(username string) (*User, error) {
 	return user, nil
 }
 
+// GetUserByID busca a revenda pelo ID (member_id), aplicando a mesma regra de ALLOWED_USERS do login.
+func GetUserByID(memberID int) (*User, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("conexão com banco de dados não inicializada")
+	}
+
+	query := `
+		SELECT username, password, member_group_id, credits, status, id as member_id
+		FROM streamcreed_db.reg_users
+		WHERE id = ?
+	`
+	user := &User{}
+	err := config.DB.QueryRow(query, memberID).Scan(
+		&user.Username,
+		&user.PasswordHash,
+		&user.MemberGroupID,
+		&user.Credits,
+		&user.Status,
+		&user.MemberID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("erro ao buscar usuário: %w", err)
+	}
+
+	if allowedUsersEnv := os.Getenv("ALLOWED_USERS"); allowedUsersEnv != "" {
+		isAllowed := false
+		for _, allowedUser := range strings.Split(allowedUsersEnv, ",") {
+			if strings.TrimSpace(allowedUser) == user.Username {
+				isAllowed = true
+				break
+			}
+		}
+		if !isAllowed {
+			return nil, sql.ErrNoRows
+		}
+	}
+
+	return user, nil
+}
+
+// ChangePasswordPayload é usado pela revenda para trocar a própria senha
+type ChangePasswordPayload struct {
+	SenhaAtual string `json:"senha_atual" binding:"required"`
+	NovaSenha  string `json:"nova_senha" binding:"required,min=6,max=64"`
+}
+
+// PasswordResetPayload troca um token de redefinição emitido pelo admin por uma nova senha
+type PasswordResetPayload struct {
+	Token     string `json:"token" binding:"required"`
+	NovaSenha string `json:"nova_senha" binding:"required,min=6,max=64"`
+}
+
+// UpdateResellerPassword grava o hash da nova senha da revenda (mesmo formato usado pelo painel)
+func UpdateResellerPassword(memberID int, passwordHash string) error {
+	if config.DB == nil {
+		return fmt.Errorf("conexão com banco de dados não inicializada")
+	}
+	result, err := config.DB.Exec("UPDATE streamcreed_db.reg_users SET password = ? WHERE id = ?", passwordHash, memberID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		var exists int
+		if err := config.DB.QueryRow("SELECT 1 FROM streamcreed_db.reg_users WHERE id = ?", memberID).Scan(&exists); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Outras funções do model user.go ...
@@ -3,6 +3,8 @@ package routes
 import (
 	"apiBackEnd/controllers"
 	_ "apiBackEnd/docs" // Importação para Swagger funcionar
+	"apiBackEnd/middleware"
+	"apiBackEnd/utils"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -16,42 +18,95 @@ func SetupRoutes(r *gin.Engine) {
 
 	// Rotas de autenticação e informações iniciais
 	r.POST("/login", controllers.Login)
+	r.POST("/login/2fa", controllers.LoginTwoFactor)
 	r.POST("/logout", controllers.Logout)
+	r.POST("/auth/refresh", controllers.RefreshToken)
+	r.POST("/password-reset", controllers.ResetPasswordHandler)
 	r.GET("/api/version", controllers.GetAPIVersion)
 	r.GET("/health", controllers.HealthCheck)
 
 	// Grupo de rotas protegidas centralizado sob "/api"
 	protected := r.Group("/api")
-	protected.Use(controllers.AuthMiddleware())
+	protected.Use(controllers.AuthMiddleware(), middleware.ImpersonationGuard())
 	{
+		// Cada rota protegida declara a permissão exigida (ver utils/rbac.go)
+		can := middleware.RequirePermission
+		// Operações faturáveis aceitam Idempotency-Key (ver middleware/idempotency.go)
+		idem := middleware.Idempotency()
+
 		// Endpoints gerais
-		protected.GET("/clients", controllers.GetClients)
-		protected.GET("/clients-table", controllers.GetClientsTable)
-		protected.POST("/create-test", controllers.CreateTest)
-		protected.GET("/details-error/:id_usuario", controllers.GetUserErrors)
-		protected.GET("/dashboard", controllers.DashboardHandler)
-		protected.POST("/renew", controllers.RenewAccount)
-		protected.GET("/credits", controllers.GetCredits)
-		protected.POST("/tools-table/add-screen", controllers.AddScreen)
-		protected.POST("/tools-table/remove-screen", controllers.RemoveScreen)
-		protected.PUT("/tools-table/edit/:id", controllers.EditUser)
-		protected.POST("/trust-bonus", controllers.TrustBonusHandler)
-		protected.POST("/renew-rollback", controllers.RenewRollbackHandler)
-		protected.POST("/change-due-date", controllers.ChangeDueDateHandler)
+		protected.GET("/clients", can(utils.PermClientsRead), controllers.GetClients)
+		protected.GET("/clients-table", can(utils.PermClientsRead), controllers.GetClientsTable)
+		protected.GET("/clients-table/export", can(utils.PermClientsRead), controllers.ExportClientsTable)
+		protected.POST("/create-test", can(utils.PermCreateTest), idem, controllers.CreateTest)
+		protected.POST("/clients", can(utils.PermCreateClient), idem, controllers.CreateClient)
+		protected.POST("/clients/import", can(utils.PermCreateClient), idem, controllers.ImportClientsHandler)
+		protected.GET("/clients/import/:job_id", can(utils.PermCreateClient), controllers.GetImportJobHandler)
+		protected.POST("/clients/import/:job_id/credenciais", can(utils.PermCreateClient), controllers.ClaimImportCredentialsHandler)
+		protected.GET("/details-error/:id_usuario", can(utils.PermClientsRead), controllers.GetUserErrors)
+		protected.GET("/dashboard", can(utils.PermDashboardRead), controllers.DashboardHandler)
+		protected.POST("/renew", can(utils.PermRenew), idem, controllers.RenewAccount)
+		protected.POST("/renew/bulk", can(utils.PermRenew), idem, controllers.BulkRenewHandler)
+		protected.GET("/credits", can(utils.PermCreditsRead), controllers.GetCredits)
+		protected.GET("/credits/history", can(utils.PermCreditsRead), controllers.GetCreditsHistory)
+		protected.POST("/pricing/quote", can(utils.PermPricingRead), controllers.QuotePriceHandler)
+		protected.POST("/tools-table/add-screen", can(utils.PermScreens), idem, controllers.AddScreen)
+		protected.POST("/tools-table/remove-screen", can(utils.PermScreens), idem, controllers.RemoveScreen)
+		protected.PUT("/tools-table/edit/:id", can(utils.PermEdit), controllers.EditUser)
+		protected.POST("/trust-bonus", can(utils.PermTrustBonus), controllers.TrustBonusHandler)
+		protected.POST("/renew-rollback", can(utils.PermRollback), controllers.RenewRollbackHandler)
+		protected.GET("/undo", can(utils.PermRollback), controllers.ListUndoHandler)
+		protected.POST("/undo/:operacao_id", can(utils.PermRollback), controllers.UndoOperationHandler)
+		protected.POST("/change-due-date", can(utils.PermDueDate), controllers.ChangeDueDateHandler)
 
 		// Primeiro definir rotas fixas, depois rotas com parâmetros
-		protected.GET("/users/deleted", controllers.ListDeletedUsersHandler)
-		protected.GET("/regions/allowed", controllers.GetAllowedRegionsHandler)
+		protected.GET("/users/deleted", can(utils.PermRestore), controllers.ListDeletedUsersHandler)
+		protected.GET("/regions/allowed", can(utils.PermRegion), controllers.GetAllowedRegionsHandler)
 
 		// Depois as rotas com parâmetros
-		protected.PATCH("/users/:user_id/status", controllers.UpdateUserStatusHandler)
-		protected.PATCH("/users/:user_id/region", controllers.ForceUserRegionHandler)
-		protected.DELETE("/users/:user_id/session", controllers.KickUserSessionHandler)
-		protected.PATCH("/users/:user_id/restore", controllers.RestoreUserHandler)
-		protected.DELETE("/users/:user_id", controllers.SoftDeleteUserHandler)
+		protected.PATCH("/users/:user_id/status", can(utils.PermStatus), controllers.UpdateUserStatusHandler)
+		protected.PATCH("/users/:user_id/region", can(utils.PermRegion), controllers.ForceUserRegionHandler)
+		protected.DELETE("/users/:user_id/session", can(utils.PermKick), controllers.KickUserSessionHandler)
+		protected.PATCH("/users/:user_id/restore", can(utils.PermRestore), controllers.RestoreUserHandler)
+		protected.DELETE("/users/:user_id", can(utils.PermDelete), controllers.SoftDeleteUserHandler)
+		protected.GET("/users/:user_id/auto-renew", can(utils.PermClientsRead), controllers.GetAutoRenewHandler)
+		protected.PUT("/users/:user_id/auto-renew", can(utils.PermRenew), controllers.SetAutoRenewHandler)
 
 		// Rotas de clientes com filtro por login e userID
-		protected.GET("/clients/login/:login", controllers.GetClients)
-		protected.GET("/clients/userid/:userid", controllers.GetClients)
+		protected.GET("/clients/login/:login", can(utils.PermClientsRead), controllers.GetClients)
+		protected.GET("/clients/userid/:userid", can(utils.PermClientsRead), controllers.GetClients)
+		protected.GET("/clients/:id/renewals", can(utils.PermClientsRead), controllers.GetClientRenewalsHandler)
+		protected.POST("/clients/:id/convert", can(utils.PermRenew), idem, controllers.ConvertTrialHandler)
+
+		// Sessões (dispositivos) da revenda
+		protected.GET("/sessions", can(utils.PermSessionsOwn), controllers.ListSessionsHandler)
+		protected.DELETE("/sessions", can(utils.PermSessionsOwn), controllers.RevokeAllSessionsHandler)
+		protected.DELETE("/sessions/:session_id", can(utils.PermSessionsOwn), controllers.RevokeSessionHandler)
+
+		// Autenticação em duas etapas (TOTP)
+		protected.GET("/2fa", can(utils.PermTwoFactor), controllers.TwoFactorStatusHandler)
+		protected.POST("/2fa/setup", can(utils.PermTwoFactor), controllers.TwoFactorSetupHandler)
+		protected.POST("/2fa/enable", can(utils.PermTwoFactor), controllers.TwoFactorEnableHandler)
+		protected.POST("/2fa/disable", can(utils.PermTwoFactor), controllers.TwoFactorDisableHandler)
+		protected.POST("/2fa/recovery-codes", can(utils.PermTwoFactor), controllers.TwoFactorRecoveryCodesHandler)
+
+		// Chaves de API para bots e integrações (header X-API-Key)
+		protected.GET("/api-keys", can(utils.PermAPIKeys), controllers.ListAPIKeysHandler)
+		protected.POST("/api-keys", can(utils.PermAPIKeys), controllers.CreateAPIKeyHandler)
+		protected.DELETE("/api-keys/:id", can(utils.PermAPIKeys), controllers.RevokeAPIKeyHandler)
+
+		// 🔑 Senha da própria conta
+		protected.PUT("/me/password", can(utils.PermPassword), controllers.ChangePasswordHandler)
+
+		// 🌳 Sub-revendas
+		protected.GET("/resellers", can(utils.PermSubResellers), controllers.ListSubResellersHandler)
+		protected.POST("/resellers", can(utils.PermSubResellers), controllers.CreateSubResellerHandler)
+		protected.POST("/resellers/:member_id/credits", can(utils.PermSubResellers), controllers.TransferCreditsHandler)
+
+		// Administração de sessões de outras revendas
+		protected.GET("/admin/resellers/:member_id/sessions", can(utils.PermSessionsAdmin), controllers.ListResellerSessionsHandler)
+		protected.DELETE("/admin/resellers/:member_id/sessions", can(utils.PermSessionsAdmin), controllers.ForceLogoutResellerHandler)
+		protected.POST("/admin/resellers/:member_id/password-reset", can(utils.PermPasswordReset), controllers.IssuePasswordResetHandler)
+		protected.POST("/admin/resellers/:member_id/impersonate", can(utils.PermImpersonate), controllers.ImpersonateResellerHandler)
 	}
 }
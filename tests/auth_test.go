@@ -50,3 +50,46 @@ func TestLoginSuccess(t *testing.T) {
 		t.Fatalf("❌ Erro ao extrair MemberID!")
 	}
 }
+
+func TestRefreshTokenRotation(t *testing.T) {
+	fmt.Println("🚀 Testando POST /auth/refresh")
+
+	loginData := map[string]string{
+		"username": os.Getenv("TEST_USER"),
+		"password": os.Getenv("TEST_PASSWORD"),
+	}
+	jsonData, _ := json.Marshal(loginData)
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router := SetupServer()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var loginResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &loginResponse)
+	refreshToken, ok := loginResponse["refresh_token"].(string)
+	if !ok || refreshToken == "" {
+		t.Fatalf("❌ Login não retornou refresh_token!")
+	}
+
+	// 🔹 Primeira troca: deve emitir um novo par de tokens
+	refreshBody, _ := json.Marshal(map[string]string{"refresh_token": refreshToken})
+	req, _ = http.NewRequest("POST", "/auth/refresh", bytes.NewBuffer(refreshBody))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var refreshResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &refreshResponse)
+	assert.NotEqual(t, refreshToken, refreshResponse["refresh_token"])
+
+	// 🔹 Reapresentar o refresh antigo é reuso: sessão revogada
+	req, _ = http.NewRequest("POST", "/auth/refresh", bytes.NewBuffer(refreshBody))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
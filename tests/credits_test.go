@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDebitCreditsConcurrent dispara débitos simultâneos que, somados, passam do saldo da revenda
+// de teste: o SELECT ... FOR UPDATE de DebitCredits deve deixar passar só um. O valor debitado é
+// devolvido no fim do teste.
+func TestDebitCreditsConcurrent(t *testing.T) {
+	fmt.Println("🚀 Testando DebitCredits com débitos simultâneos")
+
+	SetupServer()
+	EnsureAuthToken(t)
+
+	var saldoInicial float64
+	if err := config.DB.QueryRow("SELECT credits FROM streamcreed_db.reg_users WHERE id = ?", TestMemberID).Scan(&saldoInicial); err != nil {
+		t.Fatalf("❌ Erro ao buscar créditos da revenda de teste: %v", err)
+	}
+	if saldoInicial < 0.02 {
+		t.Skipf("⚠️ Revenda de teste sem créditos suficientes (%.2f)", saldoInicial)
+	}
+	// Mais da metade do saldo: dois débitos nunca cabem juntos
+	custo := math.Floor(saldoInicial*0.6*100) / 100
+
+	const total = 5
+	erros := make([]error, total)
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tx, err := config.DB.Begin()
+			if err != nil {
+				erros[i] = err
+				return
+			}
+			defer tx.Rollback()
+			if _, err := models.DebitCredits(tx, custo, models.CreditMovement{
+				MemberID:  TestMemberID,
+				Operacao:  models.LedgerRenovacao,
+				ActorID:   TestMemberID,
+				Descricao: "Teste automatizado: débito concorrente",
+			}); err != nil {
+				erros[i] = err
+				return
+			}
+			erros[i] = tx.Commit()
+		}(i)
+	}
+	wg.Wait()
+
+	debitados, recusados := 0, 0
+	for _, err := range erros {
+		switch {
+		case err == nil:
+			debitados++
+		case errors.Is(err, models.ErrCreditosInsuficientes):
+			recusados++
+		default:
+			t.Errorf("❌ Erro inesperado no débito: %v", err)
+		}
+	}
+
+	// 🔹 Devolve o que foi debitado antes de qualquer verificação
+	if debitados > 0 {
+		tx, err := config.DB.Begin()
+		if err == nil {
+			_, err = models.ApplyCreditMovement(tx, models.CreditMovement{
+				MemberID:  TestMemberID,
+				Operacao:  models.LedgerReversaoRenovacao,
+				Valor:     custo * float64(debitados),
+				ActorID:   TestMemberID,
+				Descricao: "Teste automatizado: estorno do débito concorrente",
+			})
+		}
+		if err == nil {
+			err = tx.Commit()
+		}
+		if err != nil {
+			t.Errorf("❌ Erro ao devolver %.2f créditos à revenda de teste: %v", custo*float64(debitados), err)
+		}
+	}
+
+	assert.Equal(t, 1, debitados)
+	assert.Equal(t, total-1, recusados)
+
+	var saldoFinal float64
+	if err := config.DB.QueryRow("SELECT credits FROM streamcreed_db.reg_users WHERE id = ?", TestMemberID).Scan(&saldoFinal); err == nil {
+		assert.InDelta(t, saldoInicial, saldoFinal, 0.001)
+	}
+}
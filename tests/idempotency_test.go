@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/middleware"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// idempotencyTestRouter monta uma rota faturável de mentira com o middleware de idempotência,
+// contando quantas vezes o handler executa de fato
+func idempotencyTestRouter(execucoes *int32, espera time.Duration) *gin.Engine {
+	config.InitRedis()
+
+	r := gin.New()
+	r.POST("/operacao", func(c *gin.Context) {
+		c.Set("member_id", -1) // Revenda fictícia: as chaves não colidem com as reais
+		c.Next()
+	}, middleware.Idempotency(), func(c *gin.Context) {
+		n := atomic.AddInt32(execucoes, 1)
+		time.Sleep(espera)
+		c.JSON(http.StatusCreated, gin.H{"execucao": n})
+	})
+	return r
+}
+
+func postIdempotente(r *gin.Engine, chave string, corpo string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/operacao", bytes.NewBufferString(corpo))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.IdempotencyHeader, chave)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	fmt.Println("🚀 Testando Idempotency-Key: repetição e corpo diferente")
+
+	var execucoes int32
+	r := idempotencyTestRouter(&execucoes, 0)
+	chave := fmt.Sprintf("teste-replay-%d", time.Now().UnixNano())
+
+	primeira := postIdempotente(r, chave, `{"id_cliente": 1}`)
+	assert.Equal(t, http.StatusCreated, primeira.Code)
+
+	// 🔹 Mesma chave e mesmo corpo: resposta guardada, sem executar de novo
+	repeticao := postIdempotente(r, chave, `{"id_cliente": 1}`)
+	assert.Equal(t, http.StatusCreated, repeticao.Code)
+	assert.Equal(t, primeira.Body.String(), repeticao.Body.String())
+	assert.Equal(t, "true", repeticao.Header().Get("Idempotent-Replayed"))
+
+	// 🔹 Mesma chave com outro corpo: recusada
+	diferente := postIdempotente(r, chave, `{"id_cliente": 2}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, diferente.Code)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&execucoes))
+}
+
+func TestIdempotencyConcurrent(t *testing.T) {
+	fmt.Println("🚀 Testando Idempotency-Key: requisições simultâneas")
+
+	var execucoes int32
+	r := idempotencyTestRouter(&execucoes, 300*time.Millisecond)
+	chave := fmt.Sprintf("teste-concorrente-%d", time.Now().UnixNano())
+
+	const total = 5
+	codigos := make([]int, total)
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codigos[i] = postIdempotente(r, chave, `{"id_cliente": 1}`).Code
+		}(i)
+	}
+	wg.Wait()
+
+	// 🔹 Só uma executa; as demais recebem 409 enquanto ela está em andamento
+	criadas, emAndamento := 0, 0
+	for _, codigo := range codigos {
+		switch codigo {
+		case http.StatusCreated:
+			criadas++
+		case http.StatusConflict:
+			emAndamento++
+		}
+	}
+	assert.Equal(t, 1, criadas)
+	assert.Equal(t, total-1, emAndamento)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&execucoes))
+}
+
+func TestIdempotencyHandlerPanic(t *testing.T) {
+	fmt.Println("🚀 Testando Idempotency-Key: handler em pânico libera a chave")
+
+	config.InitRedis()
+	var execucoes int32
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.POST("/operacao", func(c *gin.Context) {
+		c.Set("member_id", -1)
+		c.Next()
+	}, middleware.Idempotency(), func(c *gin.Context) {
+		if atomic.AddInt32(&execucoes, 1) == 1 {
+			panic("falha simulada")
+		}
+		c.JSON(http.StatusCreated, gin.H{"execucao": 2})
+	})
+	chave := fmt.Sprintf("teste-panico-%d", time.Now().UnixNano())
+
+	// 🔹 O pânico vira 500 no Recovery e a chave não fica presa em "processando"
+	primeira := postIdempotente(r, chave, `{"id_cliente": 1}`)
+	assert.Equal(t, http.StatusInternalServerError, primeira.Code)
+
+	nova := postIdempotente(r, chave, `{"id_cliente": 1}`)
+	assert.Equal(t, http.StatusCreated, nova.Code)
+	assert.Empty(t, nova.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&execucoes))
+}
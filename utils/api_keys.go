@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"apiBackEnd/models"
+	"database/sql"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Chaves de API das revendas
+//
+// Usadas por bots e integrações no lugar de usuário/senha: enviadas no header X-API-Key,
+// resolvem para a mesma identidade (member_id, username, grupo) que o JWT coloca no contexto.
+// Cada chave só pode usar as permissões dos seus scopes, e nunca mais do que o papel da revenda permite.
+
+// APIKeyHeader é o header em que a chave é enviada
+const APIKeyHeader = "X-API-Key"
+
+const apiKeyPrefixLength = 12
+
+var ErrAPIKeyInvalid = errors.New("chave de API inválida, revogada ou expirada")
+
+// apiKeyScopes associa cada scope às permissões que ele libera
+var apiKeyScopes = map[string][]Permission{
+	"create-test":  {PermCreateTest},
+	"read-clients": {PermClientsRead},
+	"renew":        {PermRenew, PermPricingRead},
+	"read-credits": {PermCreditsRead},
+}
+
+// APIKeyScopeNames lista os scopes aceitos, em ordem alfabética
+func APIKeyScopeNames() []string {
+	names := make([]string, 0, len(apiKeyScopes))
+	for name := range apiKeyScopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidAPIKeyScope informa se o scope existe
+func ValidAPIKeyScope(scope string) bool {
+	_, ok := apiKeyScopes[scope]
+	return ok
+}
+
+// APIKeyPermissions converte os scopes da chave nas permissões correspondentes
+func APIKeyPermissions(scopes []string) []Permission {
+	var perms []Permission
+	for _, scope := range scopes {
+		perms = append(perms, apiKeyScopes[strings.TrimSpace(scope)]...)
+	}
+	return perms
+}
+
+// HashAPIKey calcula o SHA-256 da chave (o valor puro nunca é gravado)
+func HashAPIKey(key string) string {
+	return hashRefreshSecret(strings.TrimSpace(key))
+}
+
+// GenerateAPIKey gera uma nova chave, retornando o valor puro, o prefixo exibível e o hash
+func GenerateAPIKey() (string, string, string, error) {
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", "", "", err
+	}
+	key := "sok_" + secret
+	return key, key[:apiKeyPrefixLength], HashAPIKey(key), nil
+}
+
+// AuthenticateAPIKey valida a chave e carrega a revenda dona dela
+func AuthenticateAPIKey(key string) (*models.APIKey, *models.User, error) {
+	if strings.TrimSpace(key) == "" {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+
+	apiKey, err := models.GetActiveAPIKeyByHash(HashAPIKey(key))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, ErrAPIKeyInvalid
+		}
+		return nil, nil, err
+	}
+
+	user, err := models.GetUserByID(apiKey.MemberID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, ErrAPIKeyInvalid
+		}
+		return nil, nil, err
+	}
+	if user.Status != 1 {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+
+	if err := models.TouchAPIKey(apiKey.ID); err != nil {
+		log.Printf("⚠️ Erro ao registrar uso da chave de API %d: %v", apiKey.ID, err)
+	}
+	return apiKey, user, nil
+}
+
+// APIKeyClaims monta as mesmas claims de um access token para a identidade da chave,
+// para que os handlers tratem JWT e chave de API da mesma forma
+func APIKeyClaims(apiKey *models.APIKey, user *models.User) jwt.MapClaims {
+	return jwt.MapClaims{
+		"username":        user.Username,
+		"member_id":       float64(user.MemberID),
+		"member_group_id": float64(user.MemberGroupID),
+		"credits":         user.Credits,
+		"status":          strconv.Itoa(user.Status),
+		"api_key_id":      float64(apiKey.ID),
+		"scopes":          apiKey.Scopes,
+	}
+}
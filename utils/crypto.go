@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/subtle"
+
 	"github.com/tredoe/osutil/user/crypt/sha512_crypt"
 )
 
@@ -16,3 +18,12 @@ func CryptPassword(password string) (string, error) {
 
 	return hashedPassword, nil
 }
+
+// VerifyPassword compara a senha informada com o hash gravado no painel
+func VerifyPassword(password, passwordHash string) (bool, error) {
+	hashedPassword, err := CryptPassword(password)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare([]byte(hashedPassword), []byte(passwordHash)) == 1, nil
+}
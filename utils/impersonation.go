@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Personificação (suporte)
+//
+// Um super-admin pode abrir uma sessão em nome de uma revenda para ver exatamente o que ela vê.
+// A sessão fica gravada como qualquer outra em `token:<member_id>:<session_id>` (aparece na
+// listagem de sessões da revenda e pode ser encerrada), mas:
+//   - o access token carrega `impersonated_by` / `impersonator_username` com o operador real;
+//   - não há refresh token: a sessão termina junto com o access token (IMPERSONACAO_MINUTOS, padrão 15);
+//   - operações de escrita são bloqueadas (ver middleware.ImpersonationGuard).
+
+// GetImpersonationExpiration retorna a duração da sessão de personificação
+func GetImpersonationExpiration() time.Duration {
+	return time.Duration(envPositiveInt("IMPERSONACAO_MINUTOS", 15)) * time.Minute
+}
+
+// GenerateImpersonationToken abre uma sessão de personificação da revenda `target` pelo admin informado
+func GenerateImpersonationToken(target *models.User, adminID int, adminUsername string, ip string, userAgent string) (*TokenPair, error) {
+	ctx := context.Background()
+
+	sessionID, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	session := &models.Session{
+		SessionID:            sessionID,
+		MemberID:             target.MemberID,
+		Username:             target.Username,
+		MemberGroupID:        target.MemberGroupID,
+		IP:                   ip,
+		UserAgent:            userAgent,
+		CriadoEm:             time.Now(),
+		ImpersonatedBy:       adminID,
+		ImpersonatorUsername: adminUsername,
+	}
+
+	pair, err := prepareSessionTokens(session, target.Credits, strconv.Itoa(target.Status))
+	if err != nil {
+		return nil, err
+	}
+	pair.RefreshToken = ""
+	session.ExpiraEm = session.CriadoEm.Add(GetImpersonationExpiration())
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.RedisClient.Set(ctx, sessionKey(target.MemberID, sessionID), data, GetImpersonationExpiration()).Err(); err != nil {
+		return nil, err
+	}
+	TouchSession(ctx, target.MemberID, sessionID)
+
+	return pair, nil
+}
+
+// ImpersonatorFromClaims retorna o member_id do operador real (0 se o token não for de personificação)
+func ImpersonatorFromClaims(claims jwt.MapClaims) (int, string) {
+	adminID, _ := claims["impersonated_by"].(float64)
+	adminUsername, _ := claims["impersonator_username"].(string)
+	return int(adminID), adminUsername
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Importação de clientes
+//
+// O andamento de cada importação fica em `import_job:<id>` e expira IMPORTACAO_RETENCAO_HORAS
+// (padrão 24) depois da última atualização. Os logins e senhas dos clientes criados ficam à parte,
+// em `import_job:<id>:credenciais`, por IMPORTACAO_CREDENCIAIS_MINUTOS (padrão 60), e são apagados
+// na primeira leitura.
+
+var ErrImportJobNotFound = errors.New("importação não encontrada ou expirada")
+
+var ErrImportCredentialsNotFound = errors.New("credenciais já lidas ou expiradas")
+
+func importJobKey(id string) string {
+	return "import_job:" + id
+}
+
+func importCredentialsKey(id string) string {
+	return importJobKey(id) + ":credenciais"
+}
+
+// GetImportacaoMaxLinhas retorna o limite de linhas por arquivo (IMPORTACAO_MAX_LINHAS, padrão 1000)
+func GetImportacaoMaxLinhas() int {
+	return envPositiveInt("IMPORTACAO_MAX_LINHAS", 1000)
+}
+
+// GetImportacaoRetencao retorna por quanto tempo o andamento da importação fica disponível
+func GetImportacaoRetencao() time.Duration {
+	return time.Duration(envPositiveInt("IMPORTACAO_RETENCAO_HORAS", 24)) * time.Hour
+}
+
+// SaveImportJob grava o andamento da importação (gera o ID na primeira gravação)
+func SaveImportJob(ctx context.Context, job *models.ClientImportJob) error {
+	if job.ID == "" {
+		id, err := randomID()
+		if err != nil {
+			return err
+		}
+		job.ID = id
+		job.CriadoEm = time.Now()
+	}
+	job.AtualizadoEm = time.Now()
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return config.RedisClient.Set(ctx, importJobKey(job.ID), data, GetImportacaoRetencao()).Err()
+}
+
+// GetImportJob lê o andamento da importação
+func GetImportJob(ctx context.Context, id string) (*models.ClientImportJob, error) {
+	data, err := config.RedisClient.Get(ctx, importJobKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrImportJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var job models.ClientImportJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetImportacaoCredenciaisValidade retorna por quanto tempo as credenciais da importação podem ser lidas
+func GetImportacaoCredenciaisValidade() time.Duration {
+	return time.Duration(envPositiveInt("IMPORTACAO_CREDENCIAIS_MINUTOS", 60)) * time.Minute
+}
+
+// SaveImportCredentials grava as credenciais dos clientes já criados pela importação
+func SaveImportCredentials(ctx context.Context, jobID string, creds []models.ClientImportCredential) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	return config.RedisClient.Set(ctx, importCredentialsKey(jobID), data, GetImportacaoCredenciaisValidade()).Err()
+}
+
+// TakeImportCredentials lê e apaga as credenciais da importação (leitura única)
+func TakeImportCredentials(ctx context.Context, jobID string) ([]models.ClientImportCredential, error) {
+	data, err := config.RedisClient.GetDel(ctx, importCredentialsKey(jobID)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrImportCredentialsNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var creds []models.ClientImportCredential
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"apiBackEnd/config"
+	"context"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Trava de jobs em segundo plano
+//
+// Com várias instâncias da API, cada job só deve rodar em uma delas por vez. A trava é uma chave
+// `job_lock:<nome>` no Redis com um token aleatório da instância que a obteve; o TTL a libera se
+// a instância cair no meio da execução. Jobs longos usam KeepJobLock para renovar o TTL enquanto
+// rodam, assim a trava não expira no meio de uma rodada lenta.
+
+// releaseJobLockScript apaga a trava somente se ela ainda pertencer a quem a obteve
+var releaseJobLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// extendJobLockScript renova o TTL da trava somente se ela ainda pertencer a quem a obteve
+var extendJobLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+func jobLockKey(name string) string {
+	return "job_lock:" + name
+}
+
+// AcquireJobLock tenta obter a trava do job. Retorna o token para liberá-la e ok=false se outra instância já a tem.
+func AcquireJobLock(ctx context.Context, name string, ttl time.Duration) (string, bool, error) {
+	token, err := randomID()
+	if err != nil {
+		return "", false, err
+	}
+	ok, err := config.RedisClient.SetNX(ctx, jobLockKey(name), token, ttl).Result()
+	if err != nil {
+		return "", false, err
+	}
+	return token, ok, nil
+}
+
+// ReleaseJobLock libera a trava obtida com AcquireJobLock
+func ReleaseJobLock(ctx context.Context, name string, token string) error {
+	return releaseJobLockScript.Run(ctx, config.RedisClient, []string{jobLockKey(name)}, token).Err()
+}
+
+// ExtendJobLock renova o TTL da trava. Retorna false se a trava expirou ou pertence a outra instância.
+func ExtendJobLock(ctx context.Context, name string, token string, ttl time.Duration) (bool, error) {
+	n, err := extendJobLockScript.Run(ctx, config.RedisClient, []string{jobLockKey(name)}, token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// KeepJobLock renova a trava a cada terço do TTL até que a função retornada seja chamada
+func KeepJobLock(name string, token string, ttl time.Duration) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				ok, err := ExtendJobLock(ctx, name, token, ttl)
+				cancel()
+				if err != nil {
+					log.Printf("⚠️ Erro ao renovar trava do job %s: %v", name, err)
+				} else if !ok {
+					log.Printf("⚠️ Trava do job %s perdida durante a execução", name)
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"apiBackEnd/config"
 	"context"
 	"errors" // 🔹 Importação para logs
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -12,72 +11,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var secretKey = []byte("Prs5bR2t%vWT>m+?syisEh0f2h+?/CDz=sA[:Y9CSWAjdZv&oF1x8g*TT_76<QSI")
-
-// GenerateToken agora armazena o token com TTL exato no Redis
-func GenerateToken(username string, memberID int, credits float64, status string) (string, error) {
-	ctx := context.Background()
-	redisKey := "token:" + strconv.Itoa(memberID)
-
-	////log.Printf("Gerando token para username: %s, memberID: %d, credits: %.2f, status: %s", username, memberID, credits, status)
-
-	// 🔹 **Verifica se já existe um token ativo no Redis**
-	existingToken, err := config.RedisClient.Get(ctx, redisKey).Result()
-	if err == nil && existingToken != "" {
-		////log.Printf("Token existente encontrado no Redis para memberID: %d", memberID)
-		return existingToken, nil
-	}
-
-	// 🔹 **Obtém tempo de expiração do .env**
-	expirationMinutes, err := strconv.Atoi(os.Getenv("TOKEN_EXPIRATION_MINUTES"))
-	if err != nil || expirationMinutes <= 0 {
-		////log.Printf("Erro ao obter TOKEN_EXPIRATION_MINUTES, usando fallback de 60 minutos. Erro: %v", err)
-		expirationMinutes = 60
-	}
-	expirationDuration := time.Duration(expirationMinutes) * time.Minute
-	expirationTime := time.Now().Add(expirationDuration).Unix()
-
-	////log.Printf("Tempo de expiração do token: %d minutos (%d segundos)", expirationMinutes, expirationTime)
-
-	// 🔥 Claims com campos 100% visíveis no JWT.io
-	claims := jwt.MapClaims{
-		"username":  username,
-		"member_id": memberID,
-		"exp":       expirationTime,
-		"credits":   credits, // 🔹 Certifique-se de que o valor correto está sendo atribuído
-		"status":    status,  // 🔹 Certifique-se de que o valor correto está sendo atribuído
-	}
-
-	////log.Printf("Claims geradas: %+v", claims)
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(secretKey)
-	if err != nil {
-		////log.Printf("Erro ao assinar o token: %v", err)
-		return "", err
-	}
-
-	////log.Printf("Token gerado com sucesso: %s", tokenString)
-
-	// 🔥 **Armazena o token no Redis com TTL baseado na expiração**
-	err = config.RedisClient.Set(ctx, redisKey, tokenString, expirationDuration).Err()
-	if err != nil {
-		////log.Printf("Erro ao armazenar o token no Redis: %v", err)
-		return "", err
-	}
-
-	////log.Printf("Token armazenado no Redis com TTL de %d minutos", expirationMinutes)
-
-	// 🔥 **Define manualmente o TTL do Redis (caso necessário)**
-	err = config.RedisClient.Expire(ctx, redisKey, expirationDuration).Err()
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
-}
-
-// ValidateToken agora verifica TTL no Redis
+// ValidateToken verifica assinatura, `kid` e `exp` do token e confirma no Redis que a sessão (`sid`) segue ativa
 func ValidateToken(tokenString string) (jwt.MapClaims, int64, error) {
 	ctx := context.Background()
 
@@ -87,16 +21,10 @@ func ValidateToken(tokenString string) (jwt.MapClaims, int64, error) {
 	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 	//log.Printf("Token após remoção do prefixo 'Bearer ': %s", tokenString)
 
-	// 🔹 **Decodificar o token para obter `member_id`**
-	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
+	// 🔹 **Verificar assinatura (chave pelo `kid`) e expiração**
+	claims, err := parseSignedToken(tokenString)
 	if err != nil {
-		//log.Printf("Erro ao decodificar o token: %v", err)
-		return nil, 0, errors.New("token inválido")
-	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		//log.Printf("Erro ao converter claims do token")
+		//log.Printf("Erro ao validar o token: %v", err)
 		return nil, 0, errors.New("token inválido")
 	}
 
@@ -108,15 +36,19 @@ func ValidateToken(tokenString string) (jwt.MapClaims, int64, error) {
 		return nil, 0, errors.New("member_id não encontrado no token")
 	}
 
-	redisKey := "token:" + strconv.Itoa(int(memberID))
-	//log.Printf("Chave do Redis para validação: %s", redisKey)
+	sessionID, _ := claims["sid"].(string)
+	jti, _ := claims["jti"].(string)
+	if sessionID == "" || jti == "" {
+		return nil, 0, errors.New("token sem sessão associada")
+	}
 
-	// 🔥 **Verifica no Redis se o token ainda é válido**
-	storedToken, err := config.RedisClient.Get(ctx, redisKey).Result()
-	if err != nil || storedToken != tokenString {
-		//log.Printf("Token não encontrado ou não corresponde ao armazenado no Redis")
+	// 🔥 **Verifica no Redis se a sessão existe e se este é o access token em vigor**
+	session, err := GetSession(ctx, int(memberID), sessionID)
+	if err != nil || session.AccessJTI != jti {
+		//log.Printf("Sessão não encontrada ou token substituído por um refresh")
 		return nil, 0, errors.New("token expirado ou não autorizado")
 	}
+	TouchSession(ctx, int(memberID), sessionID)
 
 	//log.Printf("Token encontrado no Redis e válido")
 
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Chaves de assinatura do JWT
+//
+// As chaves são lidas do .env para permitir rotação sem alterar o código:
+//
+//	JWT_SIGNING_KEYS=2025a:segredo-antigo,2025b:segredo-novo
+//	JWT_ACTIVE_KID=2025b
+//
+// Todas as chaves listadas são aceitas na validação; apenas a chave ativa é usada
+// para assinar novos tokens. Para aposentar uma chave basta removê-la da lista:
+// tokens assinados com ela passam a ser rejeitados.
+
+// minSigningKeyLength é o tamanho mínimo aceito para um segredo HMAC
+const minSigningKeyLength = 32
+
+var errNoSigningKeys = errors.New("nenhuma chave JWT configurada em JWT_SIGNING_KEYS")
+
+// loadSigningKeys retorna o mapa kid → segredo e o kid ativo para assinatura
+func loadSigningKeys() (map[string][]byte, string, error) {
+	raw := strings.TrimSpace(os.Getenv("JWT_SIGNING_KEYS"))
+	if raw == "" {
+		return nil, "", errNoSigningKeys
+	}
+
+	keys := make(map[string][]byte)
+	firstKid := ""
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, secret, found := strings.Cut(entry, ":")
+		kid = strings.TrimSpace(kid)
+		if !found || kid == "" || secret == "" {
+			return nil, "", fmt.Errorf("entrada inválida em JWT_SIGNING_KEYS: use o formato kid:segredo")
+		}
+		if len(secret) < minSigningKeyLength {
+			return nil, "", fmt.Errorf("a chave JWT '%s' deve ter pelo menos %d caracteres", kid, minSigningKeyLength)
+		}
+		if _, exists := keys[kid]; exists {
+			return nil, "", fmt.Errorf("kid duplicado em JWT_SIGNING_KEYS: %s", kid)
+		}
+		keys[kid] = []byte(secret)
+		if firstKid == "" {
+			firstKid = kid
+		}
+	}
+	if len(keys) == 0 {
+		return nil, "", errNoSigningKeys
+	}
+
+	activeKid := strings.TrimSpace(os.Getenv("JWT_ACTIVE_KID"))
+	if activeKid == "" {
+		activeKid = firstKid
+	}
+	if _, ok := keys[activeKid]; !ok {
+		return nil, "", fmt.Errorf("JWT_ACTIVE_KID '%s' não está presente em JWT_SIGNING_KEYS", activeKid)
+	}
+
+	return keys, activeKid, nil
+}
+
+// signToken assina as claims com a chave ativa e grava o `kid` no header
+func signToken(claims jwt.MapClaims) (string, error) {
+	keys, activeKid, err := loadSigningKeys()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = activeKid
+	return token.SignedString(keys[activeKid])
+}
+
+// parseSignedToken verifica assinatura, algoritmo e `exp` do token
+func parseSignedToken(tokenString string) (jwt.MapClaims, error) {
+	keys, _, err := loadSigningKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, ok := token.Header["kid"].(string)
+		if !ok || kid == "" {
+			return nil, errors.New("token sem kid")
+		}
+		secret, ok := keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("chave de assinatura desconhecida ou aposentada: %s", kid)
+		}
+		return secret, nil
+	}
+
+	token, err := jwt.Parse(tokenString, keyFunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("token inválido")
+	}
+	return claims, nil
+}
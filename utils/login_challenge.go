@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"apiBackEnd/config"
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Desafio de login em duas etapas
+//
+// Quando a revenda tem 2FA ativo, /login não emite JWT: grava em `2fa_challenge:<id>` o
+// member_id que passou na senha e devolve o id do desafio. O token só é emitido em
+// /login/2fa com um código TOTP ou de recuperação válido. Cada desafio aceita no máximo
+// maxChallengeFailures códigos errados e é apagado no primeiro uso bem-sucedido.
+
+const maxChallengeFailures = 5
+
+var ErrChallengeNotFound = errors.New("desafio de login inexistente ou expirado")
+
+func challengeKey(challengeID string) string {
+	return "2fa_challenge:" + challengeID
+}
+
+func challengeFailuresKey(challengeID string) string {
+	return "2fa_challenge_falhas:" + challengeID
+}
+
+// GetLoginChallengeExpiration retorna a validade do desafio (TOTP_CHALLENGE_MINUTOS, padrão 5)
+func GetLoginChallengeExpiration() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("TOTP_CHALLENGE_MINUTOS"))
+	if err != nil || minutes <= 0 {
+		minutes = 5
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// CreateLoginChallenge registra um desafio para a revenda que acertou a senha
+func CreateLoginChallenge(ctx context.Context, memberID int) (string, error) {
+	challengeID, err := randomToken(24)
+	if err != nil {
+		return "", err
+	}
+	if err := config.RedisClient.Set(ctx, challengeKey(challengeID), memberID, GetLoginChallengeExpiration()).Err(); err != nil {
+		return "", err
+	}
+	return challengeID, nil
+}
+
+// GetLoginChallenge retorna o member_id associado ao desafio
+func GetLoginChallenge(ctx context.Context, challengeID string) (int, error) {
+	memberID, err := config.RedisClient.Get(ctx, challengeKey(challengeID)).Int()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, ErrChallengeNotFound
+		}
+		return 0, err
+	}
+	return memberID, nil
+}
+
+// RegisterChallengeFailure conta um código errado e invalida o desafio ao atingir o limite.
+// Retorna quantas tentativas ainda restam.
+func RegisterChallengeFailure(ctx context.Context, challengeID string) (int, error) {
+	key := challengeFailuresKey(challengeID)
+	failures, err := config.RedisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	config.RedisClient.Expire(ctx, key, GetLoginChallengeExpiration())
+
+	remaining := maxChallengeFailures - int(failures)
+	if remaining <= 0 {
+		config.RedisClient.Del(ctx, challengeKey(challengeID), key)
+		return 0, nil
+	}
+	return remaining, nil
+}
+
+// ConsumeLoginChallenge apaga o desafio. Retorna false se outra requisição já o consumiu.
+func ConsumeLoginChallenge(ctx context.Context, challengeID string) (bool, error) {
+	deleted, err := config.RedisClient.Del(ctx, challengeKey(challengeID)).Result()
+	if err != nil {
+		return false, err
+	}
+	config.RedisClient.Del(ctx, challengeFailuresKey(challengeID))
+	return deleted == 1, nil
+}
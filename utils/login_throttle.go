@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"apiBackEnd/config"
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Proteção contra força bruta no /login
+//
+// Falhas são contadas no Redis por username e por IP, para valer entre várias instâncias
+// da API. Ao atingir o limite, a chave é bloqueada por um tempo que dobra a cada novo
+// bloqueio (nível) dentro de 24h, até o teto configurado:
+//
+//	LOGIN_MAX_TENTATIVAS=5          falhas por username antes do bloqueio
+//	LOGIN_MAX_TENTATIVAS_IP=20      falhas por IP antes do bloqueio (IPs podem ser compartilhados)
+//	LOGIN_JANELA_MINUTOS=15         janela de contagem das falhas
+//	LOGIN_BLOQUEIO_SEGUNDOS=60      duração do primeiro bloqueio
+//	LOGIN_BLOQUEIO_MAX_SEGUNDOS=3600
+
+const loginLockoutLevelTTL = 24 * time.Hour
+
+// LoginLockout descreve um bloqueio aplicado após uma falha
+type LoginLockout struct {
+	Scope    string // "username" ou "ip"
+	Value    string
+	Level    int
+	Duration time.Duration
+}
+
+// registerFailureScript incrementa as falhas e, ao atingir o limite, aplica o bloqueio
+// na mesma operação atômica. Retorna a duração do bloqueio em segundos (0 = sem bloqueio) e o nível.
+//
+// KEYS: falhas, bloqueio, nível
+// ARGV: janela(s), limite, bloqueio base(s), bloqueio máx(s), ttl do nível(s)
+var registerFailureScript = redis.NewScript(`
+local failures = redis.call('INCR', KEYS[1])
+if failures == 1 then
+	redis.call('EXPIRE', KEYS[1], ARGV[1])
+end
+if failures < tonumber(ARGV[2]) then
+	return {0, 0}
+end
+redis.call('DEL', KEYS[1])
+local level = redis.call('INCR', KEYS[3])
+redis.call('EXPIRE', KEYS[3], ARGV[5])
+local duration = tonumber(ARGV[3]) * (2 ^ (level - 1))
+if duration > tonumber(ARGV[4]) then
+	duration = tonumber(ARGV[4])
+end
+redis.call('SET', KEYS[2], level, 'EX', math.floor(duration))
+return {math.floor(duration), level}
+`)
+
+func envPositiveInt(name string, defaultValue int) int {
+	val, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || val <= 0 {
+		return defaultValue
+	}
+	return val
+}
+
+func normalizeLoginUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func loginFailuresKey(scope, value string) string {
+	return "login_falhas:" + scope + ":" + value
+}
+
+func loginLockKey(scope, value string) string {
+	return "login_bloqueio:" + scope + ":" + value
+}
+
+func loginLevelKey(scope, value string) string {
+	return "login_bloqueio_nivel:" + scope + ":" + value
+}
+
+// CheckLoginLock retorna quanto tempo falta para liberar o login (0 se não houver bloqueio)
+func CheckLoginLock(ctx context.Context, username, ip string) (time.Duration, error) {
+	pipe := config.RedisClient.Pipeline()
+	userTTL := pipe.TTL(ctx, loginLockKey("username", normalizeLoginUsername(username)))
+	ipTTL := pipe.TTL(ctx, loginLockKey("ip", ip))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, err
+	}
+
+	retryAfter := userTTL.Val()
+	if ipTTL.Val() > retryAfter {
+		retryAfter = ipTTL.Val()
+	}
+	if retryAfter < 0 { // -1/-2: chave sem TTL ou inexistente
+		return 0, nil
+	}
+	return retryAfter, nil
+}
+
+// RegisterLoginFailure conta uma falha de login para o username e para o IP.
+// Retorna os bloqueios aplicados por esta falha (já registrados no MongoDB).
+func RegisterLoginFailure(ctx context.Context, username, ip string) ([]LoginLockout, error) {
+	window := time.Duration(envPositiveInt("LOGIN_JANELA_MINUTOS", 15)) * time.Minute
+	baseLock := envPositiveInt("LOGIN_BLOQUEIO_SEGUNDOS", 60)
+	maxLock := envPositiveInt("LOGIN_BLOQUEIO_MAX_SEGUNDOS", 3600)
+
+	scopes := []struct {
+		scope     string
+		value     string
+		threshold int
+	}{
+		{"username", normalizeLoginUsername(username), envPositiveInt("LOGIN_MAX_TENTATIVAS", 5)},
+		{"ip", ip, envPositiveInt("LOGIN_MAX_TENTATIVAS_IP", 20)},
+	}
+
+	var lockouts []LoginLockout
+	for _, s := range scopes {
+		if s.value == "" {
+			continue
+		}
+		keys := []string{loginFailuresKey(s.scope, s.value), loginLockKey(s.scope, s.value), loginLevelKey(s.scope, s.value)}
+		result, err := registerFailureScript.Run(ctx, config.RedisClient, keys,
+			int(window.Seconds()), s.threshold, baseLock, maxLock, int(loginLockoutLevelTTL.Seconds())).Int64Slice()
+		if err != nil {
+			return lockouts, err
+		}
+		if result[0] > 0 {
+			lockouts = append(lockouts, LoginLockout{
+				Scope:    s.scope,
+				Value:    s.value,
+				Level:    int(result[1]),
+				Duration: time.Duration(result[0]) * time.Second,
+			})
+		}
+	}
+
+	for _, lockout := range lockouts {
+		log.Printf("🚫 Login bloqueado por %v (%s: %s, nível %d)", lockout.Duration, lockout.Scope, lockout.Value, lockout.Level)
+		if err := SaveToMongo("auth_events", map[string]interface{}{
+			"event":            "login_lockout",
+			"scope":            lockout.Scope,
+			"valor":            lockout.Value,
+			"nivel":            lockout.Level,
+			"duracao_segundos": int(lockout.Duration.Seconds()),
+			"username":         username,
+			"ip":               ip,
+			"timestamp":        time.Now(),
+		}); err != nil {
+			log.Printf("❌ Erro ao registrar bloqueio de login no MongoDB: %v", err)
+		}
+	}
+	return lockouts, nil
+}
+
+// ResetLoginFailures zera as falhas e o nível de bloqueio do username após um login bem-sucedido.
+// O contador do IP é mantido: um login válido não deve liberar tentativas contra outras contas.
+func ResetLoginFailures(ctx context.Context, username string) {
+	value := normalizeLoginUsername(username)
+	if err := config.RedisClient.Del(ctx, loginFailuresKey("username", value), loginLevelKey("username", value)).Err(); err != nil {
+		log.Printf("⚠️ Erro ao zerar falhas de login de '%s': %v", username, err)
+	}
+}
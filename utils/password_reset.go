@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"apiBackEnd/config"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redefinição de senha emitida pelo super-admin
+//
+// O admin gera um token de uso único para a revenda; a revenda (ou o suporte) o troca por
+// uma nova senha em /password-reset. No Redis guardamos apenas o SHA-256 do token
+// (`password_reset:<hash>` → member_id) e, por revenda, o hash do token vigente
+// (`password_reset_revenda:<member_id>`), para que emitir um novo invalide o anterior.
+//
+//	PASSWORD_RESET_MINUTOS=30  validade do token
+
+var ErrPasswordResetInvalid = errors.New("token de redefinição inválido ou expirado")
+
+func passwordResetKey(tokenHash string) string {
+	return "password_reset:" + tokenHash
+}
+
+func passwordResetMemberKey(memberID int) string {
+	return "password_reset_revenda:" + strconv.Itoa(memberID)
+}
+
+// GetPasswordResetExpiration retorna a validade do token (PASSWORD_RESET_MINUTOS, padrão 30)
+func GetPasswordResetExpiration() time.Duration {
+	return time.Duration(envPositiveInt("PASSWORD_RESET_MINUTOS", 30)) * time.Minute
+}
+
+// CreatePasswordResetToken emite um token de uso único para a revenda, invalidando o anterior
+func CreatePasswordResetToken(ctx context.Context, memberID int) (string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	tokenHash := hashRefreshSecret(token)
+	ttl := GetPasswordResetExpiration()
+
+	if previous, err := config.RedisClient.Get(ctx, passwordResetMemberKey(memberID)).Result(); err == nil {
+		config.RedisClient.Del(ctx, passwordResetKey(previous))
+	}
+
+	pipe := config.RedisClient.TxPipeline()
+	pipe.Set(ctx, passwordResetKey(tokenHash), memberID, ttl)
+	pipe.Set(ctx, passwordResetMemberKey(memberID), tokenHash, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ConsumePasswordResetToken valida e apaga o token na mesma operação, retornando o member_id
+func ConsumePasswordResetToken(ctx context.Context, token string) (int, error) {
+	if token == "" {
+		return 0, ErrPasswordResetInvalid
+	}
+	tokenHash := hashRefreshSecret(token)
+	memberID, err := config.RedisClient.GetDel(ctx, passwordResetKey(tokenHash)).Int()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, ErrPasswordResetInvalid
+		}
+		return 0, err
+	}
+	config.RedisClient.Del(ctx, passwordResetMemberKey(memberID))
+	return memberID, nil
+}
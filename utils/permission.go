@@ -2,13 +2,15 @@ package utils
 
 import (
 	"apiBackEnd/config"
+	"apiBackEnd/models"
 	"database/sql"
 )
 
 // VerificaPermissaoUsuario checa se a revenda tem permissão para modificar o usuário
 // Retorna: permitido (bool), responsibleMemberID (int), erro (error)
-func VerificaPermissaoUsuario(userID, revendaResponsavel int) (bool, int, error) {
-	// Verificação de segurança: apenas o membro responsável ou super admin pode alterar o usuário
+func VerificaPermissaoUsuario(userID, revendaResponsavel int, role Role) (bool, int, error) {
+	// Verificação de segurança: apenas o membro responsável, uma revenda acima dele na árvore
+	// ou um papel com acesso a todos os clientes pode alterar o usuário
 	var responsibleMemberID int
 	checkQuery := "SELECT member_id FROM streamcreed_db.users WHERE id = ?"
 	err := config.DB.QueryRow(checkQuery, userID).Scan(&responsibleMemberID)
@@ -19,11 +21,32 @@ func VerificaPermissaoUsuario(userID, revendaResponsavel int) (bool, int, error)
 		return false, 0, err
 	}
 
-	// Super admin (ID 1) tem acesso a tudo, ou se for a própria revenda responsável
-	isAdmin := revendaResponsavel == 1
-	if isAdmin || revendaResponsavel == responsibleMemberID {
-		return true, responsibleMemberID, nil
+	permitido, err := PodeGerenciarRevenda(revendaResponsavel, responsibleMemberID, role)
+	if err != nil {
+		return false, responsibleMemberID, err
+	}
+	return permitido, responsibleMemberID, nil
+}
+
+// PodeGerenciarRevenda informa se a revenda `revendaID` pode agir sobre os clientes e dados da revenda `alvoID`:
+// a própria revenda, uma master acima dela na árvore de sub-revendas, ou um papel com acesso a todos os clientes.
+func PodeGerenciarRevenda(revendaID, alvoID int, role Role) (bool, error) {
+	if revendaID == alvoID || HasPermission(role, PermClientsAll) {
+		return true, nil
 	}
+	return models.IsSubReseller(revendaID, alvoID)
+}
 
-	return false, responsibleMemberID, nil
+// RevendasGerenciadas retorna o ID da revenda seguido dos IDs de todas as sub-revendas abaixo dela
+func RevendasGerenciadas(revendaID int) ([]int, error) {
+	subs, err := models.ListSubResellers(revendaID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(subs)+1)
+	ids = append(ids, revendaID)
+	for _, sub := range subs {
+		ids = append(ids, sub.ID)
+	}
+	return ids, nil
 }
@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"apiBackEnd/models"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Motor de preços
+//
+// O preço de uma operação sai das regras em streamcreed_db.pricing_rules. Para a revenda valem
+// as regras específicas dela e, na falta de uma que se aplique, as padrão (member_id NULL).
+// Entre as que se aplicam (meses, telas, dias restantes, conversão de teste e vigência), vence a
+// primeira regra de preço base e a primeira de desconto, nessa ordem: específicas antes das
+// padrão, depois maior prioridade. Sem nenhuma regra cadastrada, valem os preços internos abaixo,
+// equivalentes aos valores fixos usados antes das regras existirem.
+//
+// Renovações por dias são proporcionais: o preço por mês vale para 30 dias e é cobrado por dia.
+// Os filtros de meses das regras usam os meses iniciados (ex.: 45 dias = 2 meses). O total
+// proporcional é arredondado pela política configurada:
+//
+//	RENOVACAO_ARREDONDAMENTO=cima        cima | baixo | proximo
+//	RENOVACAO_ARREDONDAMENTO_PASSO=0.01  múltiplo do arredondamento (ex.: 0.5 ou 1 crédito)
+
+// PricingInput descreve a operação a ser orçada
+type PricingInput struct {
+	MemberID       int
+	Produto        string
+	Meses          int // Renovação
+	Dias           int // Renovação proporcional por dias (0 = por meses)
+	Telas          int // Renovação: telas do cliente; tela adicional: total de telas após a adição
+	DiasRestantes  int // Tela adicional: dias até o vencimento do cliente
+	ConversaoTeste bool
+}
+
+func intPtr(v int) *int { return &v }
+
+// builtinPricingRules são usadas quando não há regra cadastrada que se aplique
+var builtinPricingRules = []models.PricingRule{
+	{Produto: models.ProdutoRenovacao, Modo: models.PrecoPorMesTela, Valor: 1, Descricao: "1 crédito por mês por tela"},
+	{Produto: models.ProdutoTelaAdicional, Modo: models.PrecoFixo, Valor: 0.5, DiasRestantesMax: intPtr(15), Descricao: "Tela adicional com até 15 dias restantes"},
+	{Produto: models.ProdutoTelaAdicional, Modo: models.PrecoFixo, Valor: 1, DiasRestantesMin: intPtr(16), DiasRestantesMax: intPtr(30), Descricao: "Tela adicional com 16 a 30 dias restantes"},
+	{Produto: models.ProdutoTelaAdicional, Modo: models.PrecoPorPeriodo30d, Valor: 1, DiasRestantesMin: intPtr(31), Descricao: "Tela adicional: 1 crédito a cada 30 dias restantes"},
+}
+
+func roundCredits(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// GetRenewalRounding retorna a política e o passo de arredondamento das renovações proporcionais
+func GetRenewalRounding() (string, float64) {
+	modo := strings.ToLower(strings.TrimSpace(os.Getenv("RENOVACAO_ARREDONDAMENTO")))
+	if modo != "baixo" && modo != "proximo" {
+		modo = "cima"
+	}
+	passo, err := strconv.ParseFloat(os.Getenv("RENOVACAO_ARREDONDAMENTO_PASSO"), 64)
+	if err != nil || passo <= 0 {
+		passo = 0.01
+	}
+	return modo, passo
+}
+
+// roundToStep arredonda v para um múltiplo de passo segundo o modo
+func roundToStep(v float64, modo string, passo float64) float64 {
+	// A tolerância evita que erros de ponto flutuante (ex.: 2.0000000001) subam um passo inteiro
+	q := v / passo
+	switch modo {
+	case "baixo":
+		q = math.Floor(q + 1e-9)
+	case "proximo":
+		q = math.Round(q)
+	default:
+		q = math.Ceil(q - 1e-9)
+	}
+	return roundCredits(q * passo)
+}
+
+func inRange(v int, min, max *int) bool {
+	return (min == nil || v >= *min) && (max == nil || v <= *max)
+}
+
+// ruleApplies informa se a regra vale para a operação no instante `now`
+func ruleApplies(rule models.PricingRule, in PricingInput, now int64) bool {
+	if rule.Produto != in.Produto {
+		return false
+	}
+	if !inRange(in.Meses, rule.MesesMin, rule.MesesMax) || !inRange(in.Telas, rule.TelasMin, rule.TelasMax) ||
+		!inRange(in.DiasRestantes, rule.DiasRestantesMin, rule.DiasRestantesMax) {
+		return false
+	}
+	if rule.ConversaoTeste != nil && *rule.ConversaoTeste != in.ConversaoTeste {
+		return false
+	}
+	if (rule.VigenciaInicio != nil && now < *rule.VigenciaInicio) || (rule.VigenciaFim != nil && now > *rule.VigenciaFim) {
+		return false
+	}
+	return true
+}
+
+// basePrice calcula o subtotal da regra de preço base e descreve o cálculo
+func basePrice(rule models.PricingRule, in PricingInput) (float64, string) {
+	switch rule.Modo {
+	case models.PrecoPorMesTela:
+		if in.Dias > 0 {
+			return rule.Valor * float64(in.Dias) / 30 * float64(in.Telas),
+				fmt.Sprintf("%.2f crédito(s) x %d dia(s)/30 x %d tela(s)", rule.Valor, in.Dias, in.Telas)
+		}
+		return rule.Valor * float64(in.Meses) * float64(in.Telas),
+			fmt.Sprintf("%.2f crédito(s) x %d mês(es) x %d tela(s)", rule.Valor, in.Meses, in.Telas)
+	case models.PrecoPorPeriodo30d:
+		periodos := int(math.Ceil(float64(in.DiasRestantes) / 30))
+		if periodos < 1 {
+			periodos = 1
+		}
+		return rule.Valor * float64(periodos),
+			fmt.Sprintf("%.2f crédito(s) x %d período(s) de 30 dias", rule.Valor, periodos)
+	default:
+		return rule.Valor, fmt.Sprintf("Valor fixo de %.2f crédito(s)", rule.Valor)
+	}
+}
+
+// QuotePrice calcula o custo da operação com o detalhamento das regras aplicadas, sem cobrar nada
+func QuotePrice(in PricingInput) (*models.PriceQuote, error) {
+	if in.Produto == models.ProdutoRenovacao && in.Dias > 0 {
+		// Os filtros de meses das regras consideram os meses iniciados
+		in.Meses = (in.Dias + 29) / 30
+	}
+	if in.Produto == models.ProdutoRenovacao && (in.Meses < 1 || in.Telas < 1) {
+		return nil, fmt.Errorf("renovação exige meses (ou dias) e telas maiores que zero")
+	}
+	if in.Produto != models.ProdutoRenovacao && in.Produto != models.ProdutoTelaAdicional {
+		return nil, fmt.Errorf("produto desconhecido: %s", in.Produto)
+	}
+
+	rules, err := models.ListPricingRules(in.MemberID, in.Produto)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar regras de preço: %w", err)
+	}
+	return quoteWithRules(in, rules, time.Now().Unix())
+}
+
+// quoteWithRules aplica as regras carregadas (já na ordem de ListPricingRules) à operação no instante `now`
+func quoteWithRules(in PricingInput, rules []models.PricingRule, now int64) (*models.PriceQuote, error) {
+	var base, desconto *models.PricingRule
+	for i := range rules {
+		if !ruleApplies(rules[i], in, now) {
+			continue
+		}
+		if rules[i].Modo == models.PrecoModoDesconto {
+			if desconto == nil {
+				desconto = &rules[i]
+			}
+		} else if base == nil {
+			base = &rules[i]
+		}
+	}
+	if base == nil {
+		for i := range builtinPricingRules {
+			if ruleApplies(builtinPricingRules[i], in, now) {
+				base = &builtinPricingRules[i]
+				break
+			}
+		}
+	}
+	if base == nil {
+		return nil, fmt.Errorf("nenhuma regra de preço se aplica a %s", in.Produto)
+	}
+
+	subtotal, calculo := basePrice(*base, in)
+	subtotal = roundCredits(subtotal)
+	quote := &models.PriceQuote{
+		Produto:        in.Produto,
+		Meses:          in.Meses,
+		Dias:           in.Dias,
+		Telas:          in.Telas,
+		DiasRestantes:  in.DiasRestantes,
+		ConversaoTeste: in.ConversaoTeste,
+		Subtotal:       subtotal,
+		Detalhamento: []models.PriceQuoteLine{{
+			Descricao: fmt.Sprintf("%s (%s)", base.Descricao, calculo),
+			RegraID:   base.ID,
+			Valor:     subtotal,
+		}},
+	}
+
+	if desconto != nil && desconto.DescontoPercentual > 0 {
+		quote.Desconto = roundCredits(subtotal * desconto.DescontoPercentual / 100)
+		if quote.Desconto > subtotal {
+			quote.Desconto = subtotal
+		}
+		quote.Detalhamento = append(quote.Detalhamento, models.PriceQuoteLine{
+			Descricao: fmt.Sprintf("%s (-%.2f%%)", desconto.Descricao, desconto.DescontoPercentual),
+			RegraID:   desconto.ID,
+			Valor:     -quote.Desconto,
+		})
+	}
+	quote.Total = roundCredits(subtotal - quote.Desconto)
+
+	if in.Dias > 0 {
+		modo, passo := GetRenewalRounding()
+		if arredondado := roundToStep(quote.Total, modo, passo); arredondado != quote.Total {
+			quote.Detalhamento = append(quote.Detalhamento, models.PriceQuoteLine{
+				Descricao: fmt.Sprintf("Arredondamento para %s (múltiplos de %.2f)", modo, passo),
+				Valor:     roundCredits(arredondado - quote.Total),
+			})
+			quote.Total = arredondado
+		}
+	}
+	return quote, nil
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"apiBackEnd/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func boolPtr(v bool) *bool    { return &v }
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestQuotePriceValidation(t *testing.T) {
+	casos := []struct {
+		nome string
+		in   PricingInput
+	}{
+		{"renovação sem meses", PricingInput{Produto: models.ProdutoRenovacao, Telas: 1}},
+		{"renovação sem telas", PricingInput{Produto: models.ProdutoRenovacao, Meses: 1}},
+		{"produto desconhecido", PricingInput{Produto: "outro", Meses: 1, Telas: 1}},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			_, err := QuotePrice(tc.in)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestQuoteWithRules(t *testing.T) {
+	t.Setenv("RENOVACAO_ARREDONDAMENTO", "cima")
+	t.Setenv("RENOVACAO_ARREDONDAMENTO_PASSO", "0.5")
+	const now = int64(1_700_000_000)
+
+	// Na ordem de ListPricingRules: maior prioridade primeiro
+	revenda := []models.PricingRule{
+		{ID: 13, Produto: models.ProdutoRenovacao, Modo: models.PrecoFixo, Valor: 0.5, ConversaoTeste: boolPtr(true), Prioridade: 9, Descricao: "Conversão"},
+		{ID: 10, Produto: models.ProdutoRenovacao, Modo: models.PrecoPorMesTela, Valor: 2, TelasMin: intPtr(3), Descricao: "Revenda: 3+ telas"},
+		{ID: 11, Produto: models.ProdutoRenovacao, Modo: models.PrecoPorMesTela, Valor: 1.5, Descricao: "Revenda: padrão"},
+		{ID: 12, Produto: models.ProdutoRenovacao, Modo: models.PrecoModoDesconto, DescontoPercentual: 10, MesesMin: intPtr(6), Descricao: "Semestral"},
+		{ID: 14, Produto: models.ProdutoRenovacao, Modo: models.PrecoModoDesconto, DescontoPercentual: 50, VigenciaFim: int64Ptr(now - 1), Descricao: "Promoção encerrada"},
+	}
+
+	casos := []struct {
+		nome     string
+		in       PricingInput
+		rules    []models.PricingRule
+		total    float64
+		regraID  int
+		desconto float64
+	}{
+		{"sem regras usa o preço interno", PricingInput{Produto: models.ProdutoRenovacao, Meses: 2, Telas: 2}, nil, 4, 0, 0},
+		{"tela adicional interna até 15 dias", PricingInput{Produto: models.ProdutoTelaAdicional, Telas: 2, DiasRestantes: 10}, nil, 0.5, 0, 0},
+		{"tela adicional interna de 16 a 30 dias", PricingInput{Produto: models.ProdutoTelaAdicional, Telas: 2, DiasRestantes: 20}, nil, 1, 0, 0},
+		{"tela adicional interna por período de 30 dias", PricingInput{Produto: models.ProdutoTelaAdicional, Telas: 2, DiasRestantes: 61}, nil, 3, 0, 0},
+		{"primeira regra base que se aplica", PricingInput{Produto: models.ProdutoRenovacao, Meses: 1, Telas: 3}, revenda[1:3], 6, 10, 0},
+		{"filtro de telas pula a regra", PricingInput{Produto: models.ProdutoRenovacao, Meses: 1, Telas: 1}, revenda[1:3], 1.5, 11, 0},
+		{"desconto pela quantidade de meses", PricingInput{Produto: models.ProdutoRenovacao, Meses: 6, Telas: 1}, revenda, 8.1, 11, 0.9},
+		{"regra de conversão de teste", PricingInput{Produto: models.ProdutoRenovacao, Meses: 1, Telas: 1, ConversaoTeste: true}, revenda, 0.5, 13, 0},
+		{"proporcional por dias arredonda para cima", PricingInput{Produto: models.ProdutoRenovacao, Meses: 1, Dias: 11, Telas: 1}, revenda[1:3], 1, 11, 0},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			quote, err := quoteWithRules(tc.in, tc.rules, now)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.InDelta(t, tc.total, quote.Total, 0.001)
+			assert.InDelta(t, tc.desconto, quote.Desconto, 0.001)
+			assert.Equal(t, tc.regraID, quote.Detalhamento[0].RegraID)
+		})
+	}
+}
+
+func TestRoundToStep(t *testing.T) {
+	casos := []struct {
+		v     float64
+		modo  string
+		passo float64
+		want  float64
+	}{
+		{1.01, "cima", 0.5, 1.5},
+		{1.5, "cima", 0.5, 1.5},
+		{2.0000000001, "cima", 1, 2},
+		{1.99, "baixo", 1, 1},
+		{1.24, "proximo", 0.5, 1},
+		{1.26, "proximo", 0.5, 1.5},
+		{0.333, "cima", 0.01, 0.34},
+	}
+	for _, tc := range casos {
+		assert.InDelta(t, tc.want, roundToStep(tc.v, tc.modo, tc.passo), 0.0001, "%v %s %v", tc.v, tc.modo, tc.passo)
+	}
+}
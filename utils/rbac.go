@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Controle de acesso baseado em papéis (RBAC)
+//
+// O papel da revenda é derivado de reg_users.member_group_id. Os grupos de cada papel
+// são configurados no .env:
+//
+//	RBAC_GRUPOS_ADMIN=1
+//	RBAC_GRUPOS_REVENDA=2,3
+//
+// Se RBAC_GRUPOS_REVENDA estiver vazio, qualquer grupo que não seja admin é tratado
+// como revenda. Com a lista preenchida, grupos fora das duas listas ficam sem permissões.
+
+// Role é o papel de quem está autenticado
+type Role string
+
+const (
+	RoleAdmin   Role = "admin"
+	RoleRevenda Role = "revenda"
+	RoleNenhum  Role = "" // Grupo sem papel configurado: nenhuma permissão
+)
+
+// Permission identifica uma ação protegida da API
+type Permission string
+
+const (
+	PermClientsRead   Permission = "clients:read"        // Listar/consultar clientes e erros
+	PermClientsAll    Permission = "clients:all"         // Agir sobre clientes de qualquer revenda
+	PermCreateTest    Permission = "clients:create_test" // Criar teste
+	PermCreateClient  Permission = "clients:create"      // Criar cliente pago
+	PermRenew         Permission = "clients:renew"       // Renovar
+	PermScreens       Permission = "clients:screens"     // Adicionar/remover telas
+	PermEdit          Permission = "clients:edit"        // Editar dados do cliente
+	PermTrustBonus    Permission = "clients:trust_bonus" // Liberação em confiança
+	PermRollback      Permission = "clients:rollback"    // Desfazer renovação
+	PermDueDate       Permission = "clients:due_date"    // Alterar vencimento
+	PermStatus        Permission = "clients:status"      // Ativar/desativar conta
+	PermRegion        Permission = "clients:region"      // Forçar região
+	PermKick          Permission = "clients:kick"        // Derrubar conexão do cliente
+	PermDelete        Permission = "clients:delete"      // Exclusão lógica
+	PermRestore       Permission = "clients:restore"     // Listar excluídos e restaurar
+	PermDashboardRead Permission = "dashboard:read"
+	PermCreditsRead   Permission = "credits:read"
+	PermPricingRead   Permission = "pricing:read"             // Orçar renovações e telas
+	PermSessionsOwn   Permission = "sessions:own"             // Gerenciar as próprias sessões
+	PermSessionsAdmin Permission = "sessions:admin"           // Listar/encerrar sessões de outras revendas
+	PermTwoFactor     Permission = "account:2fa"              // Configurar o próprio 2FA
+	PermAPIKeys       Permission = "account:api_keys"         // Gerenciar as próprias chaves de API
+	PermSubResellers  Permission = "resellers:sub"            // Criar sub-revendas e transferir créditos
+	PermPassword      Permission = "account:password"         // Trocar a própria senha
+	PermPasswordReset Permission = "resellers:password_reset" // Emitir token de redefinição de senha de outra revenda
+	PermImpersonate   Permission = "resellers:impersonate"    // Abrir sessão somente leitura em nome de outra revenda
+)
+
+// rolePermissions define as permissões de cada papel
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermClientsRead, PermClientsAll, PermCreateTest, PermCreateClient, PermRenew, PermScreens, PermEdit,
+		PermTrustBonus, PermRollback, PermDueDate, PermStatus, PermRegion, PermKick,
+		PermDelete, PermRestore, PermDashboardRead, PermCreditsRead, PermPricingRead,
+		PermSessionsOwn, PermSessionsAdmin, PermTwoFactor, PermAPIKeys, PermSubResellers,
+		PermPassword, PermPasswordReset, PermImpersonate,
+	},
+	RoleRevenda: {
+		PermClientsRead, PermCreateTest, PermCreateClient, PermRenew, PermScreens, PermEdit,
+		PermTrustBonus, PermRollback, PermDueDate, PermStatus, PermRegion, PermKick,
+		PermDelete, PermRestore, PermDashboardRead, PermCreditsRead, PermPricingRead,
+		PermSessionsOwn, PermTwoFactor, PermAPIKeys, PermSubResellers, PermPassword,
+	},
+}
+
+// parseGroupList lê uma lista de member_group_id separados por vírgula
+func parseGroupList(envName, defaultValue string) map[int]bool {
+	raw := os.Getenv(envName)
+	if strings.TrimSpace(raw) == "" {
+		raw = defaultValue
+	}
+	groups := make(map[int]bool)
+	for _, item := range strings.Split(raw, ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(item)); err == nil {
+			groups[id] = true
+		}
+	}
+	return groups
+}
+
+// RoleFromGroup converte o member_group_id da revenda no papel correspondente
+func RoleFromGroup(memberGroupID int) Role {
+	if parseGroupList("RBAC_GRUPOS_ADMIN", "1")[memberGroupID] {
+		return RoleAdmin
+	}
+	resellerGroups := parseGroupList("RBAC_GRUPOS_REVENDA", "")
+	if len(resellerGroups) == 0 || resellerGroups[memberGroupID] {
+		return RoleRevenda
+	}
+	return RoleNenhum
+}
+
+// RoleFromClaims obtém o papel a partir do member_group_id gravado no token
+func RoleFromClaims(claims jwt.MapClaims) Role {
+	groupID, ok := claims["member_group_id"].(float64)
+	if !ok {
+		return RoleNenhum
+	}
+	return RoleFromGroup(int(groupID))
+}
+
+// HasPermission informa se o papel pode executar a ação
+func HasPermission(role Role, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
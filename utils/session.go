@@ -0,0 +1,436 @@
+package utils
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// Quantidade máxima de refresh tokens antigos guardados por sessão para detectar reuso
+const maxRefreshUsados = 20
+
+var (
+	ErrSessionNotFound     = errors.New("sessão não encontrada ou expirada")
+	ErrRefreshTokenInvalid = errors.New("refresh token inválido")
+	ErrRefreshTokenReused  = errors.New("refresh token reutilizado: sessão revogada")
+)
+
+// TokenPair agrupa os tokens emitidos para uma sessão
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	SessionID    string
+	ExpiresIn    int64 // Segundos até o access token expirar
+}
+
+// GetAccessTokenExpiration retorna a duração do access token (TOKEN_EXPIRATION_MINUTES, padrão 15 minutos)
+func GetAccessTokenExpiration() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("TOKEN_EXPIRATION_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 15
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// GetRefreshTokenExpiration retorna a duração do refresh token (REFRESH_TOKEN_EXPIRATION_DIAS, padrão 30 dias)
+func GetRefreshTokenExpiration() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("REFRESH_TOKEN_EXPIRATION_DIAS"))
+	if err != nil || days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// sessionKey monta a chave Redis da sessão
+func sessionKey(memberID int, sessionID string) string {
+	return "token:" + strconv.Itoa(memberID) + ":" + sessionID
+}
+
+// lastUseKey é o hash Redis (session_id → unix) com o último uso de cada sessão da revenda.
+// Fica fora da chave da sessão para que o registro de uso não conflite com a rotação de refresh (WATCH).
+func lastUseKey(memberID int) string {
+	return "token_last_use:" + strconv.Itoa(memberID)
+}
+
+// randomToken gera `size` bytes aleatórios codificados em base64 URL-safe
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// randomID gera um identificador hexadecimal de 16 bytes
+func randomID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashRefreshSecret calcula o SHA-256 do segredo do refresh token (nunca guardamos o valor puro)
+func hashRefreshSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseRefreshToken separa o refresh token no formato `<member_id>.<session_id>.<segredo>`
+func parseRefreshToken(refreshToken string) (int, string, string, error) {
+	parts := strings.SplitN(strings.TrimSpace(refreshToken), ".", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return 0, "", "", ErrRefreshTokenInvalid
+	}
+	memberID, err := strconv.Atoi(parts[0])
+	if err != nil || memberID <= 0 {
+		return 0, "", "", ErrRefreshTokenInvalid
+	}
+	return memberID, parts[1], parts[2], nil
+}
+
+// RefreshTokenMemberID extrai o member_id do refresh token sem validá-lo
+func RefreshTokenMemberID(refreshToken string) (int, error) {
+	memberID, _, _, err := parseRefreshToken(refreshToken)
+	return memberID, err
+}
+
+// GetSession busca uma sessão no Redis
+func GetSession(ctx context.Context, memberID int, sessionID string) (*models.Session, error) {
+	val, err := config.RedisClient.Get(ctx, sessionKey(memberID, sessionID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	var session models.Session
+	if err := json.Unmarshal([]byte(val), &session); err != nil {
+		return nil, fmt.Errorf("sessão corrompida: %w", err)
+	}
+	return &session, nil
+}
+
+// RevokeSession remove uma sessão do Redis (logout de um único dispositivo)
+func RevokeSession(ctx context.Context, memberID int, sessionID string) error {
+	_, err := config.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(memberID, sessionID))
+		pipe.HDel(ctx, lastUseKey(memberID), sessionID)
+		return nil
+	})
+	return err
+}
+
+// scanSessionKeys lista as chaves `token:<member_id>:*` da revenda
+func scanSessionKeys(ctx context.Context, memberID int) ([]string, error) {
+	var keys []string
+	iter := config.RedisClient.Scan(ctx, 0, "token:"+strconv.Itoa(memberID)+":*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
+// RevokeAllSessions remove todas as sessões da revenda, exceto `keepSessionID` (se informado).
+// Retorna a quantidade de sessões encerradas.
+func RevokeAllSessions(ctx context.Context, memberID int, keepSessionID string) (int, error) {
+	keys, err := scanSessionKeys(ctx, memberID)
+	if err != nil {
+		return 0, err
+	}
+
+	var toDelete []string
+	var sessionIDs []string
+	for _, key := range keys {
+		sessionID := strings.TrimPrefix(key, "token:"+strconv.Itoa(memberID)+":")
+		if keepSessionID != "" && sessionID == keepSessionID {
+			continue
+		}
+		toDelete = append(toDelete, key)
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	if len(toDelete) == 0 {
+		return 0, nil
+	}
+
+	_, err = config.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, toDelete...)
+		pipe.HDel(ctx, lastUseKey(memberID), sessionIDs...)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(toDelete), nil
+}
+
+// ListSessions retorna as sessões ativas da revenda, da mais recente para a mais antiga
+func ListSessions(ctx context.Context, memberID int, currentSessionID string) ([]models.SessionInfo, error) {
+	keys, err := scanSessionKeys(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]models.SessionInfo, 0, len(keys))
+	if len(keys) == 0 {
+		return sessions, nil
+	}
+
+	values, err := config.RedisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	lastUse, err := config.RedisClient.HGetAll(ctx, lastUseKey(memberID)).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	for _, val := range values {
+		raw, ok := val.(string)
+		if !ok {
+			continue // Sessão expirou entre o SCAN e o MGET
+		}
+		var session models.Session
+		if err := json.Unmarshal([]byte(raw), &session); err != nil {
+			log.Printf("⚠️ Sessão corrompida ignorada para member_id %d: %v", memberID, err)
+			continue
+		}
+		info := models.SessionInfo{
+			SessionID: session.SessionID,
+			IP:        session.IP,
+			UserAgent: session.UserAgent,
+			CriadoEm:  session.CriadoEm,
+			ExpiraEm:  session.ExpiraEm,
+			Atual:     session.SessionID == currentSessionID,
+
+			PersonificadaPor: session.ImpersonatedBy,
+		}
+		if ts, ok := lastUse[session.SessionID]; ok {
+			if unix, err := strconv.ParseInt(ts, 10, 64); err == nil {
+				t := time.Unix(unix, 0)
+				info.UltimoUso = &t
+			}
+		}
+		sessions = append(sessions, info)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CriadoEm.After(sessions[j].CriadoEm)
+	})
+	return sessions, nil
+}
+
+// TouchSession registra o último uso da sessão
+func TouchSession(ctx context.Context, memberID int, sessionID string) {
+	key := lastUseKey(memberID)
+	_, err := config.RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, sessionID, time.Now().Unix())
+		pipe.Expire(ctx, key, GetRefreshTokenExpiration())
+		return nil
+	})
+	if err != nil {
+		log.Printf("⚠️ Erro ao registrar último uso da sessão %s (member_id %d): %v", sessionID, memberID, err)
+	}
+}
+
+// prepareSessionTokens gera um novo access token e um novo refresh token para a sessão,
+// atualizando os campos da sessão em memória. Quem chama é responsável por persistir a sessão.
+func prepareSessionTokens(session *models.Session, credits float64, status string) (*TokenPair, error) {
+	jti, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	refreshSecret, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	accessExpiration := GetAccessTokenExpiration()
+	if session.ImpersonatedBy != 0 {
+		accessExpiration = GetImpersonationExpiration()
+	}
+	now := time.Now()
+
+	// 🔥 Claims com campos 100% visíveis no JWT.io
+	claims := jwt.MapClaims{
+		"username":        session.Username,
+		"member_id":       session.MemberID,
+		"member_group_id": session.MemberGroupID,
+		"exp":             now.Add(accessExpiration).Unix(),
+		"iat":             now.Unix(),
+		"credits":         credits,
+		"status":          status,
+		"sid":             session.SessionID,
+		"jti":             jti,
+	}
+	// 🕵️ Personificação: o token carrega também a identidade do operador real
+	if session.ImpersonatedBy != 0 {
+		claims["impersonated_by"] = session.ImpersonatedBy
+		claims["impersonator_username"] = session.ImpersonatorUsername
+	}
+	accessToken, err := signToken(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// 🔄 Guarda o hash do refresh anterior para detectar reuso
+	if session.RefreshHash != "" {
+		session.RefreshUsados = append(session.RefreshUsados, session.RefreshHash)
+		if len(session.RefreshUsados) > maxRefreshUsados {
+			session.RefreshUsados = session.RefreshUsados[len(session.RefreshUsados)-maxRefreshUsados:]
+		}
+	}
+	session.AccessJTI = jti
+	session.RefreshHash = hashRefreshSecret(refreshSecret)
+	session.ExpiraEm = now.Add(GetRefreshTokenExpiration())
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: strconv.Itoa(session.MemberID) + "." + session.SessionID + "." + refreshSecret,
+		SessionID:    session.SessionID,
+		ExpiresIn:    int64(accessExpiration.Seconds()),
+	}, nil
+}
+
+// GenerateToken cria uma nova sessão (um dispositivo) e emite access token + refresh token
+func GenerateToken(username string, memberID int, memberGroupID int, credits float64, status string, ip string, userAgent string) (*TokenPair, error) {
+	ctx := context.Background()
+
+	sessionID, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	session := &models.Session{
+		SessionID:     sessionID,
+		MemberID:      memberID,
+		Username:      username,
+		MemberGroupID: memberGroupID,
+		IP:            ip,
+		UserAgent:     userAgent,
+		CriadoEm:      time.Now(),
+	}
+
+	pair, err := prepareSessionTokens(session, credits, status)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	// 🔥 **Armazena a sessão no Redis com TTL igual ao do refresh token**
+	if err := config.RedisClient.Set(ctx, sessionKey(memberID, sessionID), data, GetRefreshTokenExpiration()).Err(); err != nil {
+		return nil, err
+	}
+	TouchSession(ctx, memberID, sessionID)
+
+	return pair, nil
+}
+
+// RefreshSession valida o refresh token, detecta reuso e rotaciona os tokens da sessão.
+// A leitura e a gravação da sessão acontecem numa transação WATCH, evitando que duas
+// renovações simultâneas com o mesmo refresh token sejam aceitas.
+func RefreshSession(ctx context.Context, refreshToken string, username string, memberGroupID int, credits float64, status string, ip string, userAgent string) (*TokenPair, error) {
+	memberID, sessionID, secret, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	key := sessionKey(memberID, sessionID)
+	presentedHash := hashRefreshSecret(secret)
+
+	var pair *TokenPair
+	reused := false
+	txf := func(tx *redis.Tx) error {
+		val, err := tx.Get(ctx, key).Result()
+		if err != nil {
+			if err == redis.Nil {
+				return ErrSessionNotFound
+			}
+			return err
+		}
+		var session models.Session
+		if err := json.Unmarshal([]byte(val), &session); err != nil {
+			return fmt.Errorf("sessão corrompida: %w", err)
+		}
+
+		// Sessões de personificação não são renováveis
+		if session.ImpersonatedBy != 0 {
+			return ErrRefreshTokenInvalid
+		}
+
+		if subtle.ConstantTimeCompare([]byte(session.RefreshHash), []byte(presentedHash)) != 1 {
+			for _, used := range session.RefreshUsados {
+				if subtle.ConstantTimeCompare([]byte(used), []byte(presentedHash)) == 1 {
+					reused = true
+					// 🚨 Refresh já rotacionado foi apresentado de novo: revoga a sessão inteira
+					_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+						pipe.Del(ctx, key)
+						return nil
+					})
+					if err != nil {
+						return err
+					}
+					return ErrRefreshTokenReused
+				}
+			}
+			return ErrRefreshTokenInvalid
+		}
+
+		session.Username = username
+		session.MemberGroupID = memberGroupID
+		session.IP = ip
+		session.UserAgent = userAgent
+		pair, err = prepareSessionTokens(&session, credits, status)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(session)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, GetRefreshTokenExpiration())
+			return nil
+		})
+		return err
+	}
+
+	err = config.RedisClient.Watch(ctx, txf, key)
+	if err == redis.TxFailedErr {
+		// Outra requisição rotacionou a sessão ao mesmo tempo: este refresh não vale mais
+		return nil, ErrRefreshTokenInvalid
+	}
+	if reused {
+		log.Printf("🚨 Reuso de refresh token detectado (member_id: %d, sessão: %s). Sessão revogada.", memberID, sessionID)
+		if logErr := SaveToMongo("auth_events", map[string]interface{}{
+			"event":      "refresh_token_reuse",
+			"member_id":  memberID,
+			"session_id": sessionID,
+			"timestamp":  time.Now(),
+		}); logErr != nil {
+			log.Printf("❌ Erro ao registrar reuso de refresh token no MongoDB: %v", logErr)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	TouchSession(ctx, memberID, sessionID)
+	return pair, nil
+}
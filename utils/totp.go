@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// TOTP (RFC 6238) para o segundo fator do login das revendas
+//
+// Parâmetros compatíveis com Google Authenticator, Authy, etc.: SHA1, 6 dígitos, passo de 30s.
+// O segredo é gravado no banco cifrado com AES-256-GCM usando a chave TOTP_ENCRYPTION_KEY do .env.
+
+const (
+	totpDigits      = 6
+	totpPeriod      = 30 // segundos
+	totpSkew        = 1  // passos aceitos antes/depois do atual (tolerância de relógio)
+	totpSecretBytes = 20
+	recoveryCodes   = 10
+)
+
+var (
+	ErrTOTPKeyMissing    = errors.New("TOTP_ENCRYPTION_KEY não configurada")
+	ErrTOTPCipherInvalid = errors.New("segredo TOTP cifrado inválido")
+)
+
+// GetTOTPIssuer retorna o nome exibido no app autenticador
+func GetTOTPIssuer() string {
+	if issuer := strings.TrimSpace(os.Getenv("TOTP_ISSUER")); issuer != "" {
+		return issuer
+	}
+	return "SmartOffice"
+}
+
+// GenerateTOTPSecret gera um novo segredo em base32 (sem padding)
+func GenerateTOTPSecret() (string, error) {
+	buf := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf), nil
+}
+
+// TOTPURI monta a URI otpauth:// usada para gerar o QR code
+func TOTPURI(account, secret string) string {
+	issuer := GetTOTPIssuer()
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", totpDigits))
+	params.Set("period", fmt.Sprintf("%d", totpPeriod))
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// totpCode calcula o código de um passo de tempo (HOTP do RFC 4226)
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// ValidateTOTP confere o código informado e retorna o passo de tempo que casou.
+// Passos menores ou iguais a lastStep são recusados para impedir replay do mesmo código.
+func ValidateTOTP(secret, code string, lastStep int64) (bool, int64) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return false, 0
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return false, 0
+	}
+
+	current := time.Now().Unix() / totpPeriod
+	for delta := int64(-totpSkew); delta <= totpSkew; delta++ {
+		step := current + delta
+		if step <= lastStep {
+			continue
+		}
+		if hmac.Equal([]byte(totpCode(key, step)), []byte(code)) {
+			return true, step
+		}
+	}
+	return false, 0
+}
+
+// totpEncryptionKey deriva a chave AES-256 a partir de TOTP_ENCRYPTION_KEY
+func totpEncryptionKey() ([]byte, error) {
+	raw := strings.TrimSpace(os.Getenv("TOTP_ENCRYPTION_KEY"))
+	if raw == "" {
+		return nil, ErrTOTPKeyMissing
+	}
+	if len(raw) < minSigningKeyLength {
+		return nil, fmt.Errorf("TOTP_ENCRYPTION_KEY deve ter pelo menos %d caracteres", minSigningKeyLength)
+	}
+	sum := sha256.Sum256([]byte(raw))
+	return sum[:], nil
+}
+
+// EncryptTOTPSecret cifra o segredo para gravação no banco (nonce + ciphertext em base64)
+func EncryptTOTPSecret(secret string) (string, error) {
+	key, err := totpEncryptionKey()
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptTOTPSecret decifra o segredo gravado no banco
+func DecryptTOTPSecret(encrypted string) (string, error) {
+	key, err := totpEncryptionKey()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", ErrTOTPCipherInvalid
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", ErrTOTPCipherInvalid
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", ErrTOTPCipherInvalid
+	}
+	return string(plain), nil
+}
+
+// GenerateRecoveryCodes gera os códigos de recuperação (exibidos uma única vez) e seus hashes
+func GenerateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodes)
+	hashes := make([]string, 0, recoveryCodes)
+	for i := 0; i < recoveryCodes; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))
+		code := raw[:4] + "-" + raw[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode normaliza (sem hífen, minúsculo) e calcula o SHA-256 do código de recuperação
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
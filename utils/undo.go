@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"apiBackEnd/config"
+	"apiBackEnd/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Desfazer operações
+//
+// Cada operação faturável ou que altera o vencimento (renovação, tela adicional, alteração de
+// vencimento, bônus de confiança) grava um registro de desfazer em `undo:<id>`, indexado por
+// cliente em `undo_cliente:<user_id>` (sorted set por data). Os limites são os mesmos da reversão
+// de renovação:
+//
+//	ROLLBACK_PERMITIDO_DIAS        janela para desfazer (o registro expira junto; 0 = desativado)
+//	ROLLBACK_PERMITIDO_FREQUENCIA  dias entre duas reversões do mesmo cliente (`rollback_lock:<user_id>`)
+
+var ErrUndoNotFound = errors.New("operação não encontrada ou prazo para desfazer expirado")
+
+func undoKey(id string) string {
+	return "undo:" + id
+}
+
+func undoClientKey(userID int) string {
+	return "undo_cliente:" + strconv.Itoa(userID)
+}
+
+func rollbackLockKey(userID int) string {
+	return "rollback_lock:" + strconv.Itoa(userID)
+}
+
+// GetUndoWindow retorna a janela para desfazer operações (ROLLBACK_PERMITIDO_DIAS)
+func GetUndoWindow() time.Duration {
+	return time.Duration(GetRollbackPermitidoDias()) * 24 * time.Hour
+}
+
+// SaveUndoRecord grava o registro de desfazer e retorna o ID gerado ("" se a reversão estiver desativada)
+func SaveUndoRecord(ctx context.Context, rec *models.UndoRecord) (string, error) {
+	window := GetUndoWindow()
+	if window <= 0 {
+		return "", nil
+	}
+	id, err := randomID()
+	if err != nil {
+		return "", err
+	}
+	rec.ID = id
+	rec.CriadoEm = time.Now()
+	rec.ExpiraEm = rec.CriadoEm.Add(window)
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return "", err
+	}
+	indexKey := undoClientKey(rec.UserID)
+	pipe := config.RedisClient.TxPipeline()
+	pipe.Set(ctx, undoKey(id), data, window)
+	pipe.ZAdd(ctx, indexKey, redis.Z{Score: float64(rec.CriadoEm.Unix()), Member: id})
+	pipe.ZRemRangeByScore(ctx, indexKey, "-inf", strconv.FormatInt(rec.CriadoEm.Add(-window).Unix(), 10))
+	pipe.Expire(ctx, indexKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// GetUndoRecord lê o registro sem consumi-lo
+func GetUndoRecord(ctx context.Context, id string) (*models.UndoRecord, error) {
+	data, err := config.RedisClient.Get(ctx, undoKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrUndoNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rec models.UndoRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// ClaimUndoRecord lê e apaga o registro na mesma operação: duas reversões simultâneas não
+// aplicam a compensação duas vezes. Se a reversão falhar, devolva-o com RestoreUndoRecord.
+func ClaimUndoRecord(ctx context.Context, id string) (*models.UndoRecord, error) {
+	data, err := config.RedisClient.GetDel(ctx, undoKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrUndoNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rec models.UndoRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	if time.Now().After(rec.ExpiraEm) {
+		return nil, ErrUndoNotFound
+	}
+	return &rec, nil
+}
+
+// RestoreUndoRecord devolve um registro consumido por ClaimUndoRecord, com a validade que restava
+func RestoreUndoRecord(ctx context.Context, rec *models.UndoRecord) error {
+	ttl := time.Until(rec.ExpiraEm)
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return config.RedisClient.Set(ctx, undoKey(rec.ID), data, ttl).Err()
+}
+
+// DeleteUndoRecord remove o registro e a entrada do índice do cliente
+func DeleteUndoRecord(ctx context.Context, userID int, id string) error {
+	pipe := config.RedisClient.TxPipeline()
+	pipe.Del(ctx, undoKey(id))
+	pipe.ZRem(ctx, undoClientKey(userID), id)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ListUndoRecords lista as operações do cliente que ainda podem ser desfeitas, da mais recente para a mais antiga
+func ListUndoRecords(ctx context.Context, userID int) ([]models.UndoRecord, error) {
+	ids, err := config.RedisClient.ZRevRange(ctx, undoClientKey(userID), 0, -1).Result()
+	if err != nil || len(ids) == 0 {
+		return []models.UndoRecord{}, err
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = undoKey(id)
+	}
+	values, err := config.RedisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]models.UndoRecord, 0, len(values))
+	for i, v := range values {
+		raw, ok := v.(string)
+		if !ok {
+			// Expirado ou já desfeito: limpa o índice
+			config.RedisClient.ZRem(ctx, undoClientKey(userID), ids[i])
+			continue
+		}
+		var rec models.UndoRecord
+		if err := json.Unmarshal([]byte(raw), &rec); err == nil {
+			records = append(records, rec)
+		}
+	}
+	return records, nil
+}
+
+// RollbackLocked informa se o cliente teve uma reversão nos últimos ROLLBACK_PERMITIDO_FREQUENCIA dias
+func RollbackLocked(ctx context.Context, userID int) bool {
+	val, err := config.RedisClient.Get(ctx, rollbackLockKey(userID)).Result()
+	return err == nil && val != ""
+}
+
+// LockRollback bloqueia novas reversões do cliente por ROLLBACK_PERMITIDO_FREQUENCIA dias
+func LockRollback(ctx context.Context, userID int) error {
+	ttl := time.Duration(GetRollbackPermitidoFrequencia()) * 24 * time.Hour
+	if ttl <= 0 {
+		return nil
+	}
+	return config.RedisClient.Set(ctx, rollbackLockKey(userID), "1", ttl).Err()
+}
@@ -7,6 +7,7 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
@@ -15,6 +16,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -103,6 +105,12 @@ func GetAlteracaoVencimentoFrequenciaDias() int {
 	return val
 }
 
+// GetTelaReembolsoJanelaDias retorna por quantos dias o valor pago por uma tela adicional
+// limita a devolução ao remover a tela (TELA_REEMBOLSO_JANELA_DIAS, padrão 30)
+func GetTelaReembolsoJanelaDias() int {
+	return envPositiveInt("TELA_REEMBOLSO_JANELA_DIAS", 30)
+}
+
 func GetRollbackPermitidoFrequencia() int {
 	val, _ := strconv.Atoi(os.Getenv("ROLLBACK_PERMITIDO_FREQUENCIA"))
 	return val
@@ -204,18 +212,34 @@ func SaveToRedisJSON(ctx context.Context, key string, value interface{}, ttlSeco
 
 // TokenInfo contém dados extraídos do token JWT
 type TokenInfo struct {
-	MemberID int
-	Username string
+	MemberID       int
+	Username       string
+	SessionID      string
+	MemberGroupID  int
+	Role           Role
+	APIKeyID       int // Preenchido quando a requisição foi autenticada por chave de API
+	ImpersonatedBy int // member_id do super-admin, quando o token é de personificação
 }
 
-// ValidateAndExtractToken faz a validação do token e retorna informações essenciais
-func ValidateAndExtractToken(c *gin.Context) (*TokenInfo, bool) {
+// RequestClaims retorna as claims da identidade autenticada na requisição.
+// Nas rotas protegidas o AuthMiddleware já validou o JWT ou a chave de API e gravou as claims
+// no contexto; fora delas, valida o header Authorization.
+func RequestClaims(c *gin.Context) (jwt.MapClaims, int64, error) {
+	if value, exists := c.Get("claims"); exists {
+		if claims, ok := value.(jwt.MapClaims); ok {
+			return claims, c.GetInt64("time_remaining"), nil
+		}
+	}
 	tokenString := c.GetHeader("Authorization")
 	if tokenString == "" {
-		c.JSON(401, gin.H{"erro": "Token não fornecido"})
-		return nil, false
+		return nil, 0, errors.New("token não fornecido")
 	}
-	claims, _, err := ValidateToken(tokenString)
+	return ValidateToken(tokenString)
+}
+
+// ValidateAndExtractToken faz a validação do token e retorna informações essenciais
+func ValidateAndExtractToken(c *gin.Context) (*TokenInfo, bool) {
+	claims, _, err := RequestClaims(c)
 	if err != nil {
 		c.JSON(401, gin.H{"erro": "Token inválido ou expirado"})
 		return nil, false
@@ -230,8 +254,17 @@ func ValidateAndExtractToken(c *gin.Context) (*TokenInfo, bool) {
 		c.JSON(401, gin.H{"erro": "Username não encontrado no token"})
 		return nil, false
 	}
+	sessionID, _ := claims["sid"].(string)
+	memberGroupID, _ := claims["member_group_id"].(float64)
+	apiKeyID, _ := claims["api_key_id"].(float64)
+	impersonatedBy, _ := ImpersonatorFromClaims(claims)
 	return &TokenInfo{
-		MemberID: int(memberIDFloat),
-		Username: username,
+		MemberID:       int(memberIDFloat),
+		Username:       username,
+		SessionID:      sessionID,
+		MemberGroupID:  int(memberGroupID),
+		Role:           RoleFromClaims(claims),
+		APIKeyID:       int(apiKeyID),
+		ImpersonatedBy: impersonatedBy,
 	}, true
 }